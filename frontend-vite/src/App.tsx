@@ -237,6 +237,8 @@ export default function App() {
                                         <option value="grok">Grok</option>
                                         <option value="gemini">Gemini</option>
                                         <option disabled value="openai">OpenAI</option>
+                                        <option value="claude">Claude</option>
+                                        <option value="bedrock">Bedrock</option>
                                     </select>
                                 </div>
                             </div>
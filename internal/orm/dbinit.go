@@ -6,40 +6,67 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/chukiagosoftware/alpaca/models"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// Database drivers accepted by OpenDatabase
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
 // DB wraps the GORM database connection
 type DB struct {
 	*gorm.DB
 }
 
-// NewDatabase creates a new GORM SQLite database connection and runs migrations
+// NewDatabase opens the database selected by DB_DRIVER (sqlite by default, or postgres with DATABASE_URL) and runs migrations
 func NewDatabase() (*DB, error) {
-	dbPath := os.Getenv("SQLITE_DB_PATH")
-	if dbPath == "" {
-		_, filename, _, ok := runtime.Caller(0)
-		if !ok {
-			return nil, fmt.Errorf("failed to get current file path")
-		}
-		projectRoot := filepath.Dir(filepath.Dir(filepath.Dir(filename)))
-		dbPath = filepath.Join(projectRoot, "alpaca.db")
+	driver := os.Getenv("DB_DRIVER")
+	dsn := os.Getenv("SQLITE_DB_PATH")
+	if strings.EqualFold(driver, DriverPostgres) {
+		dsn = os.Getenv("DATABASE_URL")
 	}
+	return OpenDatabase(driver, dsn)
+}
 
-	dir := filepath.Dir(dbPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create database directory: %w", err)
+// OpenDatabase creates a new GORM connection for driver and runs migrations. An empty SQLite dsn uses alpaca.db in the project root
+func OpenDatabase(driver, dsn string) (*DB, error) {
+	var dialector gorm.Dialector
+	switch strings.ToLower(strings.TrimSpace(driver)) {
+	case "", DriverSQLite:
+		dbPath, err := sqlitePath(dsn)
+		if err != nil {
+			return nil, err
+		}
+		dialector = sqlite.Open(dbPath)
+		log.Printf("Connected to SQLite database: %s", dbPath)
+	case DriverPostgres:
+		if dsn == "" {
+			return nil, fmt.Errorf("DATABASE_URL is required for the postgres driver")
+		}
+		dialector = postgres.Open(dsn)
+	default:
+		return nil, fmt.Errorf("unknown database driver: %s", driver)
 	}
 
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	db, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	log.Printf("Connected to SQLite database: %s", dbPath)
+	if db.Dialector.Name() == DriverPostgres {
+		// review_embeddings.embedding is a pgvector column
+		if err := db.Exec("CREATE EXTENSION IF NOT EXISTS vector").Error; err != nil {
+			return nil, fmt.Errorf("failed to enable pgvector extension: %w", err)
+		}
+		log.Println("Connected to Postgres database")
+	}
 
 	// Run auto-migrations for kept tables
 	//db = db.Debug() //Gorm detailed logs
@@ -49,6 +76,11 @@ func NewDatabase() (*DB, error) {
 		&models.AirportCity{},
 		&models.AmadeusTestDetailedDataUnavailable{},
 		&models.City{},
+		&models.ReviewEmbedding{},
+		&models.SearchSession{},
+		&models.SessionTurn{},
+		&models.SearchRequestLog{},
+		&models.Judgment{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
@@ -57,6 +89,23 @@ func NewDatabase() (*DB, error) {
 	return &DB{db}, nil
 }
 
+func sqlitePath(dbPath string) (string, error) {
+	if dbPath == "" {
+		_, filename, _, ok := runtime.Caller(0)
+		if !ok {
+			return "", fmt.Errorf("failed to get current file path")
+		}
+		projectRoot := filepath.Dir(filepath.Dir(filepath.Dir(filename)))
+		dbPath = filepath.Join(projectRoot, "alpaca.db")
+	}
+
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create database directory: %w", err)
+	}
+	return dbPath, nil
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
 	sqlDB, err := db.DB.DB()
@@ -28,6 +28,17 @@ func (db *DB) GetHotel(ctx context.Context, hotelID string) (*models.Hotel, erro
 	return &hotel, err
 }
 
+// GetHotelsInBox returns hotels with coordinates inside a lat/lng box, skipping hotels without coordinates
+func (db *DB) GetHotelsInBox(ctx context.Context, minLat, maxLat, minLng, maxLng float64) ([]*models.Hotel, error) {
+	var hotels []*models.Hotel
+	err := db.DB.WithContext(ctx).
+		Select("hotel_id", "name", "city", "latitude", "longitude").
+		Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?", minLat, maxLat, minLng, maxLng).
+		Where("NOT (latitude = 0 AND longitude = 0)").
+		Find(&hotels).Error
+	return hotels, err
+}
+
 // CreateOrUpdateHotel creates or updates a hotel in the consolidated hotels table
 func (db *DB) CreateOrUpdateHotel(ctx context.Context, hotel *models.Hotel) error {
 	var existing models.Hotel
@@ -159,6 +170,55 @@ func (db *DB) GetReviewsForHotel(ctx context.Context, hotelID string) ([]*models
 	return reviews, err
 }
 
+// ReviewQuery selects a page of reviews for a hotel. SortBy is one of date, rating or helpful
+type ReviewQuery struct {
+	Source    string
+	SortBy    string
+	Ascending bool
+	Limit     int
+	Offset    int
+}
+
+// reviewSortColumns maps ReviewQuery.SortBy to columns shared by hotel_reviews and the BigQuery reviews table
+var reviewSortColumns = map[string]string{
+	"date":    "review_date",
+	"rating":  "rating",
+	"helpful": "helpful_count",
+}
+
+// OrderClause returns the ORDER BY expression for the query, newest first by default
+func (q ReviewQuery) OrderClause() string {
+	column, ok := reviewSortColumns[q.SortBy]
+	if !ok {
+		column = reviewSortColumns["date"]
+	}
+	direction := "DESC"
+	if q.Ascending {
+		direction = "ASC"
+	}
+	if column == "review_date" {
+		return fmt.Sprintf("review_date %s, created_at %s", direction, direction)
+	}
+	return fmt.Sprintf("%s %s, review_date DESC", column, direction)
+}
+
+// GetReviewsPage retrieves one page of reviews for a hotel along with the total number of matching reviews
+func (db *DB) GetReviewsPage(ctx context.Context, hotelID string, q ReviewQuery) ([]*models.HotelReview, int64, error) {
+	tx := db.DB.WithContext(ctx).Model(&models.HotelReview{}).Where("hotel_id = ?", hotelID)
+	if q.Source != "" {
+		tx = tx.Where("source = ?", q.Source)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reviews []*models.HotelReview
+	err := tx.Order(q.OrderClause()).Limit(q.Limit).Offset(q.Offset).Find(&reviews).Error
+	return reviews, total, err
+}
+
 // GetReviewTexts returns just the review texts for LLM processing
 func (db *DB) GetReviewTexts(ctx context.Context, hotelID string) ([]string, error) {
 	reviews, err := db.GetReviewsForHotel(ctx, hotelID)
@@ -175,3 +235,47 @@ func (db *DB) GetReviewTexts(ctx context.Context, hotelID string) ([]string, err
 
 	return texts, nil
 }
+
+// ForEachReviewEmbeddingBatch streams the review_embeddings table in batches to avoid loading every vector at once
+func (db *DB) ForEachReviewEmbeddingBatch(ctx context.Context, batchSize int, fn func([]*models.ReviewEmbedding) error) error {
+	var batch []*models.ReviewEmbedding
+	return db.DB.WithContext(ctx).Order("id").FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+// ReviewSample is a review with its hotel location and, when the review was embedded, the review_embeddings ID
+// that retrieval returns for it
+type ReviewSample struct {
+	ReviewID    int32
+	EmbeddingID *int64
+	HotelName   string
+	City        string
+	Country     string
+	Continent   string
+	Rating      float64
+	ReviewText  string
+}
+
+// SampleReviewsPerCity returns up to perCity random reviews of at least minLength characters for every hotel city.
+// Embeddings are matched on the review text, since review_embeddings is loaded from BigQuery with its own IDs.
+func (db *DB) SampleReviewsPerCity(ctx context.Context, perCity, minLength int) ([]ReviewSample, error) {
+	var samples []ReviewSample
+	err := db.DB.WithContext(ctx).Raw(`
+		WITH ranked AS (
+			SELECT r.id, r.rating, r.review_text, h.name AS hotel_name, h.city, h.country,
+				ROW_NUMBER() OVER (PARTITION BY h.city, h.country ORDER BY RANDOM()) AS rn
+			FROM hotel_reviews r
+			JOIN hotels h ON h.hotel_id = r.hotel_id
+			WHERE h.city <> '' AND LENGTH(r.review_text) >= ?
+		)
+		SELECT ranked.id AS review_id, e.id AS embedding_id, ranked.hotel_name, ranked.city, ranked.country,
+			COALESCE(e.continent, '') AS continent, ranked.rating, ranked.review_text
+		FROM ranked
+		LEFT JOIN review_embeddings e ON e.id = (
+			SELECT MIN(id) FROM review_embeddings WHERE review_text = ranked.review_text
+		)
+		WHERE ranked.rn <= ?
+		ORDER BY ranked.country, ranked.city, ranked.rn`, minLength, perCity).Scan(&samples).Error
+	return samples, err
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ReviewEmbedding represents the review_embeddings table, a local mirror of the BigQuery bigReview_embeddings table
+// used by offline and SQL vector search backends
+type ReviewEmbedding struct {
+	ID            int64     `gorm:"primaryKey" json:"id" bigquery:"id"`
+	HotelName     string    `gorm:"index" json:"hotel_name" bigquery:"hotel_name"`
+	City          string    `gorm:"index" json:"city" bigquery:"city"`
+	Country       string    `json:"country" bigquery:"country"`
+	Continent     string    `json:"continent" bigquery:"continent"`
+	Rating        int64     `json:"rating" bigquery:"rating"`
+	ReviewText    string    `json:"review_text" bigquery:"review_text"`
+	ReviewerName  string    `json:"reviewer_name" bigquery:"reviewer_name"`
+	GoogleMapsURI string    `gorm:"column:google_maps_uri" json:"google_maps_uri" bigquery:"google_maps_uri"`
+	PhotoName     string    `gorm:"column:photo_name" json:"photo_name" bigquery:"photo_name"`
+	Embedding     Vector    `gorm:"not null" json:"embedding" bigquery:"-"`
+	CreatedAt     time.Time `json:"createdAt" bigquery:"created_at"`
+}
+
+// Vector is an embedding stored as a pgvector column on Postgres and as JSON array text on SQLite.
+// Both use the same [0.12,-0.03,...] text representation.
+type Vector []float32
+
+// GormDataType implements schema.GormDataTypeInterface
+func (Vector) GormDataType() string {
+	return "vector"
+}
+
+// GormDBDataType picks the column type per dialect during migrations
+func (Vector) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "vector"
+	}
+	return "text"
+}
+
+// Value implements driver.Valuer
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal([]float32(v))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (v *Vector) Scan(src interface{}) error {
+	var b []byte
+	switch s := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case string:
+		b = []byte(s)
+	case []byte:
+		b = s
+	default:
+		return fmt.Errorf("cannot scan %T into Vector", src)
+	}
+	var values []float32
+	if err := json.Unmarshal(b, &values); err != nil {
+		return fmt.Errorf("invalid vector: %w", err)
+	}
+	*v = values
+	return nil
+}
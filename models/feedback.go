@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Thumbs labels accepted for a Judgment
+const (
+	JudgmentUp   = "up"
+	JudgmentDown = "down"
+)
+
+// SearchRequestLog records the context of one search so judgments can be joined back to what was asked and
+// retrieved. Filters, RetrievedIDs and HotelIDs are JSON encoded. RequestID is at most vertex.MaxRequestIDLength
+// characters, as enforced by the request ID middleware.
+type SearchRequestLog struct {
+	RequestID    string `gorm:"primaryKey;size:64" json:"request_id"`
+	SessionID    string `gorm:"index;size:64" json:"session_id"`
+	Question     string `json:"question"`
+	Mode         string `json:"mode"`
+	Filters      string `json:"filters"`
+	RetrievedIDs string `gorm:"column:retrieved_ids" json:"retrieved_ids"`
+	HotelIDs     string `gorm:"column:hotel_ids" json:"hotel_ids"`
+	Model        string `json:"model"`
+	Reranker     string `json:"reranker"`
+	// PromptVersion and SafetyPromptVersion are prompt template IDs such as "completion/v2"
+	PromptVersion       string    `gorm:"index" json:"prompt_version"`
+	SafetyPromptVersion string    `json:"safety_prompt_version"`
+	Partial             bool      `json:"partial"`
+	CreatedAt           time.Time `gorm:"index" json:"created_at"`
+}
+
+// Judgment is user feedback on a recommended hotel or review of a search. Label is a thumbs up/down and Grade a
+// graded relevance label from 0 (irrelevant) to 3 (perfect), at least one of them is set.
+type Judgment struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	RequestID string    `gorm:"index;size:64;not null" json:"request_id"`
+	HotelID   string    `gorm:"index" json:"hotel_id,omitempty"`
+	ReviewID  string    `json:"review_id,omitempty"`
+	Label     string    `gorm:"size:8" json:"label,omitempty"`
+	Grade     *int      `json:"grade,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -42,4 +42,5 @@ type Hotel struct {
 	NumberOfRatings   int     `gorm:"column:number_of_ratings;default:0" bigquery:"number_of_ratings"`
 	OverallRating     float64 `gorm:"column:overall_rating" bigquery:"overall_rating"`
 	Sentiments        string  `gorm:"column:sentiments"`
+	PhotoName         string  `gorm:"column:photo_name" bigquery:"photo_name"`
 }
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// SearchSession is a conversational search session, expired sessions are deleted with their turns
+type SearchSession struct {
+	ID        string    `gorm:"primaryKey;size:64" json:"id"`
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SessionTurn is one question and answer of a SearchSession. ReviewIDs holds the retrieved review embedding IDs
+// as a JSON array so follow-up questions can refine the same results.
+type SessionTurn struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	SessionID string    `gorm:"index;size:64;not null" json:"session_id"`
+	Question  string    `json:"question"`
+	Answer    string    `json:"answer"`
+	ReviewIDs string    `gorm:"column:review_ids" json:"review_ids"`
+	CreatedAt time.Time `json:"created_at"`
+}
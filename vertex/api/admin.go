@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HotelModerator updates the admin-maintained fields of a hotel, implemented by stores.BQ and by orm.DB. Search excludes
+// hotels with the admin flag set and shows the important note with their recommendations.
+type HotelModerator interface {
+	UpdateAdminFlag(ctx context.Context, hotelID string, disabled bool) error
+	UpdateRecommendationFields(ctx context.Context, hotelID string, recommended, quality, quiet bool, importantNote string) error
+}
+
+// HotelModerationForm is the body of PATCH /api/admin/hotels/:id, fields left out keep their current value
+type HotelModerationForm struct {
+	AdminFlag     *bool   `json:"admin_flag"`
+	Recommended   *bool   `json:"recommended"`
+	Quality       *bool   `json:"quality"`
+	Quiet         *bool   `json:"quiet"`
+	ImportantNote *string `json:"important_note"`
+}
+
+// AdminAuthMiddleware requires admin_token as a bearer token
+func AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			recordErrorMetric(c.Request.Context(), "admin_unauthorized")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// HotelModerationHandler sets or clears the admin flag, recommended/quality/quiet and the important note of a
+// hotel, returning the updated hotel
+func HotelModerationHandler(c *gin.Context, hotels HotelStore, moderator HotelModerator) {
+	hotelID := strings.TrimSpace(c.Param("id"))
+
+	var form HotelModerationForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moderation data"})
+		return
+	}
+	if form.AdminFlag == nil && form.Recommended == nil && form.Quality == nil && form.Quiet == nil && form.ImportantNote == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "admin_flag, recommended, quality, quiet or important_note is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	hotel, err := hotels.GetHotel(ctx, hotelID)
+	if isHotelNotFound(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hotel not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("error: Failed to get hotel %s for moderation: %v", hotelID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get hotel"})
+		return
+	}
+
+	if form.Recommended != nil || form.Quality != nil || form.Quiet != nil || form.ImportantNote != nil {
+		recommended, quality, quiet, note := hotel.Recommended, hotel.Quality, hotel.Quiet, hotel.ImportantNote
+		if form.Recommended != nil {
+			recommended = *form.Recommended
+		}
+		if form.Quality != nil {
+			quality = *form.Quality
+		}
+		if form.Quiet != nil {
+			quiet = *form.Quiet
+		}
+		if form.ImportantNote != nil {
+			note = strings.TrimSpace(*form.ImportantNote)
+		}
+		if err := moderator.UpdateRecommendationFields(ctx, hotelID, recommended, quality, quiet, note); err != nil {
+			log.Printf("error: Failed to update recommendation fields of hotel %s: %v", hotelID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update hotel"})
+			return
+		}
+	}
+	if form.AdminFlag != nil {
+		if err := moderator.UpdateAdminFlag(ctx, hotelID, *form.AdminFlag); err != nil {
+			log.Printf("error: Failed to update admin flag of hotel %s: %v", hotelID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update hotel"})
+			return
+		}
+	}
+	log.Printf("Moderated hotel %s from %s", hotelID, c.ClientIP())
+
+	hotel, err = hotels.GetHotel(ctx, hotelID)
+	if err != nil {
+		log.Printf("error: Failed to reload hotel %s: %v", hotelID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get hotel"})
+		return
+	}
+	c.JSON(http.StatusOK, hotel)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/chukiagosoftware/alpaca/internal/orm"
+	"github.com/chukiagosoftware/alpaca/models"
+)
+
+const testAdminToken = "secret"
+
+// adminRouter serves the admin API over a SQLite database holding one recommended, quiet hotel h1
+func adminRouter(t *testing.T) (*gin.Engine, *orm.DB) {
+	t.Helper()
+	db, err := orm.OpenDatabase(orm.DriverSQLite, filepath.Join(t.TempDir(), "alpaca.db"))
+	if err != nil {
+		t.Fatalf("OpenDatabase: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	hotel := &models.Hotel{HotelID: "h1", Source: "test", Name: "Grand Hotel", Recommended: true, Quiet: true, ImportantNote: "Closed in August"}
+	if err := db.DB.Create(hotel).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	admin := r.Group("/api/admin", AdminAuthMiddleware(testAdminToken))
+	admin.PATCH("/hotels/:id", func(c *gin.Context) {
+		HotelModerationHandler(c, db, db)
+	})
+	return r, db
+}
+
+func patchHotel(r *gin.Engine, hotelID, authorization, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/api/admin/hotels/"+hotelID, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	r, db := adminRouter(t)
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "missing token", want: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer guess", want: http.StatusUnauthorized},
+		{name: "token without bearer scheme", authorization: testAdminToken, want: http.StatusUnauthorized},
+		{name: "admin token", authorization: "Bearer " + testAdminToken, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := patchHotel(r, "h1", tt.authorization, `{"quality":true}`); w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+	// Only the authorized request reached the handler
+	hotel, err := db.GetHotel(context.Background(), "h1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hotel.Quality {
+		t.Error("quality not set by the authorized request")
+	}
+}
+
+func TestHotelModerationHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		hotelID string
+		body    string
+		want    int
+		// check is applied to the stored hotel after the request
+		check func(t *testing.T, hotel *models.Hotel)
+	}{
+		{
+			name:    "partial update keeps the other fields",
+			hotelID: "h1",
+			body:    `{"quiet":false}`,
+			want:    http.StatusOK,
+			check: func(t *testing.T, hotel *models.Hotel) {
+				if hotel.Quiet || !hotel.Recommended || hotel.Quality || hotel.AdminFlag || hotel.ImportantNote != "Closed in August" {
+					t.Errorf("hotel = %+v, want only quiet cleared", hotel)
+				}
+			},
+		},
+		{
+			name:    "admin flag only",
+			hotelID: "h1",
+			body:    `{"admin_flag":true}`,
+			want:    http.StatusOK,
+			check: func(t *testing.T, hotel *models.Hotel) {
+				if !hotel.AdminFlag || !hotel.Recommended || !hotel.Quiet || hotel.ImportantNote != "Closed in August" {
+					t.Errorf("hotel = %+v, want only the admin flag set", hotel)
+				}
+			},
+		},
+		{
+			name:    "important note is trimmed",
+			hotelID: "h1",
+			body:    `{"important_note":"  Renovated lobby  "}`,
+			want:    http.StatusOK,
+			check: func(t *testing.T, hotel *models.Hotel) {
+				if hotel.ImportantNote != "Renovated lobby" || !hotel.Recommended || !hotel.Quiet {
+					t.Errorf("hotel = %+v, want only the note changed", hotel)
+				}
+			},
+		},
+		{name: "unknown hotel", hotelID: "missing", body: `{"quiet":false}`, want: http.StatusNotFound},
+		{name: "no fields", hotelID: "h1", body: `{}`, want: http.StatusBadRequest},
+		{name: "invalid body", hotelID: "h1", body: `{"quiet":"no"}`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, db := adminRouter(t)
+			w := patchHotel(r, tt.hotelID, "Bearer "+testAdminToken, tt.body)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.check == nil {
+				return
+			}
+			var returned models.Hotel
+			if err := json.Unmarshal(w.Body.Bytes(), &returned); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			tt.check(t, &returned)
+			stored, err := db.GetHotel(context.Background(), tt.hotelID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, stored)
+		})
+	}
+}
@@ -25,7 +25,8 @@ func CORSMiddleware(appConfig vertex.Config) gin.HandlerFunc {
 	}
 
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", requestIDHeader}
+	config.ExposeHeaders = []string{requestIDHeader}
 	config.MaxAge = 12 * time.Hour
 
 	return cors.New(config)
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+	"github.com/gin-gonic/gin"
+)
+
+const searchLogTimeout = 5 * time.Second
+
+// FeedbackHandler stores a thumbs up/down or graded judgment for a hotel or review returned by a search,
+// identified by the request_id of that search
+func FeedbackHandler(c *gin.Context, store *vertex.FeedbackStore) {
+	if store == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Feedback not configured"})
+		return
+	}
+
+	var form vertex.FeedbackForm
+	if err := c.ShouldBind(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feedback data"})
+		return
+	}
+	judgment, err := form.Judgment()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = store.SaveJudgment(c.Request.Context(), judgment)
+	if errors.Is(err, vertex.ErrUnknownRequestID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "request_id": judgment.RequestID})
+		return
+	}
+	if err != nil {
+		log.Printf("error: Failed to save feedback for %s: %v", judgment.RequestID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save feedback"})
+		return
+	}
+	c.JSON(http.StatusCreated, judgment)
+}
+
+// searchLogs tracks the background writes of logSearchRequest, main waits on it before closing the database
+var searchLogs sync.WaitGroup
+
+// logSearchRequest records the search context in the background, so a slow feedback database never delays a search
+func logSearchRequest(ctx context.Context, store *vertex.FeedbackStore, entry vertex.SearchLog) {
+	if store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), searchLogTimeout)
+	searchLogs.Add(1)
+	go func() {
+		defer searchLogs.Done()
+		defer cancel()
+		if err := store.LogSearch(ctx, entry); err != nil {
+			log.Printf("[%s] %v", entry.RequestID, err)
+		}
+	}()
+}
+
+// retrievedIDs returns the review IDs of the retrieved metadata rows
+func retrievedIDs(retrieved []map[string]any) []string {
+	ids := make([]string, 0, len(retrieved))
+	for _, r := range retrieved {
+		if id, ok := r["id"]; ok && id != nil {
+			ids = append(ids, fmt.Sprintf("%v", id))
+		}
+	}
+	return ids
+}
+
+// recommendedHotelIDs returns the hotel_id of each completion item, as attached by attachHotelFields
+func recommendedHotelIDs(items []map[string]any) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if id, ok := item["hotel_id"]; ok && id != nil {
+			ids = append(ids, fmt.Sprintf("%v", id))
+		}
+	}
+	return ids
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/chukiagosoftware/alpaca/models"
+	"github.com/chukiagosoftware/alpaca/vertex"
+	"github.com/chukiagosoftware/alpaca/vertex/api/searchpb"
+	"github.com/chukiagosoftware/alpaca/vertex/stores"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// searchServer serves searchpb.SearchService over the same pipelines as the HTTP handlers
+type searchServer struct {
+	searchpb.UnimplementedSearchServiceServer
+
+	config   *vertex.Config
+	vsSvc    *vertex.VertexSearchService
+	backends *SearchBackends
+	hotels   HotelStore
+	bq       *stores.BQ
+}
+
+// NewGRPCServer registers the SearchService. Like otelgin on the HTTP side every call gets a server span, otelgrpc
+// only provides this as a stats handler now, and the request ID interceptors mirror RequestIDMiddleware.
+func NewGRPCServer(config *vertex.Config, vsSvc *vertex.VertexSearchService, backends *SearchBackends, hotels HotelStore, bq *stores.BQ) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(RequestIDUnaryInterceptor()),
+		grpc.ChainStreamInterceptor(RequestIDStreamInterceptor()),
+	)
+	searchpb.RegisterSearchServiceServer(server, &searchServer{
+		config:   config,
+		vsSvc:    vsSvc,
+		backends: backends,
+		hotels:   hotels,
+		bq:       bq,
+	})
+	return server
+}
+
+// Search runs the SearchHandler pipeline. Failures without usable results are returned as a status carrying the
+// SearchError as detail, partial results and unsafe queries are regular responses like over HTTP.
+func (s *searchServer) Search(ctx context.Context, req *searchpb.SearchRequest) (*searchpb.SearchResponse, error) {
+	ctx, span := otel.Tracer("vertex-search").Start(ctx, "search-request")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, searchTimeout(s.config))
+	defer cancel()
+
+	stageErrs := &searchErrors{requestID: requestIDFromContext(ctx)}
+	input, sessionID, session, searchErr := prepareSearch(ctx, s.config, s.vsSvc, s.backends, stageErrs, searchFormFromRequest(req))
+	if searchErr != nil {
+		return nil, searchStatus(searchErr)
+	}
+
+	res := runSearch(ctx, s.config, s.vsSvc, s.backends, stageErrs, input, sessionID, session)
+	if res.Err != nil {
+		return nil, searchStatus(res.Err)
+	}
+
+	resp := &searchpb.SearchResponse{
+		Completion:          make([]*searchpb.Recommendation, len(res.Items)),
+		Message:             res.Message,
+		Model:               res.Completion.Model,
+		Usage:               tokenUsageProto(res.Completion.Usage),
+		VectorCount:         int32(res.VectorCount),
+		SafeQuery:           res.Safe,
+		Mode:                res.Input.Mode,
+		Filters:             queryFiltersProto(res.Filters),
+		Reranker:            res.Reranker,
+		RequestId:           res.RequestID,
+		PromptVersion:       vertex.PromptID(vertex.PromptKindCompletion, res.Input.PromptVersion),
+		SafetyPromptVersion: vertex.PromptID(vertex.PromptKindSafety, res.Input.SafetyPromptVersion),
+		SessionId:           res.SessionID,
+		Errors:              searchErrorsProto(res.Errors),
+		Partial:             res.Partial,
+		Timings:             timingsProto(res.Timings),
+	}
+	for i, item := range res.Items {
+		resp.Completion[i] = recommendationProto(item)
+	}
+	if res.Partial && len(res.Items) == 0 {
+		retrieved, err := structsProto(res.Retrieved)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode retrieved reviews: %v", err)
+		}
+		resp.Retrieved = retrieved
+	}
+	return resp, nil
+}
+
+// StreamSearch runs the SearchStreamHandler pipeline, sending its events as they happen
+func (s *searchServer) StreamSearch(req *searchpb.SearchRequest, stream grpc.ServerStreamingServer[searchpb.SearchEvent]) error {
+	ctx, span := otel.Tracer("vertex-search").Start(stream.Context(), "search-stream-request")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, searchTimeout(s.config))
+	defer cancel()
+
+	stageErrs := &searchErrors{requestID: requestIDFromContext(ctx)}
+	input, sessionID, session, searchErr := prepareSearch(ctx, s.config, s.vsSvc, s.backends, stageErrs, searchFormFromRequest(req))
+	if searchErr != nil {
+		return searchStatus(searchErr)
+	}
+
+	// A failed send means the client is gone, the canceled context then stops the pipeline
+	var sendErr error
+	runSearchStream(ctx, s.config, s.vsSvc, s.backends, stageErrs, input, sessionID, session, func(event string, data any) {
+		if sendErr != nil {
+			return
+		}
+		msg, err := searchEventProto(event, data)
+		if err != nil {
+			log.Printf("[%s] Failed to encode %s event: %v", stageErrs.requestID, event, err)
+			return
+		}
+		if sendErr = stream.Send(msg); sendErr != nil {
+			cancel()
+		}
+	})
+	return sendErr
+}
+
+func (s *searchServer) GetLocations(ctx context.Context, _ *searchpb.GetLocationsRequest) (*searchpb.GetLocationsResponse, error) {
+	if s.bq == nil {
+		return nil, status.Error(codes.Unavailable, "locations not configured")
+	}
+	groups, err := s.bq.GetDistinctLocations(ctx)
+	if err != nil {
+		log.Printf("error: Failed to get locations: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to get locations: %v", err)
+	}
+
+	resp := &searchpb.GetLocationsResponse{Locations: make([]*searchpb.LocationGroup, len(groups))}
+	for i, g := range groups {
+		resp.Locations[i] = &searchpb.LocationGroup{Continent: g.Continent, CityCountries: g.CityCountries}
+	}
+	return resp, nil
+}
+
+func (s *searchServer) GetHotel(ctx context.Context, req *searchpb.GetHotelRequest) (*searchpb.GetHotelResponse, error) {
+	query, err := reviewQuery(int(req.GetPage()), int(req.GetPageSize()), req.GetSort(), req.GetAscending(), req.GetSource())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	hotel, reviews, total, err := hotelDetail(ctx, s.hotels, req.GetHotelId(), query)
+	if errors.Is(err, stores.ErrHotelNotFound) {
+		return nil, status.Error(codes.NotFound, "hotel not found")
+	}
+	if err != nil {
+		log.Printf("Hotel detail error: %v", err)
+		return nil, status.Error(codes.Internal, "failed to get hotel")
+	}
+
+	resp := &searchpb.GetHotelResponse{
+		Hotel:   hotelProto(hotel, s.backends.Photos),
+		Reviews: make([]*searchpb.Review, len(reviews)),
+		Pagination: &searchpb.Pagination{
+			Page:     int32(query.Offset/query.Limit + 1),
+			PageSize: int32(query.Limit),
+			Total:    total,
+		},
+	}
+	for i, r := range reviews {
+		resp.Reviews[i] = reviewProto(r)
+	}
+	return resp, nil
+}
+
+// searchFormFromRequest maps the request onto the form fields SearchHandler binds, so both share buildSearchInput
+func searchFormFromRequest(req *searchpb.SearchRequest) vertex.SearchForm {
+	form := vertex.SearchForm{
+		Question:      req.GetQuestion(),
+		Continent:     req.GetContinent(),
+		CityCountry:   req.GetCityCountry(),
+		LLMChoice:     req.GetLlm(),
+		Mode:          req.GetMode(),
+		Near:          req.GetNear(),
+		SessionID:     req.GetSessionId(),
+		PromptVersion: req.GetPromptVersion(),
+	}
+	if req.GetMinRating() > 0 {
+		form.Rating = strconv.Itoa(int(req.GetMinRating()))
+	}
+	if req.GetRadiusKm() != 0 {
+		form.RadiusKm = strconv.FormatFloat(req.GetRadiusKm(), 'f', -1, 64)
+	}
+	return form
+}
+
+// searchStatus maps a stage failure to the gRPC code matching httpStatusForSearchError
+func searchStatus(e *vertex.SearchError) error {
+	code := codes.Internal
+	switch {
+	case e.Code == vertex.ErrCodeInvalidRequest:
+		code = codes.InvalidArgument
+	case e.Code == vertex.ErrCodeSessionNotFound:
+		code = codes.NotFound
+	case e.Code == vertex.ErrCodeTimeout:
+		code = codes.DeadlineExceeded
+	case e.Code == vertex.ErrCodeCanceled:
+		code = codes.Canceled
+	case e.Retryable:
+		code = codes.Unavailable
+	}
+
+	st := status.New(code, e.Message)
+	if detailed, err := st.WithDetails(searchErrorProto(e)); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// searchEventProto converts an event of runSearchStream
+func searchEventProto(event string, data any) (*searchpb.SearchEvent, error) {
+	switch event {
+	case "filters":
+		return &searchpb.SearchEvent{Event: &searchpb.SearchEvent_Filters{Filters: queryFiltersProto(data.(vertex.QueryFilters))}}, nil
+	case "reviews":
+		reviews, err := structsProto(data.([]map[string]any))
+		if err != nil {
+			return nil, err
+		}
+		return &searchpb.SearchEvent{Event: &searchpb.SearchEvent_Reviews{Reviews: &searchpb.RetrievedReviews{Reviews: reviews}}}, nil
+	case "reviews_order":
+		return &searchpb.SearchEvent{Event: &searchpb.SearchEvent_ReviewsOrder{ReviewsOrder: &searchpb.ReviewOrder{Ids: data.(reviewOrder).IDs}}}, nil
+	case "item":
+		return &searchpb.SearchEvent{Event: &searchpb.SearchEvent_Item{Item: recommendationProto(data.(map[string]any))}}, nil
+	case "message":
+		message, _ := data.(gin.H)["message"].(string)
+		return &searchpb.SearchEvent{Event: &searchpb.SearchEvent_Message{Message: message}}, nil
+	case "error":
+		return &searchpb.SearchEvent{Event: &searchpb.SearchEvent_Error{Error: searchErrorProto(data.(*vertex.SearchError))}}, nil
+	case "done":
+		summary := data.(searchSummary)
+		return &searchpb.SearchEvent{Event: &searchpb.SearchEvent_Done{Done: &searchpb.SearchDone{
+			Model:               summary.Model,
+			Usage:               tokenUsageProto(summary.Usage),
+			VectorCount:         int32(summary.VectorCount),
+			ItemCount:           int32(summary.ItemCount),
+			SafeQuery:           summary.SafeQuery,
+			Mode:                summary.Mode,
+			Filters:             queryFiltersProto(summary.Filters),
+			Reranker:            summary.Reranker,
+			RequestId:           summary.RequestID,
+			PromptVersion:       summary.PromptVersion,
+			SafetyPromptVersion: summary.SafetyPromptVersion,
+			SessionId:           summary.SessionID,
+			Errors:              searchErrorsProto(summary.Errors),
+			Timings:             timingsProto(summary.Timings),
+		}}}, nil
+	}
+	return nil, fmt.Errorf("unknown event %q", event)
+}
+
+func tokenUsageProto(u vertex.TokenUsage) *searchpb.TokenUsage {
+	return &searchpb.TokenUsage{
+		PromptTokens:     int32(u.PromptTokens),
+		CompletionTokens: int32(u.CompletionTokens),
+		TotalTokens:      int32(u.TotalTokens),
+	}
+}
+
+func queryFiltersProto(f vertex.QueryFilters) *searchpb.QueryFilters {
+	return &searchpb.QueryFilters{
+		City:       f.City,
+		Country:    f.Country,
+		Continent:  f.Continent,
+		MinRating:  int32(f.MinRating),
+		TravelType: f.TravelType,
+		Aspects:    f.Aspects,
+	}
+}
+
+func searchErrorProto(e *vertex.SearchError) *searchpb.SearchError {
+	return &searchpb.SearchError{
+		Code:      e.Code,
+		Stage:     e.Stage,
+		Message:   e.Message,
+		Retryable: e.Retryable,
+		RequestId: e.RequestID,
+	}
+}
+
+func searchErrorsProto(errs []*vertex.SearchError) []*searchpb.SearchError {
+	out := make([]*searchpb.SearchError, len(errs))
+	for i, e := range errs {
+		out[i] = searchErrorProto(e)
+	}
+	return out
+}
+
+func timingsProto(t searchTimings) *searchpb.Timings {
+	return &searchpb.Timings{
+		QueryUnderstandingMs: t.QueryUnderstandingMs,
+		GeoPrefilterMs:       t.GeoPrefilterMs,
+		EmbeddingMs:          t.EmbeddingMs,
+		VectorSearchMs:       t.VectorSearchMs,
+		KeywordSearchMs:      t.KeywordSearchMs,
+		SafetyMs:             t.SafetyMs,
+		MetadataMs:           t.MetadataMs,
+		RerankMs:             t.RerankMs,
+		LlmCompletionMs:      t.LLMCompletionMs,
+	}
+}
+
+// recommendationProto reads a completion item after attachHotelFields and enrichReviewWithGoogleMedia
+func recommendationProto(item map[string]any) *searchpb.Recommendation {
+	return &searchpb.Recommendation{
+		Hotel:         itemString(item, "Hotel"),
+		City:          itemString(item, "City"),
+		Review:        itemString(item, "Review"),
+		Rating:        itemFloat(item, "Rating"),
+		Distance:      itemFloat(item, "Distance"),
+		Address:       itemString(item, "Address"),
+		GoogleMapsUri: itemString(item, "google_maps_uri"),
+		PhotoName:     itemString(item, "photo_name"),
+		HotelId:       itemString(item, "hotel_id"),
+		DistanceKm:    itemFloat(item, "distance_km"),
+		MapUrl:        itemString(item, "map_url"),
+		PhotoThumb:    itemString(item, "photo_thumb"),
+		PhotoFull:     itemString(item, "photo_full"),
+		ImportantNote: itemString(item, "important_note"),
+	}
+}
+
+func itemString(item map[string]any, key string) string {
+	if v, ok := item[key]; ok && v != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
+
+func itemFloat(item map[string]any, key string) float64 {
+	f, _ := strconv.ParseFloat(itemString(item, key), 64)
+	return f
+}
+
+// structsProto converts retrieved metadata rows through JSON, the rows hold backend specific column types
+func structsProto(rows []map[string]any) ([]*structpb.Struct, error) {
+	out := make([]*structpb.Struct, len(rows))
+	for i, row := range rows {
+		b, err := json.Marshal(row)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = &structpb.Struct{}
+		if err := out[i].UnmarshalJSON(b); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func hotelProto(hotel *models.Hotel, photos *PhotoProxy) *searchpb.Hotel {
+	h := &searchpb.Hotel{
+		HotelId:           hotel.HotelID,
+		Source:            hotel.Source,
+		Name:              hotel.Name,
+		City:              hotel.City,
+		Country:           hotel.Country,
+		Latitude:          hotel.Latitude,
+		Longitude:         hotel.Longitude,
+		StreetAddress:     hotel.StreetAddress,
+		PostalCode:        hotel.PostalCode,
+		Phone:             hotel.Phone,
+		Website:           hotel.Website,
+		AmadeusRating:     hotel.AmadeusRating,
+		GoogleRating:      hotel.GoogleRating,
+		TripadvisorRating: hotel.TripAdvisorRating,
+		BookingRating:     hotel.BookingRating,
+		OverallRating:     hotel.OverallRating,
+		NumberOfReviews:   int32(hotel.NumberOfReviews),
+		Recommended:       hotel.Recommended,
+		Quality:           hotel.Quality,
+		Quiet:             hotel.Quiet,
+		ImportantNote:     hotel.ImportantNote,
+		PhotoName:         hotel.PhotoName,
+	}
+	if photos != nil && hotel.PhotoName != "" {
+		h.PhotoThumb = photos.SignedURL(hotel.PhotoName, 120)
+		h.PhotoFull = photos.SignedURL(hotel.PhotoName, 800)
+	}
+
+	// Sentiments holds the raw Amadeus sentiments JSON
+	if json.Valid([]byte(hotel.Sentiments)) {
+		sentiments := &structpb.Struct{}
+		if err := sentiments.UnmarshalJSON([]byte(hotel.Sentiments)); err == nil {
+			h.AmadeusSentiments = sentiments
+		}
+	}
+	return h
+}
+
+func reviewProto(r *models.HotelReview) *searchpb.Review {
+	review := &searchpb.Review{
+		Id:               r.ID,
+		Source:           r.Source,
+		ReviewerName:     r.ReviewerName,
+		ReviewerLocation: r.ReviewerLocation,
+		Rating:           r.Rating,
+		ReviewText:       r.ReviewText,
+		Verified:         r.Verified,
+		HelpfulCount:     int32(r.HelpfulCount),
+		RoomType:         r.RoomType,
+		TravelType:       r.TravelType,
+		GoogleMapsUri:    r.GoogleMapsURI,
+	}
+	if !r.ReviewDate.IsZero() {
+		review.ReviewDate = timestamppb.New(r.ReviewDate)
+	}
+	return review
+}
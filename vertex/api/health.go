@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+	"github.com/chukiagosoftware/alpaca/vertex/stores"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultHealthTimeout  = 5 * time.Second
+	defaultHealthCacheTTL = 10 * time.Second
+)
+
+// Component statuses of a health report
+const (
+	healthOK    = "ok"
+	healthError = "error"
+)
+
+// healthCheck is one dependency. Required dependencies must pass for /readyz, LLM providers are a fallback chain so
+// one reachable provider is enough.
+type healthCheck struct {
+	name     string
+	required bool
+	llm      bool
+	check    func(ctx context.Context) error
+}
+
+type componentHealth struct {
+	Status    string `json:"status"`
+	Required  bool   `json:"required"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// healthReport is the body of /readyz. Status is "ok" when every component passed, "degraded" when only optional
+// ones failed and "unavailable" when the service cannot answer searches.
+type healthReport struct {
+	Status     string                     `json:"status"`
+	Ready      bool                       `json:"ready"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+	Components map[string]componentHealth `json:"components"`
+	// Providers are the circuit breakers and rolling stats of the LLM providers from live traffic
+	Providers []vertex.ProviderStats `json:"providers,omitempty"`
+}
+
+// HealthChecker runs the dependency checks concurrently, each bounded by health_timeout_seconds. Results are
+// cached for health_cache_seconds so frequent probes do not spend provider quota.
+type HealthChecker struct {
+	checks        []healthCheck
+	timeout       time.Duration
+	cacheTTL      time.Duration
+	started       time.Time
+	providerStats func() []vertex.ProviderStats
+
+	// mu is held while the checks run, so concurrent probes wait for one run instead of starting their own
+	mu     sync.Mutex
+	cached *healthReport
+}
+
+// NewHealthChecker checks BigQuery with a dry-run query, the SQL store when it serves metadata, the vector backend
+// and every configured LLM provider. bq and store may be nil when not in use.
+func NewHealthChecker(config *vertex.Config, vsSvc *vertex.VertexSearchService, bq *stores.BQ, store *vertex.SQLStore) *HealthChecker {
+	h := &HealthChecker{
+		timeout:       defaultHealthTimeout,
+		cacheTTL:      defaultHealthCacheTTL,
+		started:       time.Now(),
+		providerStats: vsSvc.ProviderStats,
+	}
+	if config.HealthTimeoutSeconds > 0 {
+		h.timeout = time.Duration(config.HealthTimeoutSeconds) * time.Second
+	}
+	if config.HealthCacheSeconds > 0 {
+		h.cacheTTL = time.Duration(config.HealthCacheSeconds) * time.Second
+	}
+
+	if bq != nil {
+		h.checks = append(h.checks, healthCheck{name: "bigquery", required: store == nil, check: bq.Ping})
+	}
+	if store != nil {
+		h.checks = append(h.checks, healthCheck{name: "sql", required: true, check: store.Ping})
+	}
+	h.checks = append(h.checks, healthCheck{
+		name:     "vector:" + vsSvc.VectorBackendName(),
+		required: true,
+		check:    vsSvc.PingVectorBackend,
+	})
+	for _, choice := range vsSvc.LLMChoices() {
+		h.checks = append(h.checks, healthCheck{
+			name: "llm:" + string(choice),
+			llm:  true,
+			check: func(ctx context.Context) error {
+				return vsSvc.PingProvider(ctx, choice)
+			},
+		})
+	}
+	return h
+}
+
+// Check returns the cached report while it is fresh, otherwise runs the checks. The providers' breaker stats are
+// in memory, so they are always current.
+func (h *HealthChecker) Check(ctx context.Context) healthReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cached == nil || time.Since(h.cached.CheckedAt) >= h.cacheTTL {
+		// The result is shared by later probes, so a probe that disconnects must not cancel the checks
+		report := h.runChecks(context.WithoutCancel(ctx))
+		h.cached = &report
+	}
+	report := *h.cached
+	report.Providers = h.providerStats()
+	return report
+}
+
+func (h *HealthChecker) runChecks(ctx context.Context) healthReport {
+	results := make([]componentHealth, len(h.checks))
+	var wg sync.WaitGroup
+	for i, hc := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := hc.check(checkCtx)
+			results[i] = componentHealth{Status: healthOK, Required: hc.required, LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				results[i].Status = healthError
+				results[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := healthReport{
+		Status:     "ok",
+		Ready:      true,
+		CheckedAt:  time.Now(),
+		Components: make(map[string]componentHealth, len(h.checks)),
+	}
+	llms, llmsOK := 0, 0
+	for i, hc := range h.checks {
+		report.Components[hc.name] = results[i]
+		ok := results[i].Status == healthOK
+		if hc.llm {
+			llms++
+			if ok {
+				llmsOK++
+			}
+		}
+		if !ok {
+			report.Status = "degraded"
+			if hc.required {
+				report.Ready = false
+			}
+		}
+	}
+	if llms > 0 && llmsOK == 0 {
+		report.Ready = false
+	}
+	if !report.Ready {
+		report.Status = "unavailable"
+	}
+	return report
+}
+
+// HealthzHandler is the liveness probe. It only reports that the process is serving, so failing dependencies take
+// the instance out of rotation through /readyz instead of restarting it.
+func (h *HealthChecker) HealthzHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":         "ok",
+		"uptime_seconds": int64(time.Since(h.started).Seconds()),
+	})
+}
+
+// ReadyzHandler runs the dependency checks, answering 503 when a required dependency or every LLM provider is
+// unreachable
+func (h *HealthChecker) ReadyzHandler(c *gin.Context) {
+	report := h.Check(c.Request.Context())
+	if !report.Ready {
+		recordErrorMetric(c.Request.Context(), "not_ready")
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chukiagosoftware/alpaca/internal/orm"
+	"github.com/chukiagosoftware/alpaca/models"
+	"github.com/chukiagosoftware/alpaca/vertex/stores"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// isHotelNotFound covers both HotelStore implementations, BQ and orm.DB
+func isHotelNotFound(err error) bool {
+	return errors.Is(err, stores.ErrHotelNotFound) || errors.Is(err, gorm.ErrRecordNotFound)
+}
+
+// HotelStore serves hotel records and their reviews, implemented by stores.BQ and by orm.DB
+type HotelStore interface {
+	GetHotel(ctx context.Context, hotelID string) (*models.Hotel, error)
+	GetReviewsPage(ctx context.Context, hotelID string, q orm.ReviewQuery) ([]*models.HotelReview, int64, error)
+}
+
+// reviewQuery validates the paging and sorting of a hotel's reviews. page is 1-based, an out of range page_size is
+// 20 and sort defaults to date.
+func reviewQuery(page, pageSize int, sortBy string, ascending bool, source string) (orm.ReviewQuery, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	if sortBy == "" {
+		sortBy = "date"
+	}
+	if sortBy != "date" && sortBy != "rating" && sortBy != "helpful" {
+		return orm.ReviewQuery{}, fmt.Errorf("sort must be one of date, rating, helpful")
+	}
+	return orm.ReviewQuery{
+		Source:    strings.TrimSpace(source),
+		SortBy:    sortBy,
+		Ascending: ascending,
+		Limit:     pageSize,
+		Offset:    (page - 1) * pageSize,
+	}, nil
+}
+
+// hotelDetail loads a hotel with one page of its reviews, stores.ErrHotelNotFound when no store has it
+func hotelDetail(ctx context.Context, hotels HotelStore, hotelID string, query orm.ReviewQuery) (*models.Hotel, []*models.HotelReview, int64, error) {
+	hotel, err := hotels.GetHotel(ctx, hotelID)
+	if isHotelNotFound(err) {
+		return nil, nil, 0, stores.ErrHotelNotFound
+	}
+	if err != nil {
+		recordErrorMetric(ctx, "hotel_lookup_error")
+		return nil, nil, 0, fmt.Errorf("failed to get hotel: %w", err)
+	}
+
+	reviews, total, err := hotels.GetReviewsPage(ctx, hotelID, query)
+	if err != nil {
+		recordErrorMetric(ctx, "hotel_reviews_error")
+		return nil, nil, 0, fmt.Errorf("failed to get hotel reviews: %w", err)
+	}
+	if reviews == nil {
+		reviews = []*models.HotelReview{}
+	}
+	return hotel, reviews, total, nil
+}
+
+// HotelDetailHandler returns a hotel with one page of its reviews.
+// Query params: page (1-based), page_size (max 100), sort=date|rating|helpful, order=asc|desc, source.
+func HotelDetailHandler(c *gin.Context, hotels HotelStore, photos *PhotoProxy) {
+	hotelID := strings.TrimSpace(c.Param("id"))
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	query, err := reviewQuery(page, pageSize, c.DefaultQuery("sort", "date"), strings.EqualFold(c.Query("order"), "asc"), c.Query("source"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hotel, reviews, total, err := hotelDetail(c.Request.Context(), hotels, hotelID, query)
+	if errors.Is(err, stores.ErrHotelNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hotel not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Hotel detail error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get hotel"})
+		return
+	}
+
+	// Sentiments holds the raw Amadeus sentiments JSON
+	var sentiments json.RawMessage
+	if json.Valid([]byte(hotel.Sentiments)) {
+		sentiments = json.RawMessage(hotel.Sentiments)
+	}
+
+	media := map[string]any{"photo_name": hotel.PhotoName}
+	if photos != nil {
+		enrichReviewWithGoogleMedia(&media, photos)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hotel":              hotel,
+		"amadeus_sentiments": sentiments,
+		"photo":              media,
+		"reviews":            reviews,
+		"pagination": gin.H{
+			"page":      query.Offset/query.Limit + 1,
+			"page_size": query.Limit,
+			"total":     total,
+		},
+	})
+}
+
+// hotelFields are copied from the retrieved metadata onto the completion items
+var hotelFields = []string{"hotel_id", "distance_km", "important_note"}
+
+// attachHotelFields copies hotel_id, an admin's important_note and, for geo searches, distance_km from the retrieved
+// metadata onto completion items by hotel name, so clients can follow a recommendation to /api/hotels/:id.
+// photo_name is replaced by the retrieved row's, so the photo proxy only ever signs names from our own metadata.
+func attachHotelFields(items []map[string]any, results []map[string]any) {
+	byName := make(map[string]map[string]any, len(results))
+	for _, r := range results {
+		name := strings.ToLower(fmt.Sprintf("%v", r["hotel_name"]))
+		if _, ok := byName[name]; !ok {
+			byName[name] = r
+		}
+	}
+	for _, item := range items {
+		delete(item, "photo_name")
+		name, ok := item["Hotel"].(string)
+		if !ok {
+			continue
+		}
+		r, ok := byName[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		if photoName, ok := r["photo_name"].(string); ok && photoName != "" {
+			item["photo_name"] = photoName
+		}
+		for _, field := range hotelFields {
+			if v, ok := r[field]; ok && v != nil && v != "" {
+				if field == "hotel_id" {
+					v = fmt.Sprintf("%v", v)
+				}
+				item[field] = v
+			}
+		}
+	}
+}
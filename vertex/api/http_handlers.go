@@ -3,66 +3,76 @@ package main
 import (
 	"context"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/chukiagosoftware/alpaca/vertex"
+	"github.com/chukiagosoftware/alpaca/vertex/stores"
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
-func LocationSelectHandler(c *gin.Context, bq *BQ) {
+func LocationSelectHandler(c *gin.Context, bq *stores.BQ) {
+	if bq == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Locations not configured"})
+		return
+	}
 
-	var locations []LocationGroup
+	var locations []stores.LocationGroup
 
 	locations, err := bq.GetDistinctLocations(c)
 
 	if err != nil {
-		log.Printf("error: Failed to get locations: " + err.Error())
+		log.Printf("error: Failed to get locations: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get locations: " + err.Error()})
+		return
 	}
 
 	c.JSON(http.StatusOK, locations)
 }
 
-func recordErrorMetric(c *gin.Context, errorType string) {
+func recordErrorMetric(ctx context.Context, errorType string) {
 	meter := otel.Meter("vertex-search")
 	counter, _ := meter.Int64Counter("search.errors")
-	counter.Add(c.Request.Context(), 1, metric.WithAttributes(
+	counter.Add(ctx, 1, metric.WithAttributes(
 		attribute.String("error_type", errorType),
 	))
 }
 
-func recordLLMMetrics(c *gin.Context, model string, usage vertex.TokenUsage, success bool, isSafe bool) {
+func recordLLMMetrics(ctx context.Context, model, promptVersion string, usage vertex.TokenUsage, success bool, isSafe bool) {
 	meter := otel.Meter("vertex-search")
 
-	// Track which model was used
+	// Track which model and prompt version were used
 	modelCounter, _ := meter.Int64Counter("llm.model.usage")
-	modelCounter.Add(c.Request.Context(), 1, metric.WithAttributes(
+	modelCounter.Add(ctx, 1, metric.WithAttributes(
 		attribute.String("model", model),
+		attribute.String("prompt_version", promptVersion),
 		attribute.Bool("success", success),
 	))
 
 	if usage.PromptTokens > 0 || usage.CompletionTokens > 0 {
 		tokenHist, _ := meter.Int64Histogram("llm.tokens")
-		tokenHist.Record(c.Request.Context(), int64(usage.PromptTokens),
+		tokenHist.Record(ctx, int64(usage.PromptTokens),
 			metric.WithAttributes(attribute.String("type", "prompt"), attribute.String("model", model)))
-		tokenHist.Record(c.Request.Context(), int64(usage.CompletionTokens),
+		tokenHist.Record(ctx, int64(usage.CompletionTokens),
 			metric.WithAttributes(attribute.String("type", "completion"), attribute.String("model", model)))
 	}
 
 	completionCounter, _ := meter.Int64Counter("llm.completion.count")
-	completionCounter.Add(c.Request.Context(), 1, metric.WithAttributes(
+	completionCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("prompt_version", promptVersion),
 		attribute.Bool("success", success),
 		attribute.Bool("safe_query", isSafe),
 	))
 }
 
-func recordVectorSearchMetrics(ctx *gin.Context, durationMs int64, resultCount int) {
+func recordVectorSearchMetrics(ctx context.Context, durationMs int64, resultCount int) {
 	meter := otel.Meter("vertex-search")
 	durationHist, _ := meter.Int64Histogram("search.vector.duration_ms")
 	countGauge, _ := meter.Int64Gauge("search.vector.result_count")
@@ -71,9 +81,21 @@ func recordVectorSearchMetrics(ctx *gin.Context, durationMs int64, resultCount i
 	countGauge.Record(ctx, int64(resultCount))
 }
 
-func buildSearchInput(form vertex.SearchForm, config *vertex.Config) vertex.SearchInput {
+func buildSearchInput(form vertex.SearchForm, config *vertex.Config) (vertex.SearchInput, error) {
 	var input vertex.SearchInput
 
+	mode, err := vertex.ParseSearchMode(form.Mode, config.SearchMode)
+	if err != nil {
+		return input, err
+	}
+	input.Mode = mode
+	input.PromptVersion = form.PromptVersion
+
+	input.Geo, err = vertex.ParseGeoFilter(form.Near, form.RadiusKm)
+	if err != nil {
+		return input, err
+	}
+
 	if form.Question == "" {
 		input.Question = config.Query
 	} else {
@@ -108,206 +130,304 @@ func buildSearchInput(form vertex.SearchForm, config *vertex.Config) vertex.Sear
 		}
 	}
 
-	log.Printf("Form inputs: continent:%s city:%s country:%s rating:%d\n", input.Continent, input.City, input.Country, input.Rating)
-	return input
+	log.Printf("Form inputs: continent:%s city:%s country:%s rating:%d mode:%s\n", input.Continent, input.City, input.Country, input.Rating, input.Mode)
+	return input, nil
 }
 
-func SearchHandler(c *gin.Context, config *vertex.Config, vsSvc *vertex.VertexSearchService, bq *BQ) {
+// searchErrors collects stage failures from the search pipeline goroutines
+type searchErrors struct {
+	mu        sync.Mutex
+	requestID string
+	errs      []*vertex.SearchError
+}
 
-	tracer := otel.Tracer("vertex-search")
-	ctx, span := tracer.Start(c.Request.Context(), "search-request")
-	defer span.End()
+func (s *searchErrors) add(ctx context.Context, stage string, err error) *vertex.SearchError {
+	return s.record(ctx, vertex.NewSearchError(stage, err, s.requestID))
+}
 
-	c.Writer.Header().Set("Content-Type", "application/json")
+// record reports an error built by the caller, who must set its final Code first since the metric records it
+func (s *searchErrors) record(ctx context.Context, searchErr *vertex.SearchError) *vertex.SearchError {
+	recordErrorMetric(ctx, searchErr.Code)
+	log.Printf("[%s] %v", s.requestID, searchErr)
 
-	var form vertex.SearchForm
-	if err := c.ShouldBind(&form); err != nil {
-		recordErrorMetric(c, "bind_error")
-		log.Printf("Failed to bind form: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
-		return
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs = append(s.errs, searchErr)
+	return searchErr
+}
+
+func (s *searchErrors) list() []*vertex.SearchError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*vertex.SearchError{}, s.errs...)
+}
+
+// httpStatusForSearchError maps a stage failure to the status returned when no usable results remain
+func httpStatusForSearchError(e *vertex.SearchError) int {
+	switch {
+	case e.Code == vertex.ErrCodeInvalidRequest:
+		return http.StatusBadRequest
+	case e.Code == vertex.ErrCodeSessionNotFound:
+		return http.StatusNotFound
+	case e.Code == vertex.ErrCodeTimeout:
+		return http.StatusGatewayTimeout
+	case e.Retryable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadGateway
 	}
+}
 
-	input := buildSearchInput(form, config)
+// setRetryAfter passes on the wait the failed LLM providers asked for, rounded up to whole seconds
+func setRetryAfter(c *gin.Context, e *vertex.SearchError) {
+	if e.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds()))))
+	}
+}
 
-	var embedTime, searchTime, safetyTime, metadataTime, completionTime time.Duration
+// SearchBackends are the stores a search reads from besides the VertexSearchService, selected in main from config
+type SearchBackends struct {
+	vertex.RetrievalBackends
+	Photos   *PhotoProxy
+	Sessions vertex.SessionStore
+	Feedback *vertex.FeedbackStore
+}
 
-	completionCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
+// searchTimings are the stage durations of a search in milliseconds
+type searchTimings struct {
+	QueryUnderstandingMs int64 `json:"query_understanding_ms"`
+	GeoPrefilterMs       int64 `json:"geo_prefilter_ms"`
+	EmbeddingMs          int64 `json:"embedding_ms"`
+	VectorSearchMs       int64 `json:"vector_search_ms"`
+	KeywordSearchMs      int64 `json:"keyword_search_ms"`
+	SafetyMs             int64 `json:"safety_ms"`
+	MetadataMs           int64 `json:"metadata_ms"`
+	RerankMs             int64 `json:"rerank_ms"`
+	LLMCompletionMs      int64 `json:"llm_completion_ms"`
+}
 
-	safetyChan := make(chan bool, 1)
-	embedChan := make(chan []float32, 1)
-	vectorChan := make(chan []vertex.VectorResult, 1)
-	vectorCountChan := make(chan int, 1)
-	metadataChan := make(chan []map[string]any, 1)
-	completionChan := make(chan vertex.CompletionResult, 1)
+// searchResult is the outcome of runSearch, rendered as JSON by SearchHandler and as protobuf by the gRPC server
+type searchResult struct {
+	RequestID   string
+	SessionID   string
+	Input       vertex.SearchInput
+	Filters     vertex.QueryFilters
+	Items       []map[string]any
+	Retrieved   []map[string]any
+	Message     string
+	Completion  vertex.CompletionResult
+	VectorCount int
+	Safe        bool
+	Reranker    string
+	Errors      []*vertex.SearchError
+	// Partial is set when a stage failed but results remain. Without items the retrieved reviews are the results.
+	Partial bool
+	// Err decides the response status when no usable results remain
+	Err     *vertex.SearchError
+	Timings searchTimings
+}
 
-	go func() {
-		start := time.Now()
-		_, safetySpan := tracer.Start(ctx, "safety-check")
-		defer safetySpan.End()
-
-		isSafe, err := vsSvc.CheckQuerySafety(ctx, input)
-		safetyTime = time.Since(start)
-
-		if err != nil || !isSafe {
-			if err != nil {
-				log.Printf("Safety check error: %v", err)
-			}
-			cancel()
-			safetyChan <- false
-			return
-		}
-		safetyChan <- true
-	}()
+// prepareSearch builds the pipeline input from the form, assigns its prompt versions and loads the session it
+// continues. A returned error is the response, the search does not run.
+func prepareSearch(ctx context.Context, config *vertex.Config, vsSvc *vertex.VertexSearchService, backends *SearchBackends, stageErrs *searchErrors, form vertex.SearchForm) (vertex.SearchInput, string, *vertex.Session, *vertex.SearchError) {
+	input, err := buildSearchInput(form, config)
+	if err == nil {
+		input, err = vsSvc.AssignPrompts(input)
+	}
+	if err != nil {
+		searchErr := stageErrs.add(ctx, vertex.StageRequest, err)
+		searchErr.Message = err.Error()
+		return input, form.SessionID, nil, searchErr
+	}
 
-	go func() {
-		start := time.Now()
-		_, embedSpan := tracer.Start(ctx, "embedding")
-		defer embedSpan.End()
+	sessionID, session, err := loadSession(ctx, backends.Sessions, form.SessionID)
+	if err != nil {
+		return input, form.SessionID, nil, stageErrs.add(ctx, vertex.StageSession, err)
+	}
+	input.History = session.Turns
+	return input, sessionID, session, nil
+}
 
-		embedding, err := vsSvc.GenerateEmbedding(ctx, input.Question)
-		embedTime = time.Since(start)
+// SearchHandler runs safety, embedding, vector and keyword search, metadata and completion as a goroutine pipeline.
+// mode=hybrid fuses the vector and keyword rankings with reciprocal rank fusion before the metadata lookup,
+// and the optional reranker reorders the metadata results before they reach the completion prompt.
+// Stage failures are reported in "errors". When retrieved reviews survive a later failure they are returned with
+// "partial": true and 200, otherwise the first failure decides the status (502, 503 if retryable, 504 on timeout).
+// A session_id continues a conversation: earlier turns are passed to the completion and the reviews retrieved in the
+// previous turn are fused into the ranking. The session_id to continue with is returned in every response.
+func SearchHandler(c *gin.Context, config *vertex.Config, vsSvc *vertex.VertexSearchService, backends *SearchBackends) {
 
-		if err != nil {
-			recordErrorMetric(c, "embedding_error")
-			log.Printf("Embedding error: %v", err)
-			embedChan <- nil
-			return
-		}
-		embedChan <- embedding
-	}()
-
-	go func() {
-		embedding := <-embedChan
-		if embedding == nil {
-			vectorChan <- nil
-			vectorCountChan <- 0
-			return
-		}
+	tracer := otel.Tracer("vertex-search")
+	ctx, span := tracer.Start(c.Request.Context(), "search-request")
+	defer span.End()
 
-		start := time.Now()
-		_, searchSpan := tracer.Start(ctx, "vector-search")
-		results, err := vsSvc.VertexSearchEndpoint(ctx, *config, embedding, input)
-		//log.Printf("Vector search results: %v", results)
-		searchSpan.End()
-		searchTime = time.Since(start)
-
-		count := 0
-		if results != nil {
-			count = len(results)
-		}
+	c.Writer.Header().Set("Content-Type", "application/json")
 
-		if err != nil {
-			recordErrorMetric(c, "vector_search_error")
-			log.Printf("Vector search error: %v", err)
-			vectorChan <- nil
-			vectorCountChan <- 0
-			return
-		}
-		vectorChan <- results
-		vectorCountChan <- count
-	}()
-
-	go func() {
-		vectorResults := <-vectorChan
-		if vectorResults == nil || len(vectorResults) == 0 {
-			metadataChan <- nil
-			return
-		}
+	requestID := requestIDFrom(c)
+	stageErrs := &searchErrors{requestID: requestID}
 
-		start := time.Now()
-		_, metaSpan := tracer.Start(ctx, "metadata-lookup")
+	var form vertex.SearchForm
+	if err := c.ShouldBind(&form); err != nil {
+		searchErr := stageErrs.add(ctx, vertex.StageRequest, err)
+		searchErr.Message = "Invalid form data"
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      searchErr.Message,
+			"errors":     stageErrs.list(),
+			"request_id": requestID,
+		})
+		return
+	}
 
-		results, err := bq.GetMetadataByIDs(ctx, vectorResults, config)
-		// log.Printf("Metadata lookup results: %v", results)
-		metaSpan.End()
-		metadataTime = time.Since(start)
+	input, sessionID, session, searchErr := prepareSearch(ctx, config, vsSvc, backends, stageErrs, form)
+	if searchErr != nil {
+		setRetryAfter(c, searchErr)
+		c.JSON(httpStatusForSearchError(searchErr), gin.H{
+			"error":      searchErr.Message,
+			"errors":     stageErrs.list(),
+			"request_id": requestID,
+			"session_id": sessionID,
+		})
+		return
+	}
 
-		if err != nil {
-			log.Printf("Metadata lookup error: %v", err)
-			metadataChan <- nil
-			return
-		}
-		metadataChan <- results
-	}()
-
-	go func() {
-		results := <-metadataChan
-		if results == nil {
-			completionChan <- vertex.CompletionResult{Content: "[]"}
-			return
-		}
+	res := runSearch(ctx, config, vsSvc, backends, stageErrs, input, sessionID, session)
+
+	response := gin.H{
+		"completion":            res.Items,
+		"message":               res.Message,
+		"model":                 res.Completion.Model,
+		"usage":                 res.Completion.Usage,
+		"vector_count":          res.VectorCount,
+		"safe_query":            res.Safe,
+		"mode":                  res.Input.Mode,
+		"filters":               res.Filters,
+		"reranker":              res.Reranker,
+		"request_id":            res.RequestID,
+		"prompt_version":        vertex.PromptID(vertex.PromptKindCompletion, res.Input.PromptVersion),
+		"safety_prompt_version": vertex.PromptID(vertex.PromptKindSafety, res.Input.SafetyPromptVersion),
+		"session_id":            res.SessionID,
+		"errors":                res.Errors,
+		"partial":               res.Partial,
+		"timings":               res.Timings,
+	}
 
-		if completionCtx.Err() != nil {
-			completionChan <- vertex.CompletionResult{Content: "[]"}
-			return
-		}
+	status := http.StatusOK
+	if res.Err != nil {
+		status = httpStatusForSearchError(res.Err)
+		response["error"] = res.Err.Message
+		setRetryAfter(c, res.Err)
+	}
+	if res.Partial && len(res.Items) == 0 {
+		// The completion failed but the retrieved reviews are still useful on their own
+		response["retrieved"] = res.Retrieved
+	}
+
+	c.JSON(status, response)
+}
 
+// runSearch is the SearchHandler pipeline, shared with the gRPC Search method. The rerank and completion do not wait
+// for the safety check, which cancels them when it flags the query.
+func runSearch(ctx context.Context, config *vertex.Config, vsSvc *vertex.VertexSearchService, backends *SearchBackends, stageErrs *searchErrors, input vertex.SearchInput, sessionID string, session *vertex.Session) *searchResult {
+	tracer := otel.Tracer("vertex-search")
+
+	completionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// flagged tells a cancellation by the safety check apart from a rerank or completion failure
+	flagged := func() bool { return completionCtx.Err() != nil && ctx.Err() == nil }
+
+	safety := checkSafety(ctx, vsSvc, stageErrs, input, cancel)
+	r := retrieve(ctx, config, vsSvc, backends, stageErrs, input, session)
+	retrieved := r.Rows
+
+	var rerankTime, completionTime time.Duration
+	compResult := vertex.CompletionResult{Content: "[]"}
+	if len(retrieved) > 0 && !flagged() {
+		var err error
+		retrieved, rerankTime, err = rerank(completionCtx, config, vsSvc, r.Input, retrieved)
+		if err != nil && !flagged() {
+			stageErrs.add(ctx, vertex.StageRerank, err)
+		}
+	}
+	if len(retrieved) > 0 && !flagged() {
 		start := time.Now()
 		_, compSpan := tracer.Start(completionCtx, "llm-completion")
-		defer compSpan.End()
-
-		completion, err := vsSvc.PromptCompletion(completionCtx, input, results)
+		completion, err := vsSvc.PromptCompletion(completionCtx, r.Input, retrieved)
+		compSpan.End()
 		completionTime = time.Since(start)
 
-		if err != nil {
-			log.Printf("Completion error: %v", err)
-			completionChan <- vertex.CompletionResult{Content: "[]"}
-			return
+		if err == nil {
+			compResult = completion
+		} else if !flagged() {
+			stageErrs.add(ctx, vertex.StageCompletion, err)
 		}
-		completionChan <- completion
-	}()
-
-	isSafe := <-safetyChan
-	compResult := <-completionChan
+	}
 
+	verdict := <-safety
 	var userMessage string
-	if !isSafe {
-		userMessage = "Your query was flagged as not relevant to hotel reviews. Please try a different question."
-		compResult = vertex.CompletionResult{Content: "Your query was flagged as not relevant to hotel reviews. Please try a different question."}
+	if !verdict.Safe {
+		// Nothing retrieved or completed for an unsafe or unchecked query is returned, logged or kept in the session
+		retrieved = nil
+		compResult = vertex.CompletionResult{}
+		if verdict.Err == nil {
+			userMessage = flaggedMessage
+		}
 	}
 
-	vectorCount := 0
-	select {
-	case vectorCount = <-vectorCountChan:
-	default:
+	parsedReviews := []map[string]any{}
+	if verdict.Safe {
+		var parseErr error
+		parsedReviews, parseErr = vertex.ParseCompletionJSON(compResult.Content)
+		if parseErr != nil {
+			searchErr := vertex.NewSearchError(vertex.StageCompletion, parseErr, stageErrs.requestID)
+			searchErr.Code = vertex.ErrCodeCompletionInvalid
+			searchErr.Message = "completion returned invalid JSON"
+			searchErr.Retryable = true
+			stageErrs.record(ctx, searchErr)
+			parsedReviews = []map[string]any{}
+		}
 	}
 
-	parsedReviews, parseErr := parseCompletionJSON(compResult.Content)
-	if parseErr != nil {
-		log.Printf("Failed to parse LLM JSON: %v", parseErr)
-		parsedReviews = []map[string]any{}
-		recordErrorMetric(c, "json_parse_error")
+	if backends.Photos == nil {
+		log.Println("Warning: GOOGLE_MAPS_API_KEY not set - maps/photos skipped")
 	}
+	presentItems(parsedReviews, retrieved, backends.Photos)
 
-	googleKey := config.GooglePlacesAPIKey
-	if googleKey == "" {
-		log.Println("Warning: GOOGLE_MAPS_API_KEY not set - maps/photos skipped")
-	} else {
-		for i := range parsedReviews {
-			enrichReviewWithGoogleMedia(&parsedReviews[i], googleKey)
-		}
+	if len(parsedReviews) > 0 {
+		saveSessionTurn(ctx, stageErrs.requestID, backends.Sessions, sessionID, input.Question, parsedReviews, retrieved)
 	}
 
-	recordLLMMetrics(c, compResult.Model, compResult.Usage, len(parsedReviews) > 0 || userMessage != "", isSafe)
-	recordVectorSearchMetrics(c, searchTime.Milliseconds(), vectorCount)
+	errs := stageErrs.list()
+	res := &searchResult{
+		RequestID:   stageErrs.requestID,
+		SessionID:   sessionID,
+		Input:       input,
+		Filters:     r.Filters,
+		Items:       parsedReviews,
+		Retrieved:   retrieved,
+		Message:     userMessage,
+		Completion:  compResult,
+		VectorCount: len(r.Results),
+		Safe:        verdict.Safe,
+		Reranker:    vsSvc.RerankerName(),
+		Errors:      errs,
+		Timings:     newSearchTimings(r.Timings, verdict.Elapsed, rerankTime, completionTime),
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"completion":   parsedReviews,
-		"message":      userMessage,
-		"model":        compResult.Model,
-		"usage":        compResult.Usage,
-		"vector_count": vectorCount,
-		"safe_query":   isSafe,
-		"timings": gin.H{
-			"embedding_ms":      embedTime.Milliseconds(),
-			"vector_search_ms":  searchTime.Milliseconds(),
-			"safety_ms":         safetyTime.Milliseconds(),
-			"metadata_ms":       metadataTime.Milliseconds(),
-			"llm_completion_ms": completionTime.Milliseconds(),
-		},
-	})
+	switch {
+	case verdict.Err != nil:
+		res.Err = verdict.Err
+	case !verdict.Safe:
+		// Flagged queries carry only the message, never a partial result
+	case len(errs) > 0 && len(parsedReviews) == 0 && len(retrieved) > 0:
+		res.Partial = true
+	case len(errs) > 0 && len(parsedReviews) == 0:
+		res.Err = errs[0]
+	case len(errs) > 0:
+		res.Partial = true
+	}
+
+	finishSearch(ctx, vsSvc, backends, stageErrs, sessionID, input, r, retrieved, parsedReviews, compResult, verdict, res.Partial)
+	return res
 }
 
 func Pong(c *gin.Context) {
@@ -2,27 +2,42 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
-	"time"
+	"syscall"
 
 	"github.com/chukiagosoftware/alpaca/vertex"
+	"github.com/chukiagosoftware/alpaca/vertex/stores"
 	"github.com/gin-contrib/timeout"
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"google.golang.org/grpc"
 )
 
 func timeoutResponse(c *gin.Context) {
 	c.String(http.StatusRequestTimeout, "timeout")
 }
 
-func timeoutMiddleware() gin.HandlerFunc {
-	return timeout.New(
-		timeout.WithTimeout(60*time.Second),
+func timeoutMiddleware(config *vertex.Config) gin.HandlerFunc {
+	withTimeout := timeout.New(
+		timeout.WithTimeout(searchTimeout(config)),
 		timeout.WithResponse(timeoutResponse),
 	)
+	return func(c *gin.Context) {
+		// The timeout writer buffers the whole response, which would hold back Server-Sent Events
+		if c.Request.URL.Path == "/api/search/stream" {
+			c.Next()
+			return
+		}
+		withTimeout(c)
+	}
 }
 
 func main() {
@@ -30,6 +45,9 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err := config.Validate(); err != nil {
+		log.Fatal("Invalid config:", err)
+	}
 
 	log.Printf("Loaded config: ProjectID=%s\n, Location=%s\n, DatasetID=%s\n, IndexID=%s\n, EndpointID=%s\n, Domain=%s\n",
 		config.ProjectID,
@@ -39,25 +57,85 @@ func main() {
 		config.EndpointID,
 		config.EndpointPublicDomainName)
 
-	vertex.InitTracer("alpaca-vertex-search")
+	shutdownTracer := vertex.InitTracer("alpaca-vertex-search")
+	// Deferred first so it runs last, flushing the spans of the requests drained below
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout(config))
+		defer cancel()
+		if err := shutdownTracer(ctx); err != nil {
+			log.Printf("Failed to flush traces: %v", err)
+		}
+	}()
 
+	// Clients outlive the signal context, they are closed by the deferred calls once requests have drained
 	ctx := context.Background()
-	// defer cancel()
+	signalCtx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	// One connection serves every SQL store, so the migrations run once and SQLite has a single writer. It is
+	// deferred first so it is closed after the stores.
+	db, err := vertex.OpenDatabase(config)
+	if err != nil {
+		log.Printf("Warning: database unavailable: %v", err)
+		db = nil
+	} else {
+		defer db.Close()
+	}
 
-	vsSvc, err := vertex.NewVertexSearchService(ctx, config)
+	vsSvc, err := vertex.NewVertexSearchService(ctx, config, db)
 	if err != nil {
 		log.Fatal("Failed to create Vertex service:", err)
 	}
 	defer vsSvc.Close()
 
-	bq, err := NewBigQueryService(ctx, *config)
+	// The same constructor selects the stores for the eval, so it retrieves exactly like the API
+	searchStores, err := stores.Open(ctx, config, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer searchStores.Close()
+	bq, store := searchStores.BigQuery, searchStores.SQL
+
+	backends := &SearchBackends{RetrievalBackends: searchStores.Retrieval}
+	var hotels HotelStore = bq
+	var moderator HotelModerator = bq
+	if store != nil {
+		hotels = store.DB()
+		moderator = store.DB()
+	}
+
+	var photos *PhotoProxy
+	if config.GooglePlacesAPIKey != "" {
+		var refresh func(ctx context.Context) error
+		if bq != nil {
+			refresh = func(ctx context.Context) error {
+				return bq.RefreshPhotoNames(ctx, config)
+			}
+		}
+		photos = NewPhotoProxy(config, refresh)
+	}
+	backends.Photos = photos
+
+	sessions, err := vertex.NewSessionStore(config, db)
+	if err != nil {
+		log.Fatal("Failed to create session store:", err)
+	}
+	defer sessions.Close()
+	backends.Sessions = sessions
+
+	if db != nil {
+		backends.Feedback = vertex.NewFeedbackStore(db)
+	} else {
+		log.Println("Warning: feedback and search logging disabled without a database")
+	}
 
 	// Setup our http server with OpenTelemetry spans
 	r := gin.Default()
+	r.Use(RequestIDMiddleware())
 	r.Use(CORSMiddleware(*config))
 	r.Use(otelgin.Middleware("vertex-search"))
 
-	r.Use(timeoutMiddleware())
+	r.Use(timeoutMiddleware(config))
 
 	distDir := filepath.Join(".", "frontend-vite", "dist")
 	assetsDir := filepath.Join(distDir, "assets")
@@ -65,7 +143,40 @@ func main() {
 	r.StaticFS("/assets/", http.Dir(assetsDir))
 
 	r.POST("/api/search", func(c *gin.Context) {
-		SearchHandler(c, config, vsSvc, bq)
+		SearchHandler(c, config, vsSvc, backends)
+	})
+
+	r.GET("/api/search/stream", func(c *gin.Context) {
+		SearchStreamHandler(c, config, vsSvc, backends)
+	})
+
+	r.POST("/api/feedback", func(c *gin.Context) {
+		FeedbackHandler(c, backends.Feedback)
+	})
+
+	r.DELETE("/api/sessions/:id", func(c *gin.Context) {
+		DeleteSessionHandler(c, sessions)
+	})
+
+	r.GET("/api/hotels/:id", func(c *gin.Context) {
+		HotelDetailHandler(c, hotels, photos)
+	})
+
+	if config.AdminToken != "" {
+		admin := r.Group("/api/admin", AdminAuthMiddleware(config.AdminToken))
+		admin.PATCH("/hotels/:id", func(c *gin.Context) {
+			HotelModerationHandler(c, hotels, moderator)
+		})
+	} else {
+		log.Println("Admin API disabled, set admin_token to enable it")
+	}
+
+	r.GET("/api/photos/*name", func(c *gin.Context) {
+		if photos == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Photos not configured"})
+			return
+		}
+		photos.PhotoHandler(c)
 	})
 
 	r.GET("/api/locations", func(c *gin.Context) {
@@ -77,6 +188,10 @@ func main() {
 		Pong(c)
 	})
 
+	health := NewHealthChecker(config, vsSvc, bq, store)
+	r.GET("/healthz", health.HealthzHandler)
+	r.GET("/readyz", health.ReadyzHandler)
+
 	r.NoRoute(func(c *gin.Context) {
 		if strings.HasPrefix(c.Request.URL.Path, "/api/") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "API endpoint not found"})
@@ -85,8 +200,54 @@ func main() {
 		c.File(distDir + "/index.html")
 	})
 
-	log.Println("Starting server on :8080")
-	if err := r.Run(":8080"); err != nil {
-		log.Fatal("Failed to start server:", err)
+	// Serve errors end main like SIGTERM does, so the deferred cleanup still runs
+	serveErr := make(chan error, 2)
+
+	var grpcServer *grpc.Server
+	if config.GRPCPort != "" {
+		addr := ":" + strings.TrimPrefix(config.GRPCPort, ":")
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatal("Failed to listen for gRPC:", err)
+		}
+		grpcServer = NewGRPCServer(config, vsSvc, backends, hotels, bq)
+		go func() {
+			log.Printf("Starting gRPC server on %s", addr)
+			if err := grpcServer.Serve(lis); err != nil {
+				serveErr <- fmt.Errorf("gRPC server failed: %w", err)
+			}
+		}()
+	}
+
+	srv := newHTTPServer(config, r)
+	go func() {
+		log.Printf("Starting server on %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- fmt.Errorf("server failed: %w", err)
+		}
+	}()
+
+	select {
+	case <-signalCtx.Done():
+		log.Println("Shutting down, draining in-flight requests")
+	case err := <-serveErr:
+		log.Printf("Shutting down: %v", err)
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout(config))
+	defer cancel()
+	if err := srv.Shutdown(drainCtx); err != nil {
+		// Requests still running past the deadline are cut off, so the deferred Close calls do not race them
+		log.Printf("Failed to drain HTTP requests: %v", err)
+		srv.Close()
+	}
+	if grpcServer != nil {
+		if drainCtx.Err() != nil {
+			grpcServer.Stop()
+		} else {
+			stopGRPC(drainCtx, grpcServer)
+		}
 	}
+	// Search logs written by the drained requests finish before the database is closed
+	searchLogs.Wait()
 }
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+)
+
+const (
+	photoURLTTL         = time.Hour
+	photoCacheTTL       = 24 * time.Hour
+	photoMemoryEntries  = 512
+	photoRefreshBackoff = 6 * time.Hour
+	photoMaxBytes       = 10 << 20
+)
+
+var photoWidths = map[int]bool{120: true, 400: true, 800: true, 1600: true}
+
+type cachedPhoto struct {
+	data        []byte
+	contentType string
+	expires     time.Time
+}
+
+// PhotoProxy serves Google Places photos through /api/photos so the Places API key never reaches the browser.
+// Clients receive short-lived HMAC signed URLs; media bytes are cached in memory and optionally on disk.
+type PhotoProxy struct {
+	apiKey     string
+	signingKey []byte
+	cacheDir   string
+	client     *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedPhoto
+
+	// refresh re-fetches expired photo names, at most once per photoRefreshBackoff
+	refresh     func(ctx context.Context) error
+	refreshMu   sync.Mutex
+	lastRefresh time.Time
+}
+
+func NewPhotoProxy(config *vertex.Config, refresh func(ctx context.Context) error) *PhotoProxy {
+	signingKey := []byte(config.PhotoURLSigningKey)
+	if len(signingKey) == 0 {
+		log.Println("Warning: photo_url_signing_key not set - signed photo URLs only valid for this instance")
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			log.Fatal("Failed to generate photo signing key:", err)
+		}
+	}
+	if config.PhotoCacheDir != "" {
+		if err := os.MkdirAll(config.PhotoCacheDir, 0755); err != nil {
+			log.Printf("Photo disk cache disabled: %v", err)
+			config.PhotoCacheDir = ""
+		}
+	}
+	return &PhotoProxy{
+		apiKey:     config.GooglePlacesAPIKey,
+		signingKey: signingKey,
+		cacheDir:   config.PhotoCacheDir,
+		client:     &http.Client{Timeout: 15 * time.Second},
+		cache:      make(map[string]cachedPhoto),
+		refresh:    refresh,
+	}
+}
+
+// SignedURL returns a relative /api/photos URL for a Places photo name, valid for photoURLTTL
+func (p *PhotoProxy) SignedURL(photoName string, maxWidthPx int) string {
+	expires := time.Now().Add(photoURLTTL).Unix()
+	q := url.Values{}
+	q.Set("w", strconv.Itoa(maxWidthPx))
+	q.Set("exp", strconv.FormatInt(expires, 10))
+	q.Set("sig", p.sign(photoName, maxWidthPx, expires))
+	return "/api/photos/" + photoName + "?" + q.Encode()
+}
+
+func (p *PhotoProxy) sign(photoName string, maxWidthPx int, expires int64) string {
+	mac := hmac.New(sha256.New, p.signingKey)
+	fmt.Fprintf(mac, "%s|%d|%d", photoName, maxWidthPx, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p *PhotoProxy) verify(photoName string, maxWidthPx int, expires int64, sig string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+	expected := p.sign(photoName, maxWidthPx, expires)
+	return hmac.Equal([]byte(expected), []byte(sig))
+}
+
+// PhotoHandler serves GET /api/photos/*name?w=&exp=&sig=
+func (p *PhotoProxy) PhotoHandler(c *gin.Context) {
+	photoName := strings.TrimPrefix(c.Param("name"), "/")
+	maxWidthPx, _ := strconv.Atoi(c.Query("w"))
+	expires, _ := strconv.ParseInt(c.Query("exp"), 10, 64)
+
+	if !strings.HasPrefix(photoName, "places/") || !strings.Contains(photoName, "/photos/") || !photoWidths[maxWidthPx] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo request"})
+		return
+	}
+	if !p.verify(photoName, maxWidthPx, expires, c.Query("sig")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Photo URL expired or invalid"})
+		return
+	}
+
+	key := fmt.Sprintf("%s@%d", photoName, maxWidthPx)
+	photo, ok := p.cached(key)
+	if !ok {
+		var status int
+		var err error
+		photo, status, err = p.fetch(c.Request.Context(), photoName, maxWidthPx)
+		if err != nil {
+			log.Printf("Photo fetch failed for %s: %v", photoName, err)
+			if status == http.StatusBadRequest || status == http.StatusNotFound {
+				// Places photo names expire; stale names come back as 400/404
+				p.triggerRefresh()
+				c.JSON(http.StatusNotFound, gin.H{"error": "Photo expired"})
+				return
+			}
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch photo"})
+			return
+		}
+		p.store(key, photo)
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(photoURLTTL.Seconds())))
+	c.Data(http.StatusOK, photo.contentType, photo.data)
+}
+
+func (p *PhotoProxy) fetch(ctx context.Context, photoName string, maxWidthPx int) (cachedPhoto, int, error) {
+	mediaURL := fmt.Sprintf("https://places.googleapis.com/v1/%s/media?maxWidthPx=%d", photoName, maxWidthPx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return cachedPhoto{}, 0, err
+	}
+	req.Header.Set("X-Goog-Api-Key", p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return cachedPhoto{}, 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, photoMaxBytes))
+	if err != nil {
+		return cachedPhoto{}, resp.StatusCode, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return cachedPhoto{}, resp.StatusCode, fmt.Errorf("places media returned %s: %s", resp.Status, string(data))
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return cachedPhoto{data: data, contentType: contentType, expires: time.Now().Add(photoCacheTTL)}, resp.StatusCode, nil
+}
+
+func (p *PhotoProxy) cached(key string) (cachedPhoto, bool) {
+	p.mu.Lock()
+	photo, ok := p.cache[key]
+	p.mu.Unlock()
+	if ok && time.Now().Before(photo.expires) {
+		return photo, true
+	}
+
+	if p.cacheDir == "" {
+		return cachedPhoto{}, false
+	}
+	path := p.diskPath(key)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > photoCacheTTL {
+		return cachedPhoto{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cachedPhoto{}, false
+	}
+	photo = cachedPhoto{data: data, contentType: http.DetectContentType(data), expires: info.ModTime().Add(photoCacheTTL)}
+	p.storeMemory(key, photo)
+	return photo, true
+}
+
+func (p *PhotoProxy) store(key string, photo cachedPhoto) {
+	p.storeMemory(key, photo)
+	if p.cacheDir == "" {
+		return
+	}
+	if err := os.WriteFile(p.diskPath(key), photo.data, 0644); err != nil {
+		log.Printf("Failed to write photo cache: %v", err)
+	}
+}
+
+func (p *PhotoProxy) storeMemory(key string, photo cachedPhoto) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.cache) >= photoMemoryEntries {
+		now := time.Now()
+		for k, v := range p.cache {
+			if now.After(v.expires) {
+				delete(p.cache, k)
+			}
+		}
+		// Still full: drop an arbitrary entry, the disk cache keeps a copy
+		for k := range p.cache {
+			if len(p.cache) < photoMemoryEntries {
+				break
+			}
+			delete(p.cache, k)
+		}
+	}
+	p.cache[key] = photo
+}
+
+func (p *PhotoProxy) diskPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(p.cacheDir, hex.EncodeToString(sum[:]))
+}
+
+func (p *PhotoProxy) triggerRefresh() {
+	if p.refresh == nil {
+		return
+	}
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+	if time.Since(p.lastRefresh) < photoRefreshBackoff {
+		return
+	}
+	p.lastRefresh = time.Now()
+
+	go func() {
+		log.Println("Expired photo name detected, refreshing photo names")
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		if err := p.refresh(ctx); err != nil {
+			log.Printf("Photo name refresh failed: %v", err)
+		}
+	}()
+}
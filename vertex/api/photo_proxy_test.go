@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testPhotoName = "places/ChIJ123/photos/AbC"
+
+func TestPhotoProxyVerify(t *testing.T) {
+	p := &PhotoProxy{signingKey: []byte("key")}
+	expires := time.Now().Add(photoURLTTL).Unix()
+	sig := p.sign(testPhotoName, 400, expires)
+
+	tests := []struct {
+		name      string
+		proxy     *PhotoProxy
+		photoName string
+		width     int
+		expires   int64
+		sig       string
+		want      bool
+	}{
+		{name: "valid", proxy: p, photoName: testPhotoName, width: 400, expires: expires, sig: sig, want: true},
+		{name: "expired", proxy: p, photoName: testPhotoName, width: 400, expires: time.Now().Add(-time.Minute).Unix(), sig: p.sign(testPhotoName, 400, time.Now().Add(-time.Minute).Unix())},
+		{name: "tampered signature", proxy: p, photoName: testPhotoName, width: 400, expires: expires, sig: sig[:len(sig)-1] + "0"},
+		{name: "other photo", proxy: p, photoName: "places/ChIJ456/photos/XyZ", width: 400, expires: expires, sig: sig},
+		{name: "other width", proxy: p, photoName: testPhotoName, width: 1600, expires: expires, sig: sig},
+		{name: "extended expiry", proxy: p, photoName: testPhotoName, width: 400, expires: expires + 3600, sig: sig},
+		{name: "other signing key", proxy: &PhotoProxy{signingKey: []byte("other")}, photoName: testPhotoName, width: 400, expires: expires, sig: sig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.proxy.verify(tt.photoName, tt.width, tt.expires, tt.sig); got != tt.want {
+				t.Errorf("verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhotoProxySignedURL(t *testing.T) {
+	p := &PhotoProxy{signingKey: []byte("key")}
+	u, err := url.Parse(p.SignedURL(testPhotoName, 120))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/api/photos/"+testPhotoName {
+		t.Errorf("path = %q, want the photo name under /api/photos", u.Path)
+	}
+	q := u.Query()
+	width, _ := strconv.Atoi(q.Get("w"))
+	expires, _ := strconv.ParseInt(q.Get("exp"), 10, 64)
+	if width != 120 || !p.verify(testPhotoName, width, expires, q.Get("sig")) {
+		t.Errorf("SignedURL = %s does not verify", u)
+	}
+}
+
+func TestPhotoHandlerRejects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	p := &PhotoProxy{signingKey: []byte("key")}
+	router := gin.New()
+	router.GET("/api/photos/*name", p.PhotoHandler)
+
+	expires := time.Now().Add(photoURLTTL).Unix()
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{name: "not a places photo", target: "/api/photos/other/thing?w=400", want: http.StatusBadRequest},
+		{name: "unsupported width", target: "/api/photos/" + testPhotoName + "?w=401", want: http.StatusBadRequest},
+		{name: "unsigned", target: "/api/photos/" + testPhotoName + "?w=400&exp=" + strconv.FormatInt(expires, 10), want: http.StatusForbidden},
+		{name: "signed for another photo", target: "/api/photos/places/ChIJ456/photos/XyZ?w=400&exp=" + strconv.FormatInt(expires, 10) + "&sig=" + p.sign(testPhotoName, 400, expires), want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachHotelFieldsPhotoName(t *testing.T) {
+	retrieved := []map[string]any{
+		{"hotel_name": "Grand Hotel", "hotel_id": 7, "photo_name": testPhotoName},
+		{"hotel_name": "Plain Inn", "hotel_id": 8},
+	}
+	tests := []struct {
+		name string
+		item map[string]any
+		want any
+	}{
+		{name: "taken from the retrieved row", item: map[string]any{"Hotel": "grand hotel", "photo_name": "places/evil/photos/1"}, want: testPhotoName},
+		{name: "retrieved row without a photo", item: map[string]any{"Hotel": "Plain Inn", "photo_name": "places/evil/photos/1"}},
+		{name: "hotel not retrieved", item: map[string]any{"Hotel": "Made Up Hotel", "photo_name": "places/evil/photos/1"}},
+		{name: "no hotel name", item: map[string]any{"photo_name": "places/evil/photos/1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attachHotelFields([]map[string]any{tt.item}, retrieved)
+			if got := tt.item["photo_name"]; got != tt.want {
+				t.Errorf("photo_name = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware propagates the caller's X-Request-ID or assigns a new one, echoing it in the response
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > vertex.MaxRequestIDLength {
+			requestID = newRequestID()
+		}
+		c.Set("request_id", requestID)
+		c.Header(requestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+func requestIDFrom(c *gin.Context) string {
+	return c.GetString("request_id")
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+type requestIDKey struct{}
+
+// RequestIDUnaryInterceptor is RequestIDMiddleware for gRPC, reading and echoing x-request-id metadata
+func RequestIDUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		requestID := incomingRequestID(ctx)
+		if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, requestID)); err != nil {
+			log.Printf("[%s] Failed to set request ID header: %v", requestID, err)
+		}
+		return handler(context.WithValue(ctx, requestIDKey{}, requestID), req)
+	}
+}
+
+// RequestIDStreamInterceptor is RequestIDUnaryInterceptor for streaming methods
+func RequestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		requestID := incomingRequestID(ss.Context())
+		if err := ss.SetHeader(metadata.Pairs(requestIDHeader, requestID)); err != nil {
+			log.Printf("[%s] Failed to set request ID header: %v", requestID, err)
+		}
+		return handler(srv, &requestIDStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), requestIDKey{}, requestID)})
+	}
+}
+
+type requestIDStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *requestIDStream) Context() context.Context { return s.ctx }
+
+func incomingRequestID(ctx context.Context) string {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDHeader); len(ids) > 0 {
+			requestID = ids[0]
+		}
+	}
+	if requestID == "" || len(requestID) > vertex.MaxRequestIDLength {
+		requestID = newRequestID()
+	}
+	return requestID
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+	"go.opentelemetry.io/otel"
+)
+
+// The stages shared by runSearch and runSearchStream. Retrieval itself is vertex.Retrieve; the two pipelines only
+// differ in when they wait for the safety check and in how the completion reaches the client.
+
+// flaggedMessage is returned instead of results when the safety check flags a query
+const flaggedMessage = "Your query was flagged as not relevant to hotel reviews. Please try a different question."
+
+// retrieve runs the retrieval stages of a search, recording failed stages in stageErrs
+func retrieve(ctx context.Context, config *vertex.Config, vsSvc *vertex.VertexSearchService, backends *SearchBackends, stageErrs *searchErrors, input vertex.SearchInput, session *vertex.Session) vertex.Retrieval {
+	return vsSvc.Retrieve(ctx, config, backends.RetrievalBackends, input, session.PriorReviewIDs(), func(stage string, err error) {
+		stageErrs.add(ctx, stage, err)
+	})
+}
+
+// safetyVerdict is the outcome of the safety check, Safe is false when the check failed with Err
+type safetyVerdict struct {
+	Safe    bool
+	Err     *vertex.SearchError
+	Elapsed time.Duration
+}
+
+// checkSafety runs the safety check alongside retrieval. onFlagged, when set, is called as soon as the query is
+// flagged or the check fails, before the verdict is sent.
+func checkSafety(ctx context.Context, vsSvc *vertex.VertexSearchService, stageErrs *searchErrors, input vertex.SearchInput, onFlagged func()) <-chan safetyVerdict {
+	verdict := make(chan safetyVerdict, 1)
+	go func() {
+		start := time.Now()
+		_, safetySpan := otel.Tracer("vertex-search").Start(ctx, "safety-check")
+		defer safetySpan.End()
+
+		safe, err := vsSvc.CheckQuerySafety(ctx, input)
+		v := safetyVerdict{Safe: err == nil && safe, Elapsed: time.Since(start)}
+		if err != nil {
+			v.Err = stageErrs.add(ctx, vertex.StageSafety, err)
+		}
+		if !v.Safe && onFlagged != nil {
+			onFlagged()
+		}
+		verdict <- v
+	}()
+	return verdict
+}
+
+// rerank reorders the retrieved rows for the completion prompt. On failure it falls back to the retrieval order,
+// trimmed so the prompt does not grow, and returns the error for the caller to report.
+func rerank(ctx context.Context, config *vertex.Config, vsSvc *vertex.VertexSearchService, input vertex.SearchInput, rows []map[string]any) ([]map[string]any, time.Duration, error) {
+	if len(rows) == 0 {
+		return rows, 0, nil
+	}
+	start := time.Now()
+	_, rerankSpan := otel.Tracer("vertex-search").Start(ctx, "rerank")
+	reranked, err := vsSvc.Rerank(ctx, input, rows, config.Limit)
+	rerankSpan.End()
+	if err != nil {
+		reranked = rows[:min(len(rows), config.Limit)]
+	}
+	return reranked, time.Since(start), err
+}
+
+// presentItems copies the hotel fields of the retrieved rows onto completion items and adds their map and photo URLs
+func presentItems(items, retrieved []map[string]any, photos *PhotoProxy) {
+	attachHotelFields(items, retrieved)
+	if photos == nil {
+		return
+	}
+	for i := range items {
+		enrichReviewWithGoogleMedia(&items[i], photos)
+	}
+}
+
+// newSearchTimings combines the retrieval timings with the stages run by the handlers
+func newSearchTimings(r vertex.RetrievalTimings, safety, rerank, completion time.Duration) searchTimings {
+	return searchTimings{
+		QueryUnderstandingMs: r.Understand.Milliseconds(),
+		GeoPrefilterMs:       r.Geo.Milliseconds(),
+		EmbeddingMs:          r.Embedding.Milliseconds(),
+		VectorSearchMs:       r.VectorSearch.Milliseconds(),
+		KeywordSearchMs:      r.Keyword.Milliseconds(),
+		SafetyMs:             safety.Milliseconds(),
+		MetadataMs:           r.Metadata.Milliseconds(),
+		RerankMs:             rerank.Milliseconds(),
+		LLMCompletionMs:      completion.Milliseconds(),
+	}
+}
+
+// finishSearch records the metrics of a finished search and logs it for feedback
+func finishSearch(ctx context.Context, vsSvc *vertex.VertexSearchService, backends *SearchBackends, stageErrs *searchErrors, sessionID string, input vertex.SearchInput, r vertex.Retrieval, retrieved, items []map[string]any, completion vertex.CompletionResult, verdict safetyVerdict, partial bool) {
+	flagged := !verdict.Safe && verdict.Err == nil
+	recordLLMMetrics(ctx, completion.Model, vertex.PromptID(vertex.PromptKindCompletion, input.PromptVersion), completion.Usage, len(items) > 0 || flagged, verdict.Safe)
+	recordVectorSearchMetrics(ctx, r.Timings.VectorSearch.Milliseconds(), len(r.Results))
+	logSearchRequest(ctx, backends.Feedback, vertex.SearchLog{
+		RequestID:    stageErrs.requestID,
+		SessionID:    sessionID,
+		Input:        r.Input,
+		RetrievedIDs: retrievedIDs(retrieved),
+		HotelIDs:     recommendedHotelIDs(items),
+		Model:        completion.Model,
+		Reranker:     vsSvc.RerankerName(),
+		Partial:      partial,
+	})
+}
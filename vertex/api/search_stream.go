@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+// completionItemScanner extracts complete top-level objects from a JSON array that arrives in chunks,
+// so each hotel recommendation can be sent as soon as the provider has finished writing it
+type completionItemScanner struct {
+	text     []byte
+	pos      int
+	depth    int
+	start    int
+	inString bool
+	escaped  bool
+}
+
+func (s *completionItemScanner) Write(chunk string) []map[string]any {
+	s.text = append(s.text, chunk...)
+
+	var items []map[string]any
+	for ; s.pos < len(s.text); s.pos++ {
+		ch := s.text[s.pos]
+		if s.inString {
+			switch {
+			case s.escaped:
+				s.escaped = false
+			case ch == '\\':
+				s.escaped = true
+			case ch == '"':
+				s.inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			if s.depth > 0 {
+				s.inString = true
+			}
+		case '{':
+			if s.depth == 0 {
+				s.start = s.pos
+			}
+			s.depth++
+		case '}':
+			if s.depth == 0 {
+				continue
+			}
+			s.depth--
+			if s.depth == 0 {
+				var item map[string]any
+				if err := json.Unmarshal(s.text[s.start:s.pos+1], &item); err != nil {
+					log.Printf("Skipping malformed streamed completion item: %v", err)
+					continue
+				}
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// SearchStreamHandler runs the same pipeline as SearchHandler but reports each stage as a Server-Sent Event:
+// "filters" with what was inferred from the question, "reviews" once the query passed the safety check, "reviews_order"
+// with the review IDs after reranking, one "item" per completed recommendation, and "done" with timings and usage.
+// Unsafe queries produce a "message" event and failures an "error" event, both followed by "done".
+func SearchStreamHandler(c *gin.Context, config *vertex.Config, vsSvc *vertex.VertexSearchService, backends *SearchBackends) {
+	tracer := otel.Tracer("vertex-search")
+	ctx, span := tracer.Start(c.Request.Context(), "search-stream-request")
+	defer span.End()
+
+	requestID := requestIDFrom(c)
+	stageErrs := &searchErrors{requestID: requestID}
+
+	var form vertex.SearchForm
+	if err := c.ShouldBind(&form); err != nil {
+		searchErr := stageErrs.add(ctx, vertex.StageRequest, err)
+		searchErr.Message = "Invalid form data"
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      searchErr.Message,
+			"errors":     stageErrs.list(),
+			"request_id": requestID,
+		})
+		return
+	}
+
+	input, sessionID, session, searchErr := prepareSearch(ctx, config, vsSvc, backends, stageErrs, form)
+	if searchErr != nil {
+		setRetryAfter(c, searchErr)
+		c.JSON(httpStatusForSearchError(searchErr), gin.H{
+			"error":      searchErr.Message,
+			"errors":     stageErrs.list(),
+			"request_id": requestID,
+			"session_id": sessionID,
+		})
+		return
+	}
+
+	// The timeout middleware buffers responses, so streaming requests carry their own deadline instead
+	ctx, cancel := context.WithTimeout(ctx, searchTimeout(config))
+	defer cancel()
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	runSearchStream(ctx, config, vsSvc, backends, stageErrs, input, sessionID, session, func(event string, data any) {
+		c.SSEvent(event, data)
+		c.Writer.Flush()
+	})
+}
+
+// searchSummary is the "done" event ending a streamed search
+type searchSummary struct {
+	Model               string                `json:"model"`
+	Usage               vertex.TokenUsage     `json:"usage"`
+	VectorCount         int                   `json:"vector_count"`
+	ItemCount           int                   `json:"item_count"`
+	SafeQuery           bool                  `json:"safe_query"`
+	Mode                string                `json:"mode"`
+	Filters             vertex.QueryFilters   `json:"filters"`
+	Reranker            string                `json:"reranker"`
+	RequestID           string                `json:"request_id"`
+	PromptVersion       string                `json:"prompt_version"`
+	SafetyPromptVersion string                `json:"safety_prompt_version"`
+	SessionID           string                `json:"session_id"`
+	Errors              []*vertex.SearchError `json:"errors"`
+	Timings             searchTimings         `json:"timings"`
+}
+
+// reviewOrder is the "reviews_order" event, the IDs of the reviews passed to the completion in rerank order
+type reviewOrder struct {
+	IDs []string `json:"ids"`
+}
+
+// runSearchStream is the SearchStreamHandler pipeline, shared with the gRPC StreamSearch method. send receives
+// the events in order: "filters" with vertex.QueryFilters, "reviews" with the retrieved rows once the query passed
+// the safety check, "reviews_order" with a reviewOrder once reranked, "item" per recommendation, "message" for
+// unsafe queries, "error" with a *vertex.SearchError, and last "done" with a searchSummary. Events are only sent
+// from the calling goroutine.
+func runSearchStream(ctx context.Context, config *vertex.Config, vsSvc *vertex.VertexSearchService, backends *SearchBackends, stageErrs *searchErrors, input vertex.SearchInput, sessionID string, session *vertex.Session, send func(event string, data any)) {
+	tracer := otel.Tracer("vertex-search")
+
+	// reportErrors sends the stage failures recorded since its last call
+	reported := 0
+	reportErrors := func() {
+		errs := stageErrs.list()
+		for _, searchErr := range errs[reported:] {
+			send("error", searchErr)
+		}
+		reported = len(errs)
+	}
+
+	safety := checkSafety(ctx, vsSvc, stageErrs, input, nil)
+	r := retrieve(ctx, config, vsSvc, backends, stageErrs, input, session)
+	reportErrors()
+	send("filters", r.Filters)
+
+	// Items stream to the client as they are completed, so nothing past retrieval runs before the safety check
+	verdict := <-safety
+	reportErrors()
+
+	var rerankTime, completionTime time.Duration
+	var compResult vertex.CompletionResult
+	var retrieved, items []map[string]any
+	done := func() {
+		finishSearch(ctx, vsSvc, backends, stageErrs, sessionID, input, r, retrieved, items, compResult, verdict, len(stageErrs.list()) > 0)
+		send("done", searchSummary{
+			Model:               compResult.Model,
+			Usage:               compResult.Usage,
+			VectorCount:         len(r.Results),
+			ItemCount:           len(items),
+			SafeQuery:           verdict.Safe,
+			Mode:                input.Mode,
+			Filters:             r.Filters,
+			Reranker:            vsSvc.RerankerName(),
+			RequestID:           stageErrs.requestID,
+			PromptVersion:       vertex.PromptID(vertex.PromptKindCompletion, input.PromptVersion),
+			SafetyPromptVersion: vertex.PromptID(vertex.PromptKindSafety, input.SafetyPromptVersion),
+			SessionID:           sessionID,
+			Errors:              stageErrs.list(),
+			Timings:             newSearchTimings(r.Timings, verdict.Elapsed, rerankTime, completionTime),
+		})
+	}
+
+	if verdict.Err != nil {
+		done()
+		return
+	}
+	if !verdict.Safe {
+		send("message", gin.H{"message": flaggedMessage})
+		done()
+		return
+	}
+
+	rows := r.Rows
+	if rows == nil {
+		rows = []map[string]any{}
+	}
+	// Sent before the rerank finishes, "reviews_order" follows with the final order
+	send("reviews", rows)
+
+	var err error
+	retrieved, rerankTime, err = rerank(ctx, config, vsSvc, r.Input, rows)
+	if err != nil {
+		stageErrs.add(ctx, vertex.StageRerank, err)
+		reportErrors()
+	}
+
+	send("reviews_order", reviewOrder{IDs: retrievedIDs(retrieved)})
+	if len(retrieved) == 0 {
+		done()
+		return
+	}
+
+	scanner := &completionItemScanner{}
+
+	start := time.Now()
+	_, compSpan := tracer.Start(ctx, "llm-completion")
+	compResult, err = vsSvc.StreamCompletion(ctx, r.Input, retrieved, func(chunk string) {
+		for _, item := range scanner.Write(chunk) {
+			presentItems([]map[string]any{item}, retrieved, backends.Photos)
+			items = append(items, item)
+			send("item", item)
+		}
+	})
+	compSpan.End()
+	completionTime = time.Since(start)
+	if err != nil {
+		stageErrs.add(ctx, vertex.StageCompletion, err)
+		reportErrors()
+	}
+	if len(items) > 0 {
+		saveSessionTurn(ctx, stageErrs.requestID, backends.Sessions, sessionID, input.Question, items, retrieved)
+	}
+
+	done()
+}
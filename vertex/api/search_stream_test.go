@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCompletionItemScanner(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		// want are the items as JSON, with the number of chunks written before each was returned
+		want  []string
+		after []int
+	}{
+		{
+			name:   "whole array in one chunk",
+			chunks: []string{`[{"Hotel":"A","Rating":4},{"Hotel":"B","Rating":5}]`},
+			want:   []string{`{"Hotel":"A","Rating":4}`, `{"Hotel":"B","Rating":5}`},
+			after:  []int{1, 1},
+		},
+		{
+			name:   "object split across chunks",
+			chunks: []string{`[{"Hot`, `el":"A"`, `},{"Hotel"`, `:"B"}]`},
+			want:   []string{`{"Hotel":"A"}`, `{"Hotel":"B"}`},
+			after:  []int{3, 4},
+		},
+		{
+			name:   "escaped quotes",
+			chunks: []string{`[{"Review":"the \"quiet\" room }"}]`},
+			want:   []string{`{"Review":"the \"quiet\" room }"}`},
+			after:  []int{1},
+		},
+		{
+			name:   "escape split from the quote it escapes",
+			chunks: []string{`[{"Review":"a \`, `"}\" b"}]`},
+			want:   []string{`{"Review":"a \"}\" b"}`},
+			after:  []int{2},
+		},
+		{
+			name:   "escaped backslash before the closing quote",
+			chunks: []string{`[{"Address":"C:\\"},{"Hotel":"B"}]`},
+			want:   []string{`{"Address":"C:\\"}`, `{"Hotel":"B"}`},
+			after:  []int{1, 1},
+		},
+		{
+			name:   "braces inside strings",
+			chunks: []string{`[{"Review":"{not}} an {object"`, `,"Hotel":"A"}]`},
+			want:   []string{`{"Review":"{not}} an {object","Hotel":"A"}`},
+			after:  []int{2},
+		},
+		{
+			name:   "nested objects",
+			chunks: []string{`[{"Hotel":"A","Scores":{"quiet":`, `5}}]`},
+			want:   []string{`{"Hotel":"A","Scores":{"quiet":5}}`},
+			after:  []int{2},
+		},
+		{
+			name:   "text around the array",
+			chunks: []string{"```json\n[", `{"Hotel":"A"}`, "]\n```"},
+			want:   []string{`{"Hotel":"A"}`},
+			after:  []int{2},
+		},
+		{
+			name:   "malformed item is skipped",
+			chunks: []string{`[{"Hotel":A},{"Hotel":"B"}]`},
+			want:   []string{`{"Hotel":"B"}`},
+			after:  []int{1},
+		},
+		{
+			name:   "unfinished item",
+			chunks: []string{`[{"Hotel":"A"},{"Hotel":"B"`},
+			want:   []string{`{"Hotel":"A"}`},
+			after:  []int{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &completionItemScanner{}
+			var got []map[string]any
+			var after []int
+			for i, chunk := range tt.chunks {
+				for _, item := range scanner.Write(chunk) {
+					got = append(got, item)
+					after = append(after, i+1)
+				}
+			}
+
+			want := make([]map[string]any, len(tt.want))
+			for i, s := range tt.want {
+				if err := json.Unmarshal([]byte(s), &want[i]); err != nil {
+					t.Fatalf("bad test item %s: %v", s, err)
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("items = %v, want %v", got, want)
+			}
+			if !reflect.DeepEqual(after, tt.after) {
+				t.Errorf("items returned after chunks %v, want %v", after, tt.after)
+			}
+		})
+	}
+}
@@ -0,0 +1,2225 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: search.proto
+
+package searchpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SearchRequest holds the search form fields. Empty fields fall back to config like they do over HTTP.
+type SearchRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Question  string                 `protobuf:"bytes,1,opt,name=question,proto3" json:"question,omitempty"`
+	Continent string                 `protobuf:"bytes,2,opt,name=continent,proto3" json:"continent,omitempty"`
+	// city_country is "City, Country"
+	CityCountry string `protobuf:"bytes,3,opt,name=city_country,json=cityCountry,proto3" json:"city_country,omitempty"`
+	MinRating   int32  `protobuf:"varint,4,opt,name=min_rating,json=minRating,proto3" json:"min_rating,omitempty"`
+	// llm is the preferred provider: gemini, grok, openai, claude, bedrock, a configured openai_compatible name or auto
+	Llm string `protobuf:"bytes,5,opt,name=llm,proto3" json:"llm,omitempty"`
+	// mode is vector, keyword or hybrid
+	Mode string `protobuf:"bytes,6,opt,name=mode,proto3" json:"mode,omitempty"`
+	// near is "lat,lng", restricting results to radius_km around it
+	Near          string  `protobuf:"bytes,7,opt,name=near,proto3" json:"near,omitempty"`
+	RadiusKm      float64 `protobuf:"fixed64,8,opt,name=radius_km,json=radiusKm,proto3" json:"radius_km,omitempty"`
+	SessionId     string  `protobuf:"bytes,9,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	PromptVersion string  `protobuf:"bytes,10,opt,name=prompt_version,json=promptVersion,proto3" json:"prompt_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_search_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchRequest) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetContinent() string {
+	if x != nil {
+		return x.Continent
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetCityCountry() string {
+	if x != nil {
+		return x.CityCountry
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetMinRating() int32 {
+	if x != nil {
+		return x.MinRating
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetLlm() string {
+	if x != nil {
+		return x.Llm
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetNear() string {
+	if x != nil {
+		return x.Near
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetRadiusKm() float64 {
+	if x != nil {
+		return x.RadiusKm
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetPromptVersion() string {
+	if x != nil {
+		return x.PromptVersion
+	}
+	return ""
+}
+
+type TokenUsage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PromptTokens     int32                  `protobuf:"varint,1,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`
+	CompletionTokens int32                  `protobuf:"varint,2,opt,name=completion_tokens,json=completionTokens,proto3" json:"completion_tokens,omitempty"`
+	TotalTokens      int32                  `protobuf:"varint,3,opt,name=total_tokens,json=totalTokens,proto3" json:"total_tokens,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *TokenUsage) Reset() {
+	*x = TokenUsage{}
+	mi := &file_search_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenUsage) ProtoMessage() {}
+
+func (x *TokenUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenUsage.ProtoReflect.Descriptor instead.
+func (*TokenUsage) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TokenUsage) GetPromptTokens() int32 {
+	if x != nil {
+		return x.PromptTokens
+	}
+	return 0
+}
+
+func (x *TokenUsage) GetCompletionTokens() int32 {
+	if x != nil {
+		return x.CompletionTokens
+	}
+	return 0
+}
+
+func (x *TokenUsage) GetTotalTokens() int32 {
+	if x != nil {
+		return x.TotalTokens
+	}
+	return 0
+}
+
+// QueryFilters are the filters inferred from the question
+type QueryFilters struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	City          string                 `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	Country       string                 `protobuf:"bytes,2,opt,name=country,proto3" json:"country,omitempty"`
+	Continent     string                 `protobuf:"bytes,3,opt,name=continent,proto3" json:"continent,omitempty"`
+	MinRating     int32                  `protobuf:"varint,4,opt,name=min_rating,json=minRating,proto3" json:"min_rating,omitempty"`
+	TravelType    string                 `protobuf:"bytes,5,opt,name=travel_type,json=travelType,proto3" json:"travel_type,omitempty"`
+	Aspects       []string               `protobuf:"bytes,6,rep,name=aspects,proto3" json:"aspects,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryFilters) Reset() {
+	*x = QueryFilters{}
+	mi := &file_search_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryFilters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryFilters) ProtoMessage() {}
+
+func (x *QueryFilters) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryFilters.ProtoReflect.Descriptor instead.
+func (*QueryFilters) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *QueryFilters) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *QueryFilters) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *QueryFilters) GetContinent() string {
+	if x != nil {
+		return x.Continent
+	}
+	return ""
+}
+
+func (x *QueryFilters) GetMinRating() int32 {
+	if x != nil {
+		return x.MinRating
+	}
+	return 0
+}
+
+func (x *QueryFilters) GetTravelType() string {
+	if x != nil {
+		return x.TravelType
+	}
+	return ""
+}
+
+func (x *QueryFilters) GetAspects() []string {
+	if x != nil {
+		return x.Aspects
+	}
+	return nil
+}
+
+// SearchError is a failed pipeline stage, also attached as a detail of the status of a failed Search
+type SearchError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Stage         string                 `protobuf:"bytes,2,opt,name=stage,proto3" json:"stage,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Retryable     bool                   `protobuf:"varint,4,opt,name=retryable,proto3" json:"retryable,omitempty"`
+	RequestId     string                 `protobuf:"bytes,5,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchError) Reset() {
+	*x = SearchError{}
+	mi := &file_search_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchError) ProtoMessage() {}
+
+func (x *SearchError) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchError.ProtoReflect.Descriptor instead.
+func (*SearchError) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SearchError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *SearchError) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *SearchError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SearchError) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
+func (x *SearchError) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type Timings struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	QueryUnderstandingMs int64                  `protobuf:"varint,1,opt,name=query_understanding_ms,json=queryUnderstandingMs,proto3" json:"query_understanding_ms,omitempty"`
+	GeoPrefilterMs       int64                  `protobuf:"varint,2,opt,name=geo_prefilter_ms,json=geoPrefilterMs,proto3" json:"geo_prefilter_ms,omitempty"`
+	EmbeddingMs          int64                  `protobuf:"varint,3,opt,name=embedding_ms,json=embeddingMs,proto3" json:"embedding_ms,omitempty"`
+	VectorSearchMs       int64                  `protobuf:"varint,4,opt,name=vector_search_ms,json=vectorSearchMs,proto3" json:"vector_search_ms,omitempty"`
+	KeywordSearchMs      int64                  `protobuf:"varint,5,opt,name=keyword_search_ms,json=keywordSearchMs,proto3" json:"keyword_search_ms,omitempty"`
+	SafetyMs             int64                  `protobuf:"varint,6,opt,name=safety_ms,json=safetyMs,proto3" json:"safety_ms,omitempty"`
+	MetadataMs           int64                  `protobuf:"varint,7,opt,name=metadata_ms,json=metadataMs,proto3" json:"metadata_ms,omitempty"`
+	RerankMs             int64                  `protobuf:"varint,8,opt,name=rerank_ms,json=rerankMs,proto3" json:"rerank_ms,omitempty"`
+	LlmCompletionMs      int64                  `protobuf:"varint,9,opt,name=llm_completion_ms,json=llmCompletionMs,proto3" json:"llm_completion_ms,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Timings) Reset() {
+	*x = Timings{}
+	mi := &file_search_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Timings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Timings) ProtoMessage() {}
+
+func (x *Timings) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Timings.ProtoReflect.Descriptor instead.
+func (*Timings) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Timings) GetQueryUnderstandingMs() int64 {
+	if x != nil {
+		return x.QueryUnderstandingMs
+	}
+	return 0
+}
+
+func (x *Timings) GetGeoPrefilterMs() int64 {
+	if x != nil {
+		return x.GeoPrefilterMs
+	}
+	return 0
+}
+
+func (x *Timings) GetEmbeddingMs() int64 {
+	if x != nil {
+		return x.EmbeddingMs
+	}
+	return 0
+}
+
+func (x *Timings) GetVectorSearchMs() int64 {
+	if x != nil {
+		return x.VectorSearchMs
+	}
+	return 0
+}
+
+func (x *Timings) GetKeywordSearchMs() int64 {
+	if x != nil {
+		return x.KeywordSearchMs
+	}
+	return 0
+}
+
+func (x *Timings) GetSafetyMs() int64 {
+	if x != nil {
+		return x.SafetyMs
+	}
+	return 0
+}
+
+func (x *Timings) GetMetadataMs() int64 {
+	if x != nil {
+		return x.MetadataMs
+	}
+	return 0
+}
+
+func (x *Timings) GetRerankMs() int64 {
+	if x != nil {
+		return x.RerankMs
+	}
+	return 0
+}
+
+func (x *Timings) GetLlmCompletionMs() int64 {
+	if x != nil {
+		return x.LlmCompletionMs
+	}
+	return 0
+}
+
+// Recommendation is one hotel recommended by the completion
+type Recommendation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hotel         string                 `protobuf:"bytes,1,opt,name=hotel,proto3" json:"hotel,omitempty"`
+	City          string                 `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+	Review        string                 `protobuf:"bytes,3,opt,name=review,proto3" json:"review,omitempty"`
+	Rating        float64                `protobuf:"fixed64,4,opt,name=rating,proto3" json:"rating,omitempty"`
+	Distance      float64                `protobuf:"fixed64,5,opt,name=distance,proto3" json:"distance,omitempty"`
+	Address       string                 `protobuf:"bytes,6,opt,name=address,proto3" json:"address,omitempty"`
+	GoogleMapsUri string                 `protobuf:"bytes,7,opt,name=google_maps_uri,json=googleMapsUri,proto3" json:"google_maps_uri,omitempty"`
+	PhotoName     string                 `protobuf:"bytes,8,opt,name=photo_name,json=photoName,proto3" json:"photo_name,omitempty"`
+	HotelId       string                 `protobuf:"bytes,9,opt,name=hotel_id,json=hotelId,proto3" json:"hotel_id,omitempty"`
+	// distance_km is set for searches near a point
+	DistanceKm float64 `protobuf:"fixed64,10,opt,name=distance_km,json=distanceKm,proto3" json:"distance_km,omitempty"`
+	MapUrl     string  `protobuf:"bytes,11,opt,name=map_url,json=mapUrl,proto3" json:"map_url,omitempty"`
+	PhotoThumb string  `protobuf:"bytes,12,opt,name=photo_thumb,json=photoThumb,proto3" json:"photo_thumb,omitempty"`
+	PhotoFull  string  `protobuf:"bytes,13,opt,name=photo_full,json=photoFull,proto3" json:"photo_full,omitempty"`
+	// important_note is set by an admin for the hotel
+	ImportantNote string `protobuf:"bytes,14,opt,name=important_note,json=importantNote,proto3" json:"important_note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Recommendation) Reset() {
+	*x = Recommendation{}
+	mi := &file_search_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Recommendation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recommendation) ProtoMessage() {}
+
+func (x *Recommendation) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recommendation.ProtoReflect.Descriptor instead.
+func (*Recommendation) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Recommendation) GetHotel() string {
+	if x != nil {
+		return x.Hotel
+	}
+	return ""
+}
+
+func (x *Recommendation) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Recommendation) GetReview() string {
+	if x != nil {
+		return x.Review
+	}
+	return ""
+}
+
+func (x *Recommendation) GetRating() float64 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *Recommendation) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *Recommendation) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Recommendation) GetGoogleMapsUri() string {
+	if x != nil {
+		return x.GoogleMapsUri
+	}
+	return ""
+}
+
+func (x *Recommendation) GetPhotoName() string {
+	if x != nil {
+		return x.PhotoName
+	}
+	return ""
+}
+
+func (x *Recommendation) GetHotelId() string {
+	if x != nil {
+		return x.HotelId
+	}
+	return ""
+}
+
+func (x *Recommendation) GetDistanceKm() float64 {
+	if x != nil {
+		return x.DistanceKm
+	}
+	return 0
+}
+
+func (x *Recommendation) GetMapUrl() string {
+	if x != nil {
+		return x.MapUrl
+	}
+	return ""
+}
+
+func (x *Recommendation) GetPhotoThumb() string {
+	if x != nil {
+		return x.PhotoThumb
+	}
+	return ""
+}
+
+func (x *Recommendation) GetPhotoFull() string {
+	if x != nil {
+		return x.PhotoFull
+	}
+	return ""
+}
+
+func (x *Recommendation) GetImportantNote() string {
+	if x != nil {
+		return x.ImportantNote
+	}
+	return ""
+}
+
+type SearchResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Completion []*Recommendation      `protobuf:"bytes,1,rep,name=completion,proto3" json:"completion,omitempty"`
+	// message explains why there are no recommendations, such as a query flagged by the safety check
+	Message             string         `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Model               string         `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
+	Usage               *TokenUsage    `protobuf:"bytes,4,opt,name=usage,proto3" json:"usage,omitempty"`
+	VectorCount         int32          `protobuf:"varint,5,opt,name=vector_count,json=vectorCount,proto3" json:"vector_count,omitempty"`
+	SafeQuery           bool           `protobuf:"varint,6,opt,name=safe_query,json=safeQuery,proto3" json:"safe_query,omitempty"`
+	Mode                string         `protobuf:"bytes,7,opt,name=mode,proto3" json:"mode,omitempty"`
+	Filters             *QueryFilters  `protobuf:"bytes,8,opt,name=filters,proto3" json:"filters,omitempty"`
+	Reranker            string         `protobuf:"bytes,9,opt,name=reranker,proto3" json:"reranker,omitempty"`
+	RequestId           string         `protobuf:"bytes,10,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	PromptVersion       string         `protobuf:"bytes,11,opt,name=prompt_version,json=promptVersion,proto3" json:"prompt_version,omitempty"`
+	SafetyPromptVersion string         `protobuf:"bytes,12,opt,name=safety_prompt_version,json=safetyPromptVersion,proto3" json:"safety_prompt_version,omitempty"`
+	SessionId           string         `protobuf:"bytes,13,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Errors              []*SearchError `protobuf:"bytes,14,rep,name=errors,proto3" json:"errors,omitempty"`
+	// partial is set when a stage failed but results remain
+	Partial bool     `protobuf:"varint,15,opt,name=partial,proto3" json:"partial,omitempty"`
+	Timings *Timings `protobuf:"bytes,16,opt,name=timings,proto3" json:"timings,omitempty"`
+	// retrieved holds the retrieved reviews when the completion failed, with the metadata columns of the backend
+	Retrieved     []*structpb.Struct `protobuf:"bytes,17,rep,name=retrieved,proto3" json:"retrieved,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_search_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SearchResponse) GetCompletion() []*Recommendation {
+	if x != nil {
+		return x.Completion
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetUsage() *TokenUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetVectorCount() int32 {
+	if x != nil {
+		return x.VectorCount
+	}
+	return 0
+}
+
+func (x *SearchResponse) GetSafeQuery() bool {
+	if x != nil {
+		return x.SafeQuery
+	}
+	return false
+}
+
+func (x *SearchResponse) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetFilters() *QueryFilters {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetReranker() string {
+	if x != nil {
+		return x.Reranker
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetPromptVersion() string {
+	if x != nil {
+		return x.PromptVersion
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetSafetyPromptVersion() string {
+	if x != nil {
+		return x.SafetyPromptVersion
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetErrors() []*SearchError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *SearchResponse) GetTimings() *Timings {
+	if x != nil {
+		return x.Timings
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetRetrieved() []*structpb.Struct {
+	if x != nil {
+		return x.Retrieved
+	}
+	return nil
+}
+
+// SearchDone ends a stream, as the "done" event
+type SearchDone struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Model               string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`
+	Usage               *TokenUsage            `protobuf:"bytes,2,opt,name=usage,proto3" json:"usage,omitempty"`
+	VectorCount         int32                  `protobuf:"varint,3,opt,name=vector_count,json=vectorCount,proto3" json:"vector_count,omitempty"`
+	ItemCount           int32                  `protobuf:"varint,4,opt,name=item_count,json=itemCount,proto3" json:"item_count,omitempty"`
+	SafeQuery           bool                   `protobuf:"varint,5,opt,name=safe_query,json=safeQuery,proto3" json:"safe_query,omitempty"`
+	Mode                string                 `protobuf:"bytes,6,opt,name=mode,proto3" json:"mode,omitempty"`
+	Filters             *QueryFilters          `protobuf:"bytes,7,opt,name=filters,proto3" json:"filters,omitempty"`
+	Reranker            string                 `protobuf:"bytes,8,opt,name=reranker,proto3" json:"reranker,omitempty"`
+	RequestId           string                 `protobuf:"bytes,9,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	PromptVersion       string                 `protobuf:"bytes,10,opt,name=prompt_version,json=promptVersion,proto3" json:"prompt_version,omitempty"`
+	SafetyPromptVersion string                 `protobuf:"bytes,11,opt,name=safety_prompt_version,json=safetyPromptVersion,proto3" json:"safety_prompt_version,omitempty"`
+	SessionId           string                 `protobuf:"bytes,12,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Errors              []*SearchError         `protobuf:"bytes,13,rep,name=errors,proto3" json:"errors,omitempty"`
+	Timings             *Timings               `protobuf:"bytes,14,opt,name=timings,proto3" json:"timings,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SearchDone) Reset() {
+	*x = SearchDone{}
+	mi := &file_search_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchDone) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchDone) ProtoMessage() {}
+
+func (x *SearchDone) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchDone.ProtoReflect.Descriptor instead.
+func (*SearchDone) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SearchDone) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *SearchDone) GetUsage() *TokenUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *SearchDone) GetVectorCount() int32 {
+	if x != nil {
+		return x.VectorCount
+	}
+	return 0
+}
+
+func (x *SearchDone) GetItemCount() int32 {
+	if x != nil {
+		return x.ItemCount
+	}
+	return 0
+}
+
+func (x *SearchDone) GetSafeQuery() bool {
+	if x != nil {
+		return x.SafeQuery
+	}
+	return false
+}
+
+func (x *SearchDone) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *SearchDone) GetFilters() *QueryFilters {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *SearchDone) GetReranker() string {
+	if x != nil {
+		return x.Reranker
+	}
+	return ""
+}
+
+func (x *SearchDone) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SearchDone) GetPromptVersion() string {
+	if x != nil {
+		return x.PromptVersion
+	}
+	return ""
+}
+
+func (x *SearchDone) GetSafetyPromptVersion() string {
+	if x != nil {
+		return x.SafetyPromptVersion
+	}
+	return ""
+}
+
+func (x *SearchDone) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SearchDone) GetErrors() []*SearchError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *SearchDone) GetTimings() *Timings {
+	if x != nil {
+		return x.Timings
+	}
+	return nil
+}
+
+type RetrievedReviews struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reviews       []*structpb.Struct     `protobuf:"bytes,1,rep,name=reviews,proto3" json:"reviews,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetrievedReviews) Reset() {
+	*x = RetrievedReviews{}
+	mi := &file_search_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetrievedReviews) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetrievedReviews) ProtoMessage() {}
+
+func (x *RetrievedReviews) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetrievedReviews.ProtoReflect.Descriptor instead.
+func (*RetrievedReviews) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RetrievedReviews) GetReviews() []*structpb.Struct {
+	if x != nil {
+		return x.Reviews
+	}
+	return nil
+}
+
+// ReviewOrder is the "reviews_order" event, the IDs of the retrieved reviews in rerank order
+type ReviewOrder struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewOrder) Reset() {
+	*x = ReviewOrder{}
+	mi := &file_search_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewOrder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewOrder) ProtoMessage() {}
+
+func (x *ReviewOrder) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewOrder.ProtoReflect.Descriptor instead.
+func (*ReviewOrder) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ReviewOrder) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type SearchEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*SearchEvent_Filters
+	//	*SearchEvent_Reviews
+	//	*SearchEvent_Item
+	//	*SearchEvent_Message
+	//	*SearchEvent_Error
+	//	*SearchEvent_Done
+	//	*SearchEvent_ReviewsOrder
+	Event         isSearchEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchEvent) Reset() {
+	*x = SearchEvent{}
+	mi := &file_search_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchEvent) ProtoMessage() {}
+
+func (x *SearchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchEvent.ProtoReflect.Descriptor instead.
+func (*SearchEvent) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SearchEvent) GetEvent() isSearchEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *SearchEvent) GetFilters() *QueryFilters {
+	if x != nil {
+		if x, ok := x.Event.(*SearchEvent_Filters); ok {
+			return x.Filters
+		}
+	}
+	return nil
+}
+
+func (x *SearchEvent) GetReviews() *RetrievedReviews {
+	if x != nil {
+		if x, ok := x.Event.(*SearchEvent_Reviews); ok {
+			return x.Reviews
+		}
+	}
+	return nil
+}
+
+func (x *SearchEvent) GetItem() *Recommendation {
+	if x != nil {
+		if x, ok := x.Event.(*SearchEvent_Item); ok {
+			return x.Item
+		}
+	}
+	return nil
+}
+
+func (x *SearchEvent) GetMessage() string {
+	if x != nil {
+		if x, ok := x.Event.(*SearchEvent_Message); ok {
+			return x.Message
+		}
+	}
+	return ""
+}
+
+func (x *SearchEvent) GetError() *SearchError {
+	if x != nil {
+		if x, ok := x.Event.(*SearchEvent_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+func (x *SearchEvent) GetDone() *SearchDone {
+	if x != nil {
+		if x, ok := x.Event.(*SearchEvent_Done); ok {
+			return x.Done
+		}
+	}
+	return nil
+}
+
+func (x *SearchEvent) GetReviewsOrder() *ReviewOrder {
+	if x != nil {
+		if x, ok := x.Event.(*SearchEvent_ReviewsOrder); ok {
+			return x.ReviewsOrder
+		}
+	}
+	return nil
+}
+
+type isSearchEvent_Event interface {
+	isSearchEvent_Event()
+}
+
+type SearchEvent_Filters struct {
+	Filters *QueryFilters `protobuf:"bytes,1,opt,name=filters,proto3,oneof"`
+}
+
+type SearchEvent_Reviews struct {
+	Reviews *RetrievedReviews `protobuf:"bytes,2,opt,name=reviews,proto3,oneof"`
+}
+
+type SearchEvent_Item struct {
+	Item *Recommendation `protobuf:"bytes,3,opt,name=item,proto3,oneof"`
+}
+
+type SearchEvent_Message struct {
+	Message string `protobuf:"bytes,4,opt,name=message,proto3,oneof"`
+}
+
+type SearchEvent_Error struct {
+	Error *SearchError `protobuf:"bytes,5,opt,name=error,proto3,oneof"`
+}
+
+type SearchEvent_Done struct {
+	Done *SearchDone `protobuf:"bytes,6,opt,name=done,proto3,oneof"`
+}
+
+type SearchEvent_ReviewsOrder struct {
+	ReviewsOrder *ReviewOrder `protobuf:"bytes,7,opt,name=reviews_order,json=reviewsOrder,proto3,oneof"`
+}
+
+func (*SearchEvent_Filters) isSearchEvent_Event() {}
+
+func (*SearchEvent_Reviews) isSearchEvent_Event() {}
+
+func (*SearchEvent_Item) isSearchEvent_Event() {}
+
+func (*SearchEvent_Message) isSearchEvent_Event() {}
+
+func (*SearchEvent_Error) isSearchEvent_Event() {}
+
+func (*SearchEvent_Done) isSearchEvent_Event() {}
+
+func (*SearchEvent_ReviewsOrder) isSearchEvent_Event() {}
+
+type GetLocationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLocationsRequest) Reset() {
+	*x = GetLocationsRequest{}
+	mi := &file_search_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLocationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLocationsRequest) ProtoMessage() {}
+
+func (x *GetLocationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLocationsRequest.ProtoReflect.Descriptor instead.
+func (*GetLocationsRequest) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{11}
+}
+
+type LocationGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Continent     string                 `protobuf:"bytes,1,opt,name=continent,proto3" json:"continent,omitempty"`
+	CityCountries []string               `protobuf:"bytes,2,rep,name=city_countries,json=cityCountries,proto3" json:"city_countries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LocationGroup) Reset() {
+	*x = LocationGroup{}
+	mi := &file_search_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocationGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocationGroup) ProtoMessage() {}
+
+func (x *LocationGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocationGroup.ProtoReflect.Descriptor instead.
+func (*LocationGroup) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *LocationGroup) GetContinent() string {
+	if x != nil {
+		return x.Continent
+	}
+	return ""
+}
+
+func (x *LocationGroup) GetCityCountries() []string {
+	if x != nil {
+		return x.CityCountries
+	}
+	return nil
+}
+
+type GetLocationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Locations     []*LocationGroup       `protobuf:"bytes,1,rep,name=locations,proto3" json:"locations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLocationsResponse) Reset() {
+	*x = GetLocationsResponse{}
+	mi := &file_search_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLocationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLocationsResponse) ProtoMessage() {}
+
+func (x *GetLocationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLocationsResponse.ProtoReflect.Descriptor instead.
+func (*GetLocationsResponse) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetLocationsResponse) GetLocations() []*LocationGroup {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+// GetHotelRequest pages through the reviews of a hotel like the query parameters of /api/hotels/:id
+type GetHotelRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	HotelId string                 `protobuf:"bytes,1,opt,name=hotel_id,json=hotelId,proto3" json:"hotel_id,omitempty"`
+	// page is 1-based
+	Page int32 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	// page_size defaults to 20, at most 100
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// sort is date, rating or helpful
+	Sort          string `protobuf:"bytes,4,opt,name=sort,proto3" json:"sort,omitempty"`
+	Ascending     bool   `protobuf:"varint,5,opt,name=ascending,proto3" json:"ascending,omitempty"`
+	Source        string `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHotelRequest) Reset() {
+	*x = GetHotelRequest{}
+	mi := &file_search_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHotelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHotelRequest) ProtoMessage() {}
+
+func (x *GetHotelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHotelRequest.ProtoReflect.Descriptor instead.
+func (*GetHotelRequest) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetHotelRequest) GetHotelId() string {
+	if x != nil {
+		return x.HotelId
+	}
+	return ""
+}
+
+func (x *GetHotelRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetHotelRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetHotelRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *GetHotelRequest) GetAscending() bool {
+	if x != nil {
+		return x.Ascending
+	}
+	return false
+}
+
+func (x *GetHotelRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type Hotel struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	HotelId           string                 `protobuf:"bytes,1,opt,name=hotel_id,json=hotelId,proto3" json:"hotel_id,omitempty"`
+	Source            string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Name              string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	City              string                 `protobuf:"bytes,4,opt,name=city,proto3" json:"city,omitempty"`
+	Country           string                 `protobuf:"bytes,5,opt,name=country,proto3" json:"country,omitempty"`
+	Latitude          float64                `protobuf:"fixed64,6,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude         float64                `protobuf:"fixed64,7,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	StreetAddress     string                 `protobuf:"bytes,8,opt,name=street_address,json=streetAddress,proto3" json:"street_address,omitempty"`
+	PostalCode        string                 `protobuf:"bytes,9,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	Phone             string                 `protobuf:"bytes,10,opt,name=phone,proto3" json:"phone,omitempty"`
+	Website           string                 `protobuf:"bytes,11,opt,name=website,proto3" json:"website,omitempty"`
+	AmadeusRating     float64                `protobuf:"fixed64,12,opt,name=amadeus_rating,json=amadeusRating,proto3" json:"amadeus_rating,omitempty"`
+	GoogleRating      float64                `protobuf:"fixed64,13,opt,name=google_rating,json=googleRating,proto3" json:"google_rating,omitempty"`
+	TripadvisorRating float64                `protobuf:"fixed64,14,opt,name=tripadvisor_rating,json=tripadvisorRating,proto3" json:"tripadvisor_rating,omitempty"`
+	BookingRating     float64                `protobuf:"fixed64,15,opt,name=booking_rating,json=bookingRating,proto3" json:"booking_rating,omitempty"`
+	OverallRating     float64                `protobuf:"fixed64,16,opt,name=overall_rating,json=overallRating,proto3" json:"overall_rating,omitempty"`
+	NumberOfReviews   int32                  `protobuf:"varint,17,opt,name=number_of_reviews,json=numberOfReviews,proto3" json:"number_of_reviews,omitempty"`
+	Recommended       bool                   `protobuf:"varint,18,opt,name=recommended,proto3" json:"recommended,omitempty"`
+	Quality           bool                   `protobuf:"varint,19,opt,name=quality,proto3" json:"quality,omitempty"`
+	Quiet             bool                   `protobuf:"varint,20,opt,name=quiet,proto3" json:"quiet,omitempty"`
+	ImportantNote     string                 `protobuf:"bytes,21,opt,name=important_note,json=importantNote,proto3" json:"important_note,omitempty"`
+	PhotoName         string                 `protobuf:"bytes,22,opt,name=photo_name,json=photoName,proto3" json:"photo_name,omitempty"`
+	PhotoThumb        string                 `protobuf:"bytes,23,opt,name=photo_thumb,json=photoThumb,proto3" json:"photo_thumb,omitempty"`
+	PhotoFull         string                 `protobuf:"bytes,24,opt,name=photo_full,json=photoFull,proto3" json:"photo_full,omitempty"`
+	// amadeus_sentiments holds the raw Amadeus sentiments
+	AmadeusSentiments *structpb.Struct `protobuf:"bytes,25,opt,name=amadeus_sentiments,json=amadeusSentiments,proto3" json:"amadeus_sentiments,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Hotel) Reset() {
+	*x = Hotel{}
+	mi := &file_search_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hotel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hotel) ProtoMessage() {}
+
+func (x *Hotel) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hotel.ProtoReflect.Descriptor instead.
+func (*Hotel) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Hotel) GetHotelId() string {
+	if x != nil {
+		return x.HotelId
+	}
+	return ""
+}
+
+func (x *Hotel) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Hotel) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Hotel) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Hotel) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Hotel) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Hotel) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Hotel) GetStreetAddress() string {
+	if x != nil {
+		return x.StreetAddress
+	}
+	return ""
+}
+
+func (x *Hotel) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *Hotel) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *Hotel) GetWebsite() string {
+	if x != nil {
+		return x.Website
+	}
+	return ""
+}
+
+func (x *Hotel) GetAmadeusRating() float64 {
+	if x != nil {
+		return x.AmadeusRating
+	}
+	return 0
+}
+
+func (x *Hotel) GetGoogleRating() float64 {
+	if x != nil {
+		return x.GoogleRating
+	}
+	return 0
+}
+
+func (x *Hotel) GetTripadvisorRating() float64 {
+	if x != nil {
+		return x.TripadvisorRating
+	}
+	return 0
+}
+
+func (x *Hotel) GetBookingRating() float64 {
+	if x != nil {
+		return x.BookingRating
+	}
+	return 0
+}
+
+func (x *Hotel) GetOverallRating() float64 {
+	if x != nil {
+		return x.OverallRating
+	}
+	return 0
+}
+
+func (x *Hotel) GetNumberOfReviews() int32 {
+	if x != nil {
+		return x.NumberOfReviews
+	}
+	return 0
+}
+
+func (x *Hotel) GetRecommended() bool {
+	if x != nil {
+		return x.Recommended
+	}
+	return false
+}
+
+func (x *Hotel) GetQuality() bool {
+	if x != nil {
+		return x.Quality
+	}
+	return false
+}
+
+func (x *Hotel) GetQuiet() bool {
+	if x != nil {
+		return x.Quiet
+	}
+	return false
+}
+
+func (x *Hotel) GetImportantNote() string {
+	if x != nil {
+		return x.ImportantNote
+	}
+	return ""
+}
+
+func (x *Hotel) GetPhotoName() string {
+	if x != nil {
+		return x.PhotoName
+	}
+	return ""
+}
+
+func (x *Hotel) GetPhotoThumb() string {
+	if x != nil {
+		return x.PhotoThumb
+	}
+	return ""
+}
+
+func (x *Hotel) GetPhotoFull() string {
+	if x != nil {
+		return x.PhotoFull
+	}
+	return ""
+}
+
+func (x *Hotel) GetAmadeusSentiments() *structpb.Struct {
+	if x != nil {
+		return x.AmadeusSentiments
+	}
+	return nil
+}
+
+type Review struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Source           string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	ReviewerName     string                 `protobuf:"bytes,3,opt,name=reviewer_name,json=reviewerName,proto3" json:"reviewer_name,omitempty"`
+	ReviewerLocation string                 `protobuf:"bytes,4,opt,name=reviewer_location,json=reviewerLocation,proto3" json:"reviewer_location,omitempty"`
+	Rating           float64                `protobuf:"fixed64,5,opt,name=rating,proto3" json:"rating,omitempty"`
+	ReviewText       string                 `protobuf:"bytes,6,opt,name=review_text,json=reviewText,proto3" json:"review_text,omitempty"`
+	ReviewDate       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=review_date,json=reviewDate,proto3" json:"review_date,omitempty"`
+	Verified         bool                   `protobuf:"varint,8,opt,name=verified,proto3" json:"verified,omitempty"`
+	HelpfulCount     int32                  `protobuf:"varint,9,opt,name=helpful_count,json=helpfulCount,proto3" json:"helpful_count,omitempty"`
+	RoomType         string                 `protobuf:"bytes,10,opt,name=room_type,json=roomType,proto3" json:"room_type,omitempty"`
+	TravelType       string                 `protobuf:"bytes,11,opt,name=travel_type,json=travelType,proto3" json:"travel_type,omitempty"`
+	GoogleMapsUri    string                 `protobuf:"bytes,12,opt,name=google_maps_uri,json=googleMapsUri,proto3" json:"google_maps_uri,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Review) Reset() {
+	*x = Review{}
+	mi := &file_search_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Review) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Review) ProtoMessage() {}
+
+func (x *Review) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Review.ProtoReflect.Descriptor instead.
+func (*Review) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Review) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Review) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Review) GetReviewerName() string {
+	if x != nil {
+		return x.ReviewerName
+	}
+	return ""
+}
+
+func (x *Review) GetReviewerLocation() string {
+	if x != nil {
+		return x.ReviewerLocation
+	}
+	return ""
+}
+
+func (x *Review) GetRating() float64 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *Review) GetReviewText() string {
+	if x != nil {
+		return x.ReviewText
+	}
+	return ""
+}
+
+func (x *Review) GetReviewDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReviewDate
+	}
+	return nil
+}
+
+func (x *Review) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *Review) GetHelpfulCount() int32 {
+	if x != nil {
+		return x.HelpfulCount
+	}
+	return 0
+}
+
+func (x *Review) GetRoomType() string {
+	if x != nil {
+		return x.RoomType
+	}
+	return ""
+}
+
+func (x *Review) GetTravelType() string {
+	if x != nil {
+		return x.TravelType
+	}
+	return ""
+}
+
+func (x *Review) GetGoogleMapsUri() string {
+	if x != nil {
+		return x.GoogleMapsUri
+	}
+	return ""
+}
+
+type Pagination struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Total         int64                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pagination) Reset() {
+	*x = Pagination{}
+	mi := &file_search_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pagination) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pagination) ProtoMessage() {}
+
+func (x *Pagination) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pagination.ProtoReflect.Descriptor instead.
+func (*Pagination) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Pagination) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *Pagination) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *Pagination) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type GetHotelResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hotel         *Hotel                 `protobuf:"bytes,1,opt,name=hotel,proto3" json:"hotel,omitempty"`
+	Reviews       []*Review              `protobuf:"bytes,2,rep,name=reviews,proto3" json:"reviews,omitempty"`
+	Pagination    *Pagination            `protobuf:"bytes,3,opt,name=pagination,proto3" json:"pagination,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHotelResponse) Reset() {
+	*x = GetHotelResponse{}
+	mi := &file_search_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHotelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHotelResponse) ProtoMessage() {}
+
+func (x *GetHotelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_search_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHotelResponse.ProtoReflect.Descriptor instead.
+func (*GetHotelResponse) Descriptor() ([]byte, []int) {
+	return file_search_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetHotelResponse) GetHotel() *Hotel {
+	if x != nil {
+		return x.Hotel
+	}
+	return nil
+}
+
+func (x *GetHotelResponse) GetReviews() []*Review {
+	if x != nil {
+		return x.Reviews
+	}
+	return nil
+}
+
+func (x *GetHotelResponse) GetPagination() *Pagination {
+	if x != nil {
+		return x.Pagination
+	}
+	return nil
+}
+
+var File_search_proto protoreflect.FileDescriptor
+
+const file_search_proto_rawDesc = "" +
+	"\n" +
+	"\fsearch.proto\x12\x10alpaca.search.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa8\x02\n" +
+	"\rSearchRequest\x12\x1a\n" +
+	"\bquestion\x18\x01 \x01(\tR\bquestion\x12\x1c\n" +
+	"\tcontinent\x18\x02 \x01(\tR\tcontinent\x12!\n" +
+	"\fcity_country\x18\x03 \x01(\tR\vcityCountry\x12\x1d\n" +
+	"\n" +
+	"min_rating\x18\x04 \x01(\x05R\tminRating\x12\x10\n" +
+	"\x03llm\x18\x05 \x01(\tR\x03llm\x12\x12\n" +
+	"\x04mode\x18\x06 \x01(\tR\x04mode\x12\x12\n" +
+	"\x04near\x18\a \x01(\tR\x04near\x12\x1b\n" +
+	"\tradius_km\x18\b \x01(\x01R\bradiusKm\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\t \x01(\tR\tsessionId\x12%\n" +
+	"\x0eprompt_version\x18\n" +
+	" \x01(\tR\rpromptVersion\"\x81\x01\n" +
+	"\n" +
+	"TokenUsage\x12#\n" +
+	"\rprompt_tokens\x18\x01 \x01(\x05R\fpromptTokens\x12+\n" +
+	"\x11completion_tokens\x18\x02 \x01(\x05R\x10completionTokens\x12!\n" +
+	"\ftotal_tokens\x18\x03 \x01(\x05R\vtotalTokens\"\xb4\x01\n" +
+	"\fQueryFilters\x12\x12\n" +
+	"\x04city\x18\x01 \x01(\tR\x04city\x12\x18\n" +
+	"\acountry\x18\x02 \x01(\tR\acountry\x12\x1c\n" +
+	"\tcontinent\x18\x03 \x01(\tR\tcontinent\x12\x1d\n" +
+	"\n" +
+	"min_rating\x18\x04 \x01(\x05R\tminRating\x12\x1f\n" +
+	"\vtravel_type\x18\x05 \x01(\tR\n" +
+	"travelType\x12\x18\n" +
+	"\aaspects\x18\x06 \x03(\tR\aaspects\"\x8e\x01\n" +
+	"\vSearchError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x14\n" +
+	"\x05stage\x18\x02 \x01(\tR\x05stage\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1c\n" +
+	"\tretryable\x18\x04 \x01(\bR\tretryable\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x05 \x01(\tR\trequestId\"\xe9\x02\n" +
+	"\aTimings\x124\n" +
+	"\x16query_understanding_ms\x18\x01 \x01(\x03R\x14queryUnderstandingMs\x12(\n" +
+	"\x10geo_prefilter_ms\x18\x02 \x01(\x03R\x0egeoPrefilterMs\x12!\n" +
+	"\fembedding_ms\x18\x03 \x01(\x03R\vembeddingMs\x12(\n" +
+	"\x10vector_search_ms\x18\x04 \x01(\x03R\x0evectorSearchMs\x12*\n" +
+	"\x11keyword_search_ms\x18\x05 \x01(\x03R\x0fkeywordSearchMs\x12\x1b\n" +
+	"\tsafety_ms\x18\x06 \x01(\x03R\bsafetyMs\x12\x1f\n" +
+	"\vmetadata_ms\x18\a \x01(\x03R\n" +
+	"metadataMs\x12\x1b\n" +
+	"\trerank_ms\x18\b \x01(\x03R\brerankMs\x12*\n" +
+	"\x11llm_completion_ms\x18\t \x01(\x03R\x0fllmCompletionMs\"\xa3\x03\n" +
+	"\x0eRecommendation\x12\x14\n" +
+	"\x05hotel\x18\x01 \x01(\tR\x05hotel\x12\x12\n" +
+	"\x04city\x18\x02 \x01(\tR\x04city\x12\x16\n" +
+	"\x06review\x18\x03 \x01(\tR\x06review\x12\x16\n" +
+	"\x06rating\x18\x04 \x01(\x01R\x06rating\x12\x1a\n" +
+	"\bdistance\x18\x05 \x01(\x01R\bdistance\x12\x18\n" +
+	"\aaddress\x18\x06 \x01(\tR\aaddress\x12&\n" +
+	"\x0fgoogle_maps_uri\x18\a \x01(\tR\rgoogleMapsUri\x12\x1d\n" +
+	"\n" +
+	"photo_name\x18\b \x01(\tR\tphotoName\x12\x19\n" +
+	"\bhotel_id\x18\t \x01(\tR\ahotelId\x12\x1f\n" +
+	"\vdistance_km\x18\n" +
+	" \x01(\x01R\n" +
+	"distanceKm\x12\x17\n" +
+	"\amap_url\x18\v \x01(\tR\x06mapUrl\x12\x1f\n" +
+	"\vphoto_thumb\x18\f \x01(\tR\n" +
+	"photoThumb\x12\x1d\n" +
+	"\n" +
+	"photo_full\x18\r \x01(\tR\tphotoFull\x12%\n" +
+	"\x0eimportant_note\x18\x0e \x01(\tR\rimportantNote\"\xb8\x05\n" +
+	"\x0eSearchResponse\x12@\n" +
+	"\n" +
+	"completion\x18\x01 \x03(\v2 .alpaca.search.v1.RecommendationR\n" +
+	"completion\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x122\n" +
+	"\x05usage\x18\x04 \x01(\v2\x1c.alpaca.search.v1.TokenUsageR\x05usage\x12!\n" +
+	"\fvector_count\x18\x05 \x01(\x05R\vvectorCount\x12\x1d\n" +
+	"\n" +
+	"safe_query\x18\x06 \x01(\bR\tsafeQuery\x12\x12\n" +
+	"\x04mode\x18\a \x01(\tR\x04mode\x128\n" +
+	"\afilters\x18\b \x01(\v2\x1e.alpaca.search.v1.QueryFiltersR\afilters\x12\x1a\n" +
+	"\breranker\x18\t \x01(\tR\breranker\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\n" +
+	" \x01(\tR\trequestId\x12%\n" +
+	"\x0eprompt_version\x18\v \x01(\tR\rpromptVersion\x122\n" +
+	"\x15safety_prompt_version\x18\f \x01(\tR\x13safetyPromptVersion\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\r \x01(\tR\tsessionId\x125\n" +
+	"\x06errors\x18\x0e \x03(\v2\x1d.alpaca.search.v1.SearchErrorR\x06errors\x12\x18\n" +
+	"\apartial\x18\x0f \x01(\bR\apartial\x123\n" +
+	"\atimings\x18\x10 \x01(\v2\x19.alpaca.search.v1.TimingsR\atimings\x125\n" +
+	"\tretrieved\x18\x11 \x03(\v2\x17.google.protobuf.StructR\tretrieved\"\xa6\x04\n" +
+	"\n" +
+	"SearchDone\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x122\n" +
+	"\x05usage\x18\x02 \x01(\v2\x1c.alpaca.search.v1.TokenUsageR\x05usage\x12!\n" +
+	"\fvector_count\x18\x03 \x01(\x05R\vvectorCount\x12\x1d\n" +
+	"\n" +
+	"item_count\x18\x04 \x01(\x05R\titemCount\x12\x1d\n" +
+	"\n" +
+	"safe_query\x18\x05 \x01(\bR\tsafeQuery\x12\x12\n" +
+	"\x04mode\x18\x06 \x01(\tR\x04mode\x128\n" +
+	"\afilters\x18\a \x01(\v2\x1e.alpaca.search.v1.QueryFiltersR\afilters\x12\x1a\n" +
+	"\breranker\x18\b \x01(\tR\breranker\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\t \x01(\tR\trequestId\x12%\n" +
+	"\x0eprompt_version\x18\n" +
+	" \x01(\tR\rpromptVersion\x122\n" +
+	"\x15safety_prompt_version\x18\v \x01(\tR\x13safetyPromptVersion\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\f \x01(\tR\tsessionId\x125\n" +
+	"\x06errors\x18\r \x03(\v2\x1d.alpaca.search.v1.SearchErrorR\x06errors\x123\n" +
+	"\atimings\x18\x0e \x01(\v2\x19.alpaca.search.v1.TimingsR\atimings\"E\n" +
+	"\x10RetrievedReviews\x121\n" +
+	"\areviews\x18\x01 \x03(\v2\x17.google.protobuf.StructR\areviews\"\x1f\n" +
+	"\vReviewOrder\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"\x97\x03\n" +
+	"\vSearchEvent\x12:\n" +
+	"\afilters\x18\x01 \x01(\v2\x1e.alpaca.search.v1.QueryFiltersH\x00R\afilters\x12>\n" +
+	"\areviews\x18\x02 \x01(\v2\".alpaca.search.v1.RetrievedReviewsH\x00R\areviews\x126\n" +
+	"\x04item\x18\x03 \x01(\v2 .alpaca.search.v1.RecommendationH\x00R\x04item\x12\x1a\n" +
+	"\amessage\x18\x04 \x01(\tH\x00R\amessage\x125\n" +
+	"\x05error\x18\x05 \x01(\v2\x1d.alpaca.search.v1.SearchErrorH\x00R\x05error\x122\n" +
+	"\x04done\x18\x06 \x01(\v2\x1c.alpaca.search.v1.SearchDoneH\x00R\x04done\x12D\n" +
+	"\rreviews_order\x18\a \x01(\v2\x1d.alpaca.search.v1.ReviewOrderH\x00R\freviewsOrderB\a\n" +
+	"\x05event\"\x15\n" +
+	"\x13GetLocationsRequest\"T\n" +
+	"\rLocationGroup\x12\x1c\n" +
+	"\tcontinent\x18\x01 \x01(\tR\tcontinent\x12%\n" +
+	"\x0ecity_countries\x18\x02 \x03(\tR\rcityCountries\"U\n" +
+	"\x14GetLocationsResponse\x12=\n" +
+	"\tlocations\x18\x01 \x03(\v2\x1f.alpaca.search.v1.LocationGroupR\tlocations\"\xa7\x01\n" +
+	"\x0fGetHotelRequest\x12\x19\n" +
+	"\bhotel_id\x18\x01 \x01(\tR\ahotelId\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x12\n" +
+	"\x04sort\x18\x04 \x01(\tR\x04sort\x12\x1c\n" +
+	"\tascending\x18\x05 \x01(\bR\tascending\x12\x16\n" +
+	"\x06source\x18\x06 \x01(\tR\x06source\"\xc3\x06\n" +
+	"\x05Hotel\x12\x19\n" +
+	"\bhotel_id\x18\x01 \x01(\tR\ahotelId\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04city\x18\x04 \x01(\tR\x04city\x12\x18\n" +
+	"\acountry\x18\x05 \x01(\tR\acountry\x12\x1a\n" +
+	"\blatitude\x18\x06 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\a \x01(\x01R\tlongitude\x12%\n" +
+	"\x0estreet_address\x18\b \x01(\tR\rstreetAddress\x12\x1f\n" +
+	"\vpostal_code\x18\t \x01(\tR\n" +
+	"postalCode\x12\x14\n" +
+	"\x05phone\x18\n" +
+	" \x01(\tR\x05phone\x12\x18\n" +
+	"\awebsite\x18\v \x01(\tR\awebsite\x12%\n" +
+	"\x0eamadeus_rating\x18\f \x01(\x01R\ramadeusRating\x12#\n" +
+	"\rgoogle_rating\x18\r \x01(\x01R\fgoogleRating\x12-\n" +
+	"\x12tripadvisor_rating\x18\x0e \x01(\x01R\x11tripadvisorRating\x12%\n" +
+	"\x0ebooking_rating\x18\x0f \x01(\x01R\rbookingRating\x12%\n" +
+	"\x0eoverall_rating\x18\x10 \x01(\x01R\roverallRating\x12*\n" +
+	"\x11number_of_reviews\x18\x11 \x01(\x05R\x0fnumberOfReviews\x12 \n" +
+	"\vrecommended\x18\x12 \x01(\bR\vrecommended\x12\x18\n" +
+	"\aquality\x18\x13 \x01(\bR\aquality\x12\x14\n" +
+	"\x05quiet\x18\x14 \x01(\bR\x05quiet\x12%\n" +
+	"\x0eimportant_note\x18\x15 \x01(\tR\rimportantNote\x12\x1d\n" +
+	"\n" +
+	"photo_name\x18\x16 \x01(\tR\tphotoName\x12\x1f\n" +
+	"\vphoto_thumb\x18\x17 \x01(\tR\n" +
+	"photoThumb\x12\x1d\n" +
+	"\n" +
+	"photo_full\x18\x18 \x01(\tR\tphotoFull\x12F\n" +
+	"\x12amadeus_sentiments\x18\x19 \x01(\v2\x17.google.protobuf.StructR\x11amadeusSentiments\"\x9f\x03\n" +
+	"\x06Review\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12#\n" +
+	"\rreviewer_name\x18\x03 \x01(\tR\freviewerName\x12+\n" +
+	"\x11reviewer_location\x18\x04 \x01(\tR\x10reviewerLocation\x12\x16\n" +
+	"\x06rating\x18\x05 \x01(\x01R\x06rating\x12\x1f\n" +
+	"\vreview_text\x18\x06 \x01(\tR\n" +
+	"reviewText\x12;\n" +
+	"\vreview_date\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"reviewDate\x12\x1a\n" +
+	"\bverified\x18\b \x01(\bR\bverified\x12#\n" +
+	"\rhelpful_count\x18\t \x01(\x05R\fhelpfulCount\x12\x1b\n" +
+	"\troom_type\x18\n" +
+	" \x01(\tR\broomType\x12\x1f\n" +
+	"\vtravel_type\x18\v \x01(\tR\n" +
+	"travelType\x12&\n" +
+	"\x0fgoogle_maps_uri\x18\f \x01(\tR\rgoogleMapsUri\"S\n" +
+	"\n" +
+	"Pagination\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x03R\x05total\"\xb3\x01\n" +
+	"\x10GetHotelResponse\x12-\n" +
+	"\x05hotel\x18\x01 \x01(\v2\x17.alpaca.search.v1.HotelR\x05hotel\x122\n" +
+	"\areviews\x18\x02 \x03(\v2\x18.alpaca.search.v1.ReviewR\areviews\x12<\n" +
+	"\n" +
+	"pagination\x18\x03 \x01(\v2\x1c.alpaca.search.v1.PaginationR\n" +
+	"pagination2\xe0\x02\n" +
+	"\rSearchService\x12K\n" +
+	"\x06Search\x12\x1f.alpaca.search.v1.SearchRequest\x1a .alpaca.search.v1.SearchResponse\x12P\n" +
+	"\fStreamSearch\x12\x1f.alpaca.search.v1.SearchRequest\x1a\x1d.alpaca.search.v1.SearchEvent0\x01\x12]\n" +
+	"\fGetLocations\x12%.alpaca.search.v1.GetLocationsRequest\x1a&.alpaca.search.v1.GetLocationsResponse\x12Q\n" +
+	"\bGetHotel\x12!.alpaca.search.v1.GetHotelRequest\x1a\".alpaca.search.v1.GetHotelResponseBAZ?github.com/chukiagosoftware/alpaca/vertex/api/searchpb;searchpbb\x06proto3"
+
+var (
+	file_search_proto_rawDescOnce sync.Once
+	file_search_proto_rawDescData []byte
+)
+
+func file_search_proto_rawDescGZIP() []byte {
+	file_search_proto_rawDescOnce.Do(func() {
+		file_search_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_search_proto_rawDesc), len(file_search_proto_rawDesc)))
+	})
+	return file_search_proto_rawDescData
+}
+
+var file_search_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_search_proto_goTypes = []any{
+	(*SearchRequest)(nil),         // 0: alpaca.search.v1.SearchRequest
+	(*TokenUsage)(nil),            // 1: alpaca.search.v1.TokenUsage
+	(*QueryFilters)(nil),          // 2: alpaca.search.v1.QueryFilters
+	(*SearchError)(nil),           // 3: alpaca.search.v1.SearchError
+	(*Timings)(nil),               // 4: alpaca.search.v1.Timings
+	(*Recommendation)(nil),        // 5: alpaca.search.v1.Recommendation
+	(*SearchResponse)(nil),        // 6: alpaca.search.v1.SearchResponse
+	(*SearchDone)(nil),            // 7: alpaca.search.v1.SearchDone
+	(*RetrievedReviews)(nil),      // 8: alpaca.search.v1.RetrievedReviews
+	(*ReviewOrder)(nil),           // 9: alpaca.search.v1.ReviewOrder
+	(*SearchEvent)(nil),           // 10: alpaca.search.v1.SearchEvent
+	(*GetLocationsRequest)(nil),   // 11: alpaca.search.v1.GetLocationsRequest
+	(*LocationGroup)(nil),         // 12: alpaca.search.v1.LocationGroup
+	(*GetLocationsResponse)(nil),  // 13: alpaca.search.v1.GetLocationsResponse
+	(*GetHotelRequest)(nil),       // 14: alpaca.search.v1.GetHotelRequest
+	(*Hotel)(nil),                 // 15: alpaca.search.v1.Hotel
+	(*Review)(nil),                // 16: alpaca.search.v1.Review
+	(*Pagination)(nil),            // 17: alpaca.search.v1.Pagination
+	(*GetHotelResponse)(nil),      // 18: alpaca.search.v1.GetHotelResponse
+	(*structpb.Struct)(nil),       // 19: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil), // 20: google.protobuf.Timestamp
+}
+var file_search_proto_depIdxs = []int32{
+	5,  // 0: alpaca.search.v1.SearchResponse.completion:type_name -> alpaca.search.v1.Recommendation
+	1,  // 1: alpaca.search.v1.SearchResponse.usage:type_name -> alpaca.search.v1.TokenUsage
+	2,  // 2: alpaca.search.v1.SearchResponse.filters:type_name -> alpaca.search.v1.QueryFilters
+	3,  // 3: alpaca.search.v1.SearchResponse.errors:type_name -> alpaca.search.v1.SearchError
+	4,  // 4: alpaca.search.v1.SearchResponse.timings:type_name -> alpaca.search.v1.Timings
+	19, // 5: alpaca.search.v1.SearchResponse.retrieved:type_name -> google.protobuf.Struct
+	1,  // 6: alpaca.search.v1.SearchDone.usage:type_name -> alpaca.search.v1.TokenUsage
+	2,  // 7: alpaca.search.v1.SearchDone.filters:type_name -> alpaca.search.v1.QueryFilters
+	3,  // 8: alpaca.search.v1.SearchDone.errors:type_name -> alpaca.search.v1.SearchError
+	4,  // 9: alpaca.search.v1.SearchDone.timings:type_name -> alpaca.search.v1.Timings
+	19, // 10: alpaca.search.v1.RetrievedReviews.reviews:type_name -> google.protobuf.Struct
+	2,  // 11: alpaca.search.v1.SearchEvent.filters:type_name -> alpaca.search.v1.QueryFilters
+	8,  // 12: alpaca.search.v1.SearchEvent.reviews:type_name -> alpaca.search.v1.RetrievedReviews
+	5,  // 13: alpaca.search.v1.SearchEvent.item:type_name -> alpaca.search.v1.Recommendation
+	3,  // 14: alpaca.search.v1.SearchEvent.error:type_name -> alpaca.search.v1.SearchError
+	7,  // 15: alpaca.search.v1.SearchEvent.done:type_name -> alpaca.search.v1.SearchDone
+	9,  // 16: alpaca.search.v1.SearchEvent.reviews_order:type_name -> alpaca.search.v1.ReviewOrder
+	12, // 17: alpaca.search.v1.GetLocationsResponse.locations:type_name -> alpaca.search.v1.LocationGroup
+	19, // 18: alpaca.search.v1.Hotel.amadeus_sentiments:type_name -> google.protobuf.Struct
+	20, // 19: alpaca.search.v1.Review.review_date:type_name -> google.protobuf.Timestamp
+	15, // 20: alpaca.search.v1.GetHotelResponse.hotel:type_name -> alpaca.search.v1.Hotel
+	16, // 21: alpaca.search.v1.GetHotelResponse.reviews:type_name -> alpaca.search.v1.Review
+	17, // 22: alpaca.search.v1.GetHotelResponse.pagination:type_name -> alpaca.search.v1.Pagination
+	0,  // 23: alpaca.search.v1.SearchService.Search:input_type -> alpaca.search.v1.SearchRequest
+	0,  // 24: alpaca.search.v1.SearchService.StreamSearch:input_type -> alpaca.search.v1.SearchRequest
+	11, // 25: alpaca.search.v1.SearchService.GetLocations:input_type -> alpaca.search.v1.GetLocationsRequest
+	14, // 26: alpaca.search.v1.SearchService.GetHotel:input_type -> alpaca.search.v1.GetHotelRequest
+	6,  // 27: alpaca.search.v1.SearchService.Search:output_type -> alpaca.search.v1.SearchResponse
+	10, // 28: alpaca.search.v1.SearchService.StreamSearch:output_type -> alpaca.search.v1.SearchEvent
+	13, // 29: alpaca.search.v1.SearchService.GetLocations:output_type -> alpaca.search.v1.GetLocationsResponse
+	18, // 30: alpaca.search.v1.SearchService.GetHotel:output_type -> alpaca.search.v1.GetHotelResponse
+	27, // [27:31] is the sub-list for method output_type
+	23, // [23:27] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
+}
+
+func init() { file_search_proto_init() }
+func file_search_proto_init() {
+	if File_search_proto != nil {
+		return
+	}
+	file_search_proto_msgTypes[10].OneofWrappers = []any{
+		(*SearchEvent_Filters)(nil),
+		(*SearchEvent_Reviews)(nil),
+		(*SearchEvent_Item)(nil),
+		(*SearchEvent_Message)(nil),
+		(*SearchEvent_Error)(nil),
+		(*SearchEvent_Done)(nil),
+		(*SearchEvent_ReviewsOrder)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_search_proto_rawDesc), len(file_search_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_search_proto_goTypes,
+		DependencyIndexes: file_search_proto_depIdxs,
+		MessageInfos:      file_search_proto_msgTypes,
+	}.Build()
+	File_search_proto = out.File
+	file_search_proto_goTypes = nil
+	file_search_proto_depIdxs = nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+	"google.golang.org/grpc"
+)
+
+const (
+	defaultPort            = "8080"
+	defaultReadTimeout     = 15 * time.Second
+	defaultSearchTimeout   = 60 * time.Second
+	defaultShutdownTimeout = 10 * time.Second
+)
+
+// configSeconds converts a *_seconds config value, unset or negative values use the default
+func configSeconds(seconds int, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// searchTimeout bounds a request, streamed searches and gRPC calls apply it themselves
+func searchTimeout(config *vertex.Config) time.Duration {
+	return configSeconds(config.SearchTimeoutSeconds, defaultSearchTimeout)
+}
+
+func shutdownTimeout(config *vertex.Config) time.Duration {
+	return configSeconds(config.ShutdownTimeoutSeconds, defaultShutdownTimeout)
+}
+
+// newHTTPServer listens on port, or on PORT as set by Cloud Run. The write timeout leaves room for the search
+// timeout response of the timeout middleware.
+func newHTTPServer(config *vertex.Config, handler http.Handler) *http.Server {
+	port := strings.TrimPrefix(config.Port, ":")
+	if port == "" {
+		port = os.Getenv("PORT")
+	}
+	if port == "" {
+		port = defaultPort
+	}
+
+	return &http.Server{
+		Addr:              ":" + port,
+		Handler:           handler,
+		ReadTimeout:       configSeconds(config.ReadTimeoutSeconds, defaultReadTimeout),
+		ReadHeaderTimeout: configSeconds(config.ReadTimeoutSeconds, defaultReadTimeout),
+		WriteTimeout:      configSeconds(config.WriteTimeoutSeconds, searchTimeout(config)+defaultReadTimeout),
+	}
+}
+
+// stopGRPC waits for in-flight calls to finish, cancelling the remaining ones once ctx is done
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+	"github.com/gin-gonic/gin"
+)
+
+// loadSession returns the session a search continues. An empty id starts a new session, which is only stored once
+// its first turn is answered; an unknown or expired id is ErrSessionNotFound so the client can start over.
+func loadSession(ctx context.Context, store vertex.SessionStore, id string) (string, *vertex.Session, error) {
+	if store == nil || id == "" {
+		return vertex.NewSessionID(), &vertex.Session{}, nil
+	}
+	session, err := store.Get(ctx, id)
+	if err != nil {
+		return id, nil, err
+	}
+	return id, session, nil
+}
+
+// saveSessionTurn records an answered question with the reviews it was answered from. Failures are only logged,
+// the search itself succeeded.
+func saveSessionTurn(ctx context.Context, requestID string, store vertex.SessionStore, id, question string, items, retrieved []map[string]any) {
+	if store == nil {
+		return
+	}
+	turn := vertex.Turn{
+		Question:  question,
+		Answer:    vertex.SummarizeAnswer(items),
+		ReviewIDs: retrievedIDs(retrieved),
+	}
+	if err := store.AppendTurn(ctx, id, turn); err != nil {
+		log.Printf("[%s] Failed to save turn of session %s: %v", requestID, id, err)
+	}
+}
+
+// DeleteSessionHandler ends a conversation before it expires
+func DeleteSessionHandler(c *gin.Context, store vertex.SessionStore) {
+	if store == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sessions not configured"})
+		return
+	}
+	err := store.Delete(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, vertex.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("error: Failed to delete session %s: %v", c.Param("id"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete session"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
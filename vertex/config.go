@@ -1,6 +1,7 @@
 package vertex
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -29,8 +30,81 @@ type Config struct {
 	GrokModel                    string `mapstructure:"grok_model"`
 	OpenAIAPIKey                 string `mapstructure:"openai_api_key"`
 	OpenAIModel                  string `mapstructure:"openai_model"`
+	ClaudeAPIKey                 string `mapstructure:"claude_api_key"`
+	ClaudeModel                  string `mapstructure:"claude_model"`
+	BedrockRegion                string `mapstructure:"bedrock_region"`
+	BedrockModel                 string `mapstructure:"bedrock_model"`
 	GooglePlacesAPIKey           string `mapstructure:"google_places_api_key"`
 	CORSAllowedOrigins           string `mapstructure:"cors_allowed_origins"`
+	VectorBackend                string `mapstructure:"vector_backend"`
+	LocalEmbeddingsPath          string `mapstructure:"local_embeddings_path"`
+	QdrantURL                    string `mapstructure:"qdrant_url"`
+	QdrantAPIKey                 string `mapstructure:"qdrant_api_key"`
+	QdrantCollection             string `mapstructure:"qdrant_collection"`
+	DatabaseDriver               string `mapstructure:"database_driver"`
+	DatabaseURL                  string `mapstructure:"database_url"`
+	MetadataBackend              string `mapstructure:"metadata_backend"`
+	PhotoURLSigningKey           string `mapstructure:"photo_url_signing_key"`
+	PhotoCacheDir                string `mapstructure:"photo_cache_dir"`
+	SearchMode                   string `mapstructure:"search_mode"`
+	Reranker                     string `mapstructure:"reranker"`
+	RerankerURL                  string `mapstructure:"reranker_url"`
+	RerankerAPIKey               string `mapstructure:"reranker_api_key"`
+	RerankerModel                string `mapstructure:"reranker_model"`
+	RerankCandidates             int    `mapstructure:"rerank_candidates"`
+	QueryUnderstanding           bool   `mapstructure:"query_understanding"`
+	SessionStore                 string `mapstructure:"session_store"`
+	SessionTTLMinutes            int    `mapstructure:"session_ttl_minutes"`
+	SessionMaxTurns              int    `mapstructure:"session_max_turns"`
+	PromptDir                    string `mapstructure:"prompt_dir"`
+	// AdminToken is the bearer token of the /api/admin endpoints, which are disabled when it is empty
+	AdminToken string `mapstructure:"admin_token"`
+	// GRPCPort serves the gRPC SearchService next to the HTTP API, disabled when empty
+	GRPCPort string `mapstructure:"grpc_port"`
+	// Port of the HTTP server, falling back to the PORT environment variable and then 8080
+	Port string `mapstructure:"port"`
+	// ReadTimeoutSeconds and WriteTimeoutSeconds bound reading a request and writing its response, 15 seconds and
+	// search_timeout_seconds plus 15 seconds by default
+	ReadTimeoutSeconds  int `mapstructure:"read_timeout_seconds"`
+	WriteTimeoutSeconds int `mapstructure:"write_timeout_seconds"`
+	// SearchTimeoutSeconds bounds each API request, streamed search and gRPC call, 60 seconds by default
+	SearchTimeoutSeconds int `mapstructure:"search_timeout_seconds"`
+	// ShutdownTimeoutSeconds is how long in-flight requests may drain after SIGTERM, 10 seconds by default
+	ShutdownTimeoutSeconds int `mapstructure:"shutdown_timeout_seconds"`
+	// HealthTimeoutSeconds bounds each dependency check of /readyz, 5 seconds by default
+	HealthTimeoutSeconds int `mapstructure:"health_timeout_seconds"`
+	// HealthCacheSeconds is how long /readyz reuses its dependency checks, 10 seconds by default
+	HealthCacheSeconds int `mapstructure:"health_cache_seconds"`
+	// BreakerThreshold is the number of consecutive provider failures that open its circuit breaker, 5 by default
+	BreakerThreshold int `mapstructure:"breaker_threshold"`
+	// BreakerCooldownSeconds is how long an open breaker skips the provider before probing it, 30 seconds by default
+	BreakerCooldownSeconds int `mapstructure:"breaker_cooldown_seconds"`
+	// HedgeDelayMS hedges completions: the next provider in the chain starts when no answer arrived within this
+	// delay, and the first valid answer wins. Zero, the default, falls back only after a failure.
+	HedgeDelayMS int `mapstructure:"hedge_delay_ms"`
+	// OpenAICompatible adds providers by name, selected with llm=<name> like the built-in ones
+	OpenAICompatible map[string]OpenAICompatibleConfig `mapstructure:"openai_compatible"`
+	// EmbeddingDimensions sizes the pgvector embedding column and its HNSW index, 3072 by default to match the
+	// gemini-embedding-001 vectors of the Vertex index
+	EmbeddingDimensions int `mapstructure:"embedding_dimensions"`
+	// PromptWeights splits traffic between prompt versions, keyed by ID such as "completion/v2"
+	PromptWeights map[string]int `mapstructure:"prompt_weights"`
+}
+
+// OpenAICompatibleConfig is a provider speaking the OpenAI chat completions API, such as vLLM, Ollama or LM Studio
+type OpenAICompatibleConfig struct {
+	// BaseURL is the server root, with or without the /v1 suffix
+	BaseURL string `mapstructure:"base_url"`
+	Model   string `mapstructure:"model"`
+	APIKey  string `mapstructure:"api_key"`
+	// APIKeyEnv names the environment variable holding the key when api_key is empty
+	APIKeyEnv string `mapstructure:"api_key_env"`
+	// AuthHeader carries the key, Authorization (the default) sends it as a bearer token
+	AuthHeader string `mapstructure:"auth_header"`
+	// JSONSchema enables response_format json_schema, servers without it get the schema in the system prompt
+	JSONSchema bool `mapstructure:"json_schema"`
+	// MaxTokens bounds completions, the server default applies when unset
+	MaxTokens int `mapstructure:"max_tokens"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -52,3 +126,38 @@ func LoadConfig() (*Config, error) {
 
 	return &config, nil
 }
+
+// Validate reports settings missing for the selected backends, which would otherwise only fail on the first search
+func (c *Config) Validate() error {
+	var missing []string
+	require := func(key, value string) {
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, key)
+		}
+	}
+
+	// Gemini always runs on Vertex AI
+	require("project_id", c.ProjectID)
+	require("location", c.Location)
+
+	switch strings.ToLower(strings.TrimSpace(c.VectorBackend)) {
+	case "", "vertex":
+		require("endpoint_public_domain_name", c.EndpointPublicDomainName)
+		require("endpoint_id", c.EndpointID)
+		require("deployed_index_id", c.DeployedIndexID)
+	case "local", "qdrant", "pgvector":
+	default:
+		return fmt.Errorf("unknown vector backend: %s", c.VectorBackend)
+	}
+
+	if !strings.EqualFold(c.MetadataBackend, "sql") {
+		require("dataset_id", c.DatasetID)
+		require("big_hotels", c.BigHotels)
+		require("big_review_embeddings", c.BigReviewEmbeddings)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required config: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
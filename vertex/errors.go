@@ -0,0 +1,169 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Search pipeline stages reported in SearchError.Stage
+const (
+	StageRequest      = "request"
+	StageUnderstand   = "query_understanding"
+	StageGeo          = "geo_filter"
+	StageSafety       = "safety"
+	StageEmbedding    = "embedding"
+	StageVectorSearch = "vector_search"
+	StageKeyword      = "keyword_search"
+	StageMetadata     = "metadata"
+	StageRerank       = "rerank"
+	StageCompletion   = "completion"
+	StageSession      = "session"
+)
+
+// Search error codes reported in SearchError.Code
+const (
+	ErrCodeInvalidRequest     = "invalid_request"
+	ErrCodeUnderstandFailed   = "query_understanding_failed"
+	ErrCodeGeoFailed          = "geo_filter_failed"
+	ErrCodeTimeout            = "timeout"
+	ErrCodeCanceled           = "canceled"
+	ErrCodeSafetyFailed       = "safety_check_failed"
+	ErrCodeEmbeddingFailed    = "embedding_failed"
+	ErrCodeVectorSearchFailed = "vector_search_failed"
+	ErrCodeKeywordFailed      = "keyword_search_failed"
+	ErrCodeMetadataFailed     = "metadata_failed"
+	ErrCodeRerankFailed       = "rerank_failed"
+	ErrCodeCompletionFailed   = "completion_failed"
+	ErrCodeCompletionInvalid  = "completion_invalid_json"
+	ErrCodeSessionNotFound    = "session_not_found"
+	ErrCodeSessionFailed      = "session_failed"
+)
+
+var stageErrorCodes = map[string]string{
+	StageRequest:      ErrCodeInvalidRequest,
+	StageUnderstand:   ErrCodeUnderstandFailed,
+	StageGeo:          ErrCodeGeoFailed,
+	StageSafety:       ErrCodeSafetyFailed,
+	StageEmbedding:    ErrCodeEmbeddingFailed,
+	StageVectorSearch: ErrCodeVectorSearchFailed,
+	StageKeyword:      ErrCodeKeywordFailed,
+	StageMetadata:     ErrCodeMetadataFailed,
+	StageRerank:       ErrCodeRerankFailed,
+	StageCompletion:   ErrCodeCompletionFailed,
+	StageSession:      ErrCodeSessionFailed,
+}
+
+// SearchError is the typed error payload for a failed search pipeline stage. Message is safe to show to clients,
+// the wrapped error is only logged.
+type SearchError struct {
+	Code      string `json:"code"`
+	Stage     string `json:"stage"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+	RequestID string `json:"request_id,omitempty"`
+	// RetryAfter is the shortest wait the failed LLM providers asked for, sent as the Retry-After header
+	RetryAfter time.Duration `json:"-"`
+	Err        error         `json:"-"`
+}
+
+func (e *SearchError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s (%s): %v", e.Code, e.Stage, e.Err)
+	}
+	return fmt.Sprintf("%s (%s): %s", e.Code, e.Stage, e.Message)
+}
+
+func (e *SearchError) Unwrap() error { return e.Err }
+
+// NewSearchError classifies err for stage, detecting timeouts and transient upstream failures
+func NewSearchError(stage string, err error, requestID string) *SearchError {
+	var existing *SearchError
+	if errors.As(err, &existing) {
+		return existing
+	}
+
+	e := &SearchError{
+		Code:      stageErrorCodes[stage],
+		Stage:     stage,
+		Message:   fmt.Sprintf("%s stage failed", stage),
+		Retryable: isRetryableSearchError(err),
+		RequestID: requestID,
+		Err:       err,
+	}
+	if e.Code == "" {
+		e.Code = stage + "_failed"
+	}
+
+	if providerErrs := providerErrors(err); len(providerErrs) > 0 {
+		schemaOnly := true
+		for _, pe := range providerErrs {
+			if pe.RetryAfter > 0 && (e.RetryAfter == 0 || pe.RetryAfter < e.RetryAfter) {
+				e.RetryAfter = pe.RetryAfter
+			}
+			if pe.Kind != ProviderErrSchema {
+				schemaOnly = false
+			}
+		}
+		if schemaOnly && stage == StageCompletion {
+			e.Code = ErrCodeCompletionInvalid
+			e.Message = "completion returned invalid JSON"
+			e.Retryable = true
+		}
+	}
+
+	switch {
+	case errors.Is(err, ErrSessionNotFound):
+		e.Code = ErrCodeSessionNotFound
+		e.Message = ErrSessionNotFound.Error()
+	case errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded:
+		e.Code = ErrCodeTimeout
+		e.Message = fmt.Sprintf("%s stage timed out", stage)
+		e.Retryable = true
+	case errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled:
+		e.Code = ErrCodeCanceled
+		e.Message = fmt.Sprintf("%s stage was canceled", stage)
+	}
+	return e
+}
+
+func isRetryableSearchError(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	for _, pe := range providerErrors(err) {
+		switch pe.Kind {
+		case ProviderErrRateLimited, ProviderErrTimeout, ProviderErrUnavailable, ProviderErrCircuitOpen:
+			return true
+		}
+	}
+	return false
+}
+
+// providerErrors collects the ProviderErrors in err, including each one joined by a failed fallback chain
+func providerErrors(err error) []*ProviderError {
+	switch e := err.(type) {
+	case *ProviderError:
+		return []*ProviderError{e}
+	case interface{ Unwrap() []error }:
+		var out []*ProviderError
+		for _, inner := range e.Unwrap() {
+			out = append(out, providerErrors(inner)...)
+		}
+		return out
+	case interface{ Unwrap() error }:
+		return providerErrors(e.Unwrap())
+	}
+	return nil
+}
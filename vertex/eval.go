@@ -0,0 +1,359 @@
+package vertex
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Eval stages reported in EvalCaseResult.Timings, named like the search response timings
+var EvalStages = []string{"query_understanding_ms", "geo_prefilter_ms", "embedding_ms", "vector_search_ms", "keyword_search_ms", "metadata_ms", "rerank_ms", "llm_completion_ms"}
+
+// EvalCase is one golden question. A retrieved review is relevant when its ID is in ExpectedReviewIDs or, when
+// only hotels are given, when it is a review of one of ExpectedHotels.
+type EvalCase struct {
+	ID                string   `json:"id"`
+	Question          string   `json:"question"`
+	Continent         string   `json:"continent,omitempty"`
+	City              string   `json:"city,omitempty"`
+	Country           string   `json:"country,omitempty"`
+	Rating            int      `json:"rating,omitempty"`
+	Mode              string   `json:"mode,omitempty"`
+	ExpectedHotels    []string `json:"expected_hotels,omitempty"`
+	ExpectedReviewIDs []string `json:"expected_review_ids,omitempty"`
+}
+
+// Input builds the search input for the case the same way the form filters are applied by the API
+func (c EvalCase) Input(config *Config) (SearchInput, error) {
+	mode, err := ParseSearchMode(c.Mode, config.SearchMode)
+	if err != nil {
+		return SearchInput{}, err
+	}
+	input := SearchInput{
+		Question:       c.Question,
+		Continent:      c.Continent,
+		City:           c.City,
+		Country:        c.Country,
+		PreferredModel: config.PreferredModel,
+		Mode:           mode,
+	}
+	if c.City != "" && c.Country != "" {
+		input.FilterCityCountry = true
+	}
+	if c.Rating > 0 {
+		input.Rating = c.Rating
+		input.FilterRating = true
+	}
+	return input, nil
+}
+
+// EvalCaseResult holds the metrics of one case, retrieval metrics are computed over the top K reviews sent to the completion
+type EvalCaseResult struct {
+	ID           string   `json:"id"`
+	Question     string   `json:"question"`
+	RetrievedIDs []string `json:"retrieved_ids"`
+	AnswerHotels []string `json:"answer_hotels,omitempty"`
+	// Model is the model that answered, empty without a completion
+	Model            string           `json:"model,omitempty"`
+	Recall           float64          `json:"recall"`
+	ReciprocalRank   float64          `json:"reciprocal_rank"`
+	NDCG             float64          `json:"ndcg"`
+	HallucinatedRate float64          `json:"hallucinated_rate"`
+	Hallucinated     []string         `json:"hallucinated,omitempty"`
+	Timings          map[string]int64 `json:"timings"`
+	Error            string           `json:"error,omitempty"`
+}
+
+// LatencyStats summarizes one stage across cases
+type LatencyStats struct {
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  int64   `json:"p50_ms"`
+	P95Ms  int64   `json:"p95_ms"`
+}
+
+// EvalSummary averages the case metrics. Hallucination is averaged over the cases that produced an answer.
+type EvalSummary struct {
+	Cases            int                     `json:"cases"`
+	Failed           int                     `json:"failed"`
+	RecallAtK        float64                 `json:"recall_at_k"`
+	MRR              float64                 `json:"mrr"`
+	NDCG             float64                 `json:"ndcg"`
+	HallucinatedRate float64                 `json:"hallucinated_rate"`
+	Latency          map[string]LatencyStats `json:"latency"`
+}
+
+// EvalReport is the JSON written by the eval command and read back as a baseline
+type EvalReport struct {
+	CreatedAt      time.Time        `json:"created_at"`
+	K              int              `json:"k"`
+	Mode           string           `json:"mode"`
+	Reranker       string           `json:"reranker"`
+	Model          string           `json:"model"`
+	EmbeddingModel string           `json:"embedding_model"`
+	PromptVersion  string           `json:"prompt_version"`
+	Summary        EvalSummary      `json:"summary"`
+	Cases          []EvalCaseResult `json:"cases"`
+}
+
+// LoadEvalCases reads a golden set with one JSON EvalCase per line, blank lines and lines starting with # are skipped
+func LoadEvalCases(path string) ([]EvalCase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []EvalCase
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var c EvalCase
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if c.Question == "" {
+			return nil, fmt.Errorf("%s:%d: question is required", path, line)
+		}
+		if len(c.ExpectedHotels) == 0 && len(c.ExpectedReviewIDs) == 0 {
+			return nil, fmt.Errorf("%s:%d: expected_hotels or expected_review_ids is required", path, line)
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("case-%d", line)
+		}
+		cases = append(cases, c)
+	}
+	return cases, scanner.Err()
+}
+
+// LoadEvalReport reads a report written by a previous eval run
+func LoadEvalReport(path string) (*EvalReport, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report EvalReport
+	if err := json.Unmarshal(b, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// ScoreRetrieval computes recall@k, reciprocal rank and binary NDCG@k over the ranked metadata rows
+func ScoreRetrieval(c EvalCase, ranked []map[string]any, k int) (recall, reciprocalRank, ndcg float64) {
+	if k > 0 && len(ranked) > k {
+		ranked = ranked[:k]
+	}
+
+	expectedIDs := toSet(c.ExpectedReviewIDs)
+	expectedHotels := toSet(c.ExpectedHotels)
+	relevant := func(row map[string]any) bool {
+		if len(expectedIDs) > 0 {
+			return expectedIDs[strings.ToLower(fmt.Sprintf("%v", row["id"]))]
+		}
+		return expectedHotels[strings.ToLower(fmt.Sprintf("%v", row["hotel_name"]))]
+	}
+
+	found := make(map[string]bool)
+	var dcg float64
+	var hits int
+	for i, row := range ranked {
+		if !relevant(row) {
+			continue
+		}
+		hits++
+		if reciprocalRank == 0 {
+			reciprocalRank = 1 / float64(i+1)
+		}
+		dcg += 1 / math.Log2(float64(i+2))
+		if len(expectedIDs) > 0 {
+			found[strings.ToLower(fmt.Sprintf("%v", row["id"]))] = true
+		} else {
+			found[strings.ToLower(fmt.Sprintf("%v", row["hotel_name"]))] = true
+		}
+	}
+
+	expected := len(expectedIDs)
+	if expected == 0 {
+		expected = len(expectedHotels)
+	}
+	if expected > 0 {
+		recall = float64(len(found)) / float64(expected)
+	}
+
+	// With hotel-level labels every review of an expected hotel is relevant, so the ideal ranking has at least as
+	// many relevant reviews as were found
+	ideal := max(hits, expected)
+	if k > 0 {
+		ideal = min(ideal, k)
+	}
+	var idcg float64
+	for i := 0; i < ideal; i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+	if idcg > 0 {
+		ndcg = dcg / idcg
+	}
+	return recall, reciprocalRank, ndcg
+}
+
+// HallucinatedHotels returns the recommended hotels that are not among the reviews the completion was given
+func HallucinatedHotels(items, retrieved []map[string]any) []string {
+	known := make(map[string]bool, len(retrieved))
+	for _, row := range retrieved {
+		known[strings.ToLower(strings.TrimSpace(fmt.Sprintf("%v", row["hotel_name"])))] = true
+	}
+	var hallucinated []string
+	for _, item := range items {
+		hotel := strings.TrimSpace(fmt.Sprintf("%v", item["Hotel"]))
+		if !known[strings.ToLower(hotel)] {
+			hallucinated = append(hallucinated, hotel)
+		}
+	}
+	return hallucinated
+}
+
+// SummarizeEval averages the case results and computes per-stage latency percentiles
+func SummarizeEval(results []EvalCaseResult) EvalSummary {
+	summary := EvalSummary{Cases: len(results), Latency: make(map[string]LatencyStats)}
+	var scored, answered int
+	stageTimes := make(map[string][]int64)
+	for _, r := range results {
+		if r.Error != "" {
+			summary.Failed++
+			continue
+		}
+		scored++
+		summary.RecallAtK += r.Recall
+		summary.MRR += r.ReciprocalRank
+		summary.NDCG += r.NDCG
+		if len(r.AnswerHotels) > 0 {
+			answered++
+			summary.HallucinatedRate += r.HallucinatedRate
+		}
+		for stage, ms := range r.Timings {
+			stageTimes[stage] = append(stageTimes[stage], ms)
+		}
+	}
+	if scored > 0 {
+		summary.RecallAtK /= float64(scored)
+		summary.MRR /= float64(scored)
+		summary.NDCG /= float64(scored)
+	}
+	if answered > 0 {
+		summary.HallucinatedRate /= float64(answered)
+	}
+
+	for stage, times := range stageTimes {
+		sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+		var total int64
+		for _, t := range times {
+			total += t
+		}
+		summary.Latency[stage] = LatencyStats{
+			MeanMs: float64(total) / float64(len(times)),
+			P50Ms:  percentile(times, 0.50),
+			P95Ms:  percentile(times, 0.95),
+		}
+	}
+	return summary
+}
+
+// MarkdownDiff renders the summary against a baseline report, with the cases whose recall or MRR regressed.
+// A nil baseline renders the summary alone.
+func (r *EvalReport) MarkdownDiff(baseline *EvalReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Search evaluation\n\n")
+	fmt.Fprintf(&b, "k=%d, mode=%s, reranker=%s, model=%s, prompt=%s, embedding=%s, %d cases (%d failed)\n\n",
+		r.K, r.Mode, r.Reranker, r.Model, r.PromptVersion, r.EmbeddingModel, r.Summary.Cases, r.Summary.Failed)
+
+	var base EvalSummary
+	if baseline != nil {
+		base = baseline.Summary
+		fmt.Fprintf(&b, "Baseline from %s: k=%d, mode=%s, reranker=%s, model=%s, prompt=%s, embedding=%s\n\n",
+			baseline.CreatedAt.Format(time.RFC3339), baseline.K, baseline.Mode, baseline.Reranker, baseline.Model, baseline.PromptVersion, baseline.EmbeddingModel)
+		b.WriteString("| Metric | Baseline | Current | Δ |\n|---|---:|---:|---:|\n")
+	} else {
+		b.WriteString("| Metric | Current |\n|---|---:|\n")
+	}
+
+	row := func(name string, baseValue, value float64, format string) {
+		if baseline == nil {
+			fmt.Fprintf(&b, "| %s | "+format+" |\n", name, value)
+			return
+		}
+		fmt.Fprintf(&b, "| %s | "+format+" | "+format+" | %+"+strings.TrimPrefix(format, "%")+" |\n", name, baseValue, value, value-baseValue)
+	}
+	row(fmt.Sprintf("Recall@%d", r.K), base.RecallAtK, r.Summary.RecallAtK, "%.3f")
+	row("MRR", base.MRR, r.Summary.MRR, "%.3f")
+	row(fmt.Sprintf("NDCG@%d", r.K), base.NDCG, r.Summary.NDCG, "%.3f")
+	row("Hallucinated hotel rate", base.HallucinatedRate, r.Summary.HallucinatedRate, "%.3f")
+	for _, stage := range EvalStages {
+		stats, ok := r.Summary.Latency[stage]
+		if !ok {
+			continue
+		}
+		baseStats := base.Latency[stage]
+		name := strings.TrimSuffix(stage, "_ms")
+		row(name+" p50 ms", float64(baseStats.P50Ms), float64(stats.P50Ms), "%.0f")
+		row(name+" p95 ms", float64(baseStats.P95Ms), float64(stats.P95Ms), "%.0f")
+	}
+
+	if baseline == nil {
+		return b.String()
+	}
+
+	baseCases := make(map[string]EvalCaseResult, len(baseline.Cases))
+	for _, c := range baseline.Cases {
+		baseCases[c.ID] = c
+	}
+	var regressions []string
+	for _, c := range r.Cases {
+		prev, ok := baseCases[c.ID]
+		if !ok || prev.Error != "" {
+			continue
+		}
+		if c.Error != "" {
+			regressions = append(regressions, fmt.Sprintf("| %s | %s | failed: %s |", c.ID, markdownEscape(c.Question), c.Error))
+		} else if c.Recall < prev.Recall || c.ReciprocalRank < prev.ReciprocalRank {
+			regressions = append(regressions, fmt.Sprintf("| %s | %s | recall %.2f → %.2f, RR %.2f → %.2f |",
+				c.ID, markdownEscape(c.Question), prev.Recall, c.Recall, prev.ReciprocalRank, c.ReciprocalRank))
+		}
+	}
+	if len(regressions) > 0 {
+		b.WriteString("\n## Regressed cases\n\n| Case | Question | Change |\n|---|---|---|\n")
+		b.WriteString(strings.Join(regressions, "\n"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			set[v] = true
+		}
+	}
+	return set
+}
+
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
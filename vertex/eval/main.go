@@ -0,0 +1,221 @@
+package main
+
+// Offline evaluation of retrieval and answers against a golden set.
+// Each case runs the SearchHandler pipeline as a new session: query understanding, the geo prefilter, embedding,
+// vector and/or keyword search, metadata, rerank and completion. The stores are selected from metadata_backend and
+// vector_backend by the same constructors as in the API.
+//
+//   go run ./vertex/eval -golden eval/golden.jsonl -out eval/report.json -baseline eval/baseline.json -markdown eval/report.md
+//
+// Use -retrieval-only to skip the completion and hallucination scoring.
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+	"github.com/chukiagosoftware/alpaca/vertex/stores"
+)
+
+func main() {
+	goldenPath := flag.String("golden", "eval/golden.jsonl", "golden set, one JSON case per line")
+	outPath := flag.String("out", "eval/report.json", "JSON report output")
+	baselinePath := flag.String("baseline", "", "previous JSON report to diff against")
+	markdownPath := flag.String("markdown", "", "Markdown report output, stdout when empty")
+	k := flag.Int("k", 0, "cutoff for recall and NDCG, defaults to the configured limit")
+	retrievalOnly := flag.Bool("retrieval-only", false, "skip the completion")
+	prompt := flag.String("prompt", vertex.DefaultPromptVersion, "completion prompt version to evaluate")
+	flag.Parse()
+
+	config, err := vertex.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *k <= 0 {
+		*k = config.Limit
+	}
+
+	cases, err := vertex.LoadEvalCases(*goldenPath)
+	if err != nil {
+		log.Fatalf("Failed to load golden set: %v", err)
+	}
+
+	var baseline *vertex.EvalReport
+	if *baselinePath != "" {
+		baseline, err = vertex.LoadEvalReport(*baselinePath)
+		if err != nil {
+			log.Fatalf("Failed to load baseline: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	db, err := vertex.OpenDatabase(config)
+	if err != nil {
+		log.Fatal("Failed to open database:", err)
+	}
+	defer db.Close()
+
+	vsSvc, err := vertex.NewVertexSearchService(ctx, config, db)
+	if err != nil {
+		log.Fatal("Failed to create Vertex service:", err)
+	}
+	defer vsSvc.Close()
+
+	searchStores, err := stores.Open(ctx, config, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer searchStores.Close()
+
+	mode, _ := vertex.ParseSearchMode("", config.SearchMode)
+	report := vertex.EvalReport{
+		CreatedAt:      time.Now().UTC(),
+		K:              *k,
+		Mode:           mode,
+		Reranker:       vsSvc.RerankerName(),
+		EmbeddingModel: vertex.EmbeddingModel,
+		PromptVersion:  vertex.PromptID(vertex.PromptKindCompletion, *prompt),
+	}
+
+	for i, c := range cases {
+		result := runCase(ctx, config, vsSvc, searchStores.Retrieval, c, *prompt, *k, !*retrievalOnly)
+		if result.Error != "" {
+			log.Printf("❌ %d/%d %s: %s", i+1, len(cases), c.ID, result.Error)
+		} else {
+			log.Printf("✅ %d/%d %s: recall=%.2f rr=%.2f ndcg=%.2f", i+1, len(cases), c.ID, result.Recall, result.ReciprocalRank, result.NDCG)
+		}
+		report.Cases = append(report.Cases, result)
+	}
+	report.Summary = vertex.SummarizeEval(report.Cases)
+	report.Model = answeringModels(report.Cases)
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(*outPath), 0755); err != nil {
+		log.Fatalf("Failed to create report directory: %v", err)
+	}
+	if err := os.WriteFile(*outPath, out, 0644); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+	log.Printf("📄 Wrote %s", *outPath)
+
+	markdown := report.MarkdownDiff(baseline)
+	if *markdownPath == "" {
+		fmt.Print(markdown)
+		return
+	}
+	if err := os.WriteFile(*markdownPath, []byte(markdown), 0644); err != nil {
+		log.Fatalf("Failed to write Markdown report: %v", err)
+	}
+	log.Printf("📄 Wrote %s", *markdownPath)
+}
+
+// runCase runs the retrieval of SearchHandler, then its rerank and completion. Any failed stage fails the case,
+// where the API would return partial results.
+func runCase(ctx context.Context, config *vertex.Config, vsSvc *vertex.VertexSearchService, backends vertex.RetrievalBackends, c vertex.EvalCase, prompt string, k int, withCompletion bool) vertex.EvalCaseResult {
+	result := vertex.EvalCaseResult{ID: c.ID, Question: c.Question, Timings: make(map[string]int64)}
+	fail := func(stage string, err error) vertex.EvalCaseResult {
+		result.Error = fmt.Sprintf("%s: %v", stage, err)
+		return result
+	}
+
+	input, err := c.Input(config)
+	if err == nil {
+		input.PromptVersion = prompt
+		input, err = vsSvc.AssignPrompts(input)
+	}
+	if err != nil {
+		return fail(vertex.StageRequest, err)
+	}
+
+	caseConfig := *config
+	caseConfig.Limit = k
+
+	var mu sync.Mutex
+	var failedStage string
+	var stageErr error
+	r := vsSvc.Retrieve(ctx, &caseConfig, backends, input, nil, func(stage string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if stageErr == nil {
+			failedStage, stageErr = stage, err
+		}
+	})
+	for stage, d := range map[string]time.Duration{
+		"query_understanding_ms": r.Timings.Understand,
+		"geo_prefilter_ms":       r.Timings.Geo,
+		"embedding_ms":           r.Timings.Embedding,
+		"vector_search_ms":       r.Timings.VectorSearch,
+		"keyword_search_ms":      r.Timings.Keyword,
+		"metadata_ms":            r.Timings.Metadata,
+	} {
+		if d > 0 {
+			result.Timings[stage] = d.Milliseconds()
+		}
+	}
+	if stageErr != nil {
+		return fail(failedStage, stageErr)
+	}
+
+	retrieved := r.Rows
+	if len(retrieved) > 0 {
+		start := time.Now()
+		retrieved, err = vsSvc.Rerank(ctx, r.Input, retrieved, k)
+		result.Timings["rerank_ms"] = time.Since(start).Milliseconds()
+		if err != nil {
+			return fail(vertex.StageRerank, err)
+		}
+	}
+
+	for _, row := range retrieved {
+		result.RetrievedIDs = append(result.RetrievedIDs, fmt.Sprintf("%v", row["id"]))
+	}
+	result.Recall, result.ReciprocalRank, result.NDCG = vertex.ScoreRetrieval(c, retrieved, k)
+
+	if !withCompletion || len(retrieved) == 0 {
+		return result
+	}
+
+	start := time.Now()
+	completion, err := vsSvc.PromptCompletion(ctx, r.Input, retrieved)
+	result.Timings["llm_completion_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		return fail(vertex.StageCompletion, err)
+	}
+	result.Model = completion.Model
+	items, err := vertex.ParseCompletionJSON(completion.Content)
+	if err != nil {
+		return fail(vertex.StageCompletion, err)
+	}
+	for _, item := range items {
+		result.AnswerHotels = append(result.AnswerHotels, fmt.Sprintf("%v", item["Hotel"]))
+	}
+	result.Hallucinated = vertex.HallucinatedHotels(items, retrieved)
+	if len(items) > 0 {
+		result.HallucinatedRate = float64(len(result.Hallucinated)) / float64(len(items))
+	}
+	return result
+}
+
+// answeringModels lists the models that answered the cases in order of first use, several when the completion
+// fell back to another provider
+func answeringModels(results []vertex.EvalCaseResult) string {
+	var models []string
+	for _, r := range results {
+		if r.Model != "" && !slices.Contains(models, r.Model) {
+			models = append(models, r.Model)
+		}
+	}
+	return strings.Join(models, ", ")
+}
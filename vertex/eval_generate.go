@@ -0,0 +1,113 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chukiagosoftware/alpaca/internal/orm"
+)
+
+const (
+	evalReviewChars = 1500
+	// evalDuplicateSimilarity is the Jaccard similarity of question terms above which two questions are duplicates
+	evalDuplicateSimilarity = 0.7
+)
+
+// GenerateEvalQuestions asks the LLM for realistic traveller questions that the review answers. The questions
+// must not name the hotel, so retrieval has to find the review from what it says.
+func GenerateEvalQuestions(ctx context.Context, router *CompletionRouter, model string, review orm.ReviewSample, n int) ([]string, error) {
+	resp, err := router.CompleteJSON(ctx, model, buildEvalQuestionPrompt(review, n), "eval_questions", evalQuestionsJSONSchema())
+	if err != nil {
+		return nil, err
+	}
+
+	content := strings.TrimSpace(resp.Content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimSuffix(strings.TrimPrefix(content, "```"), "```")
+
+	var parsed struct {
+		Questions []string `json:"questions"`
+	}
+	if err := json.Unmarshal([]byte(content), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse generated questions: %w", err)
+	}
+
+	var questions []string
+	for _, q := range parsed.Questions {
+		if q = strings.TrimSpace(q); q != "" && !strings.Contains(strings.ToLower(q), strings.ToLower(review.HotelName)) {
+			questions = append(questions, q)
+		}
+	}
+	if len(questions) > n {
+		questions = questions[:n]
+	}
+	return questions, nil
+}
+
+func evalQuestionsJSONSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"questions": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+		},
+		"required":             []string{"questions"},
+		"additionalProperties": false,
+	}
+}
+
+func buildEvalQuestionPrompt(review orm.ReviewSample, n int) string {
+	text := []rune(review.ReviewText)
+	if len(text) > evalReviewChars {
+		text = append(text[:evalReviewChars], '…')
+	}
+	return fmt.Sprintf(`Write %d different questions a traveller planning a trip to %s, %s could ask a hotel search engine,
+which this hotel review answers well. Base each question on something specific the review says, such as location,
+noise, breakfast, staff, rooms or value. Write them the way travellers type searches, one short sentence each.
+Do not mention the hotel name or the reviewer.
+
+Review of %s, rated %.0f:
+%s`, n, review.City, review.Country, review.HotelName, review.Rating, string(text))
+}
+
+// QuestionDeduper rejects questions whose terms nearly match a question already accepted
+type QuestionDeduper struct {
+	seen [][]string
+}
+
+// Add reports whether the question is new, remembering it when it is
+func (d *QuestionDeduper) Add(question string) bool {
+	terms := KeywordTerms(question)
+	if len(terms) == 0 {
+		return false
+	}
+	for _, other := range d.seen {
+		if jaccard(terms, other) >= evalDuplicateSimilarity {
+			return false
+		}
+	}
+	d.seen = append(d.seen, terms)
+	return true
+}
+
+func jaccard(a, b []string) float64 {
+	set := make(map[string]bool, len(a))
+	for _, t := range a {
+		set[t] = true
+	}
+	var shared int
+	for _, t := range b {
+		if set[t] {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
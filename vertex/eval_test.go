@@ -0,0 +1,191 @@
+package vertex
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reviewRows builds ranked metadata rows from id:hotel pairs
+func reviewRows(pairs ...string) []map[string]any {
+	rows := make([]map[string]any, len(pairs))
+	for i, p := range pairs {
+		id, hotel, _ := strings.Cut(p, ":")
+		rows[i] = map[string]any{"id": id, "hotel_name": hotel}
+	}
+	return rows
+}
+
+func TestScoreRetrieval(t *testing.T) {
+	tests := []struct {
+		name       string
+		c          EvalCase
+		ranked     []map[string]any
+		k          int
+		wantRecall float64
+		wantRR     float64
+		wantNDCG   float64
+	}{
+		{
+			name:       "perfect ranking",
+			c:          EvalCase{ExpectedReviewIDs: []string{"1"}},
+			ranked:     reviewRows("1:A", "2:B"),
+			k:          5,
+			wantRecall: 1, wantRR: 1, wantNDCG: 1,
+		},
+		{
+			// Only 2 is in the top 3, at rank 2: DCG = 1/log2(3), the ideal ranks both expected reviews first
+			name:       "relevant review cut off by k",
+			c:          EvalCase{ExpectedReviewIDs: []string{"2", "5"}},
+			ranked:     reviewRows("1:A", "2:B", "3:C", "5:D"),
+			k:          3,
+			wantRecall: 0.5, wantRR: 0.5, wantNDCG: (1 / math.Log2(3)) / (1 + 1/math.Log2(3)),
+		},
+		{
+			// Both reviews of the expected hotel are relevant, at ranks 2 and 3
+			name:       "hotel labels match case insensitively",
+			c:          EvalCase{ExpectedHotels: []string{"Grand Hotel"}},
+			ranked:     reviewRows("1:Other", "2:grand hotel", "3:Grand Hotel"),
+			k:          3,
+			wantRecall: 1, wantRR: 0.5, wantNDCG: (1/math.Log2(3) + 1/math.Log2(4)) / (1 + 1/math.Log2(3)),
+		},
+		{
+			name:       "review IDs take precedence over hotels",
+			c:          EvalCase{ExpectedReviewIDs: []string{"3"}, ExpectedHotels: []string{"A"}},
+			ranked:     reviewRows("1:A", "2:A", "3:B"),
+			wantRecall: 1, wantRR: 1.0 / 3, wantNDCG: 0.5,
+		},
+		{
+			name:   "nothing relevant",
+			c:      EvalCase{ExpectedReviewIDs: []string{"9"}},
+			ranked: reviewRows("1:A", "2:B"),
+			k:      5,
+		},
+		{
+			name: "nothing retrieved",
+			c:    EvalCase{ExpectedHotels: []string{"A"}},
+			k:    5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recall, rr, ndcg := ScoreRetrieval(tt.c, tt.ranked, tt.k)
+			for _, m := range []struct {
+				name      string
+				got, want float64
+			}{{"recall", recall, tt.wantRecall}, {"reciprocal rank", rr, tt.wantRR}, {"NDCG", ndcg, tt.wantNDCG}} {
+				if math.Abs(m.got-m.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", m.name, m.got, m.want)
+				}
+			}
+		})
+	}
+}
+
+// evalResults are two scored cases, a failed one and an unanswered one
+var evalResults = []EvalCaseResult{
+	{ID: "a", Recall: 1, ReciprocalRank: 1, NDCG: 1, AnswerHotels: []string{"A"}, HallucinatedRate: 0.5, Timings: map[string]int64{"embedding_ms": 10, "rerank_ms": 30}},
+	{ID: "b", Question: "quiet | central", Recall: 0.5, ReciprocalRank: 0.5, NDCG: 0.25, Timings: map[string]int64{"embedding_ms": 20}},
+	{ID: "c", Error: "embedding: unavailable", Timings: map[string]int64{"embedding_ms": 1000}},
+	{ID: "d", AnswerHotels: []string{"B"}, Timings: map[string]int64{"embedding_ms": 30}},
+}
+
+func TestSummarizeEval(t *testing.T) {
+	got := SummarizeEval(evalResults)
+	want := EvalSummary{
+		Cases:  4,
+		Failed: 1,
+		// Averaged over the three cases without an error, hallucination over the two that answered
+		RecallAtK:        0.5,
+		MRR:              0.5,
+		NDCG:             1.25 / 3,
+		HallucinatedRate: 0.25,
+		Latency: map[string]LatencyStats{
+			"embedding_ms": {MeanMs: 20, P50Ms: 20, P95Ms: 30},
+			"rerank_ms":    {MeanMs: 30, P50Ms: 30, P95Ms: 30},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeEval = %+v, want %+v", got, want)
+	}
+}
+
+func TestSummarizeEvalEmpty(t *testing.T) {
+	got := SummarizeEval(nil)
+	if got.Cases != 0 || got.RecallAtK != 0 || got.MRR != 0 || len(got.Latency) != 0 {
+		t.Errorf("SummarizeEval(nil) = %+v, want zero metrics", got)
+	}
+}
+
+func TestEvalReportMarkdownDiff(t *testing.T) {
+	current := &EvalReport{K: 5, Mode: SearchModeHybrid, Model: "gemini", Cases: evalResults, Summary: SummarizeEval(evalResults)}
+	baseline := &EvalReport{
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		K:         5,
+		Mode:      SearchModeVector,
+		Summary: EvalSummary{
+			RecallAtK: 0.6, MRR: 0.25, NDCG: 0.5,
+			Latency: map[string]LatencyStats{"embedding_ms": {P50Ms: 25, P95Ms: 30}},
+		},
+		Cases: []EvalCaseResult{
+			{ID: "a", Recall: 1, ReciprocalRank: 0.5},
+			{ID: "b", Recall: 1, ReciprocalRank: 0.5},
+			{ID: "c", Recall: 1, ReciprocalRank: 1},
+			{ID: "d", Error: "timeout"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		baseline *EvalReport
+		want     []string
+		wantNot  []string
+	}{
+		{
+			name:     "without baseline",
+			baseline: nil,
+			want: []string{
+				"k=5, mode=hybrid, reranker=, model=gemini, prompt=, embedding=, 4 cases (1 failed)\n",
+				"| Metric | Current |\n|---|---:|\n",
+				"| Recall@5 | 0.500 |\n",
+				"| NDCG@5 | 0.417 |\n",
+				"| embedding p50 ms | 20 |\n",
+				"| embedding p95 ms | 30 |\n",
+				"| rerank p95 ms | 30 |\n",
+			},
+			wantNot: []string{"Baseline", "Regressed"},
+		},
+		{
+			name:     "against a baseline",
+			baseline: baseline,
+			want: []string{
+				"Baseline from 2026-01-02T03:04:05Z: k=5, mode=vector,",
+				"| Recall@5 | 0.600 | 0.500 | -0.100 |\n",
+				"| MRR | 0.250 | 0.500 | +0.250 |\n",
+				"| embedding p50 ms | 25 | 20 | -5 |\n",
+				"| rerank p50 ms | 0 | 30 | +30 |\n",
+				// b regressed and c failed; a improved and d failed in the baseline
+				"| b | quiet \\| central | recall 1.00 → 0.50, RR 0.50 → 0.50 |\n",
+				"| c |  | failed: embedding: unavailable |\n",
+			},
+			wantNot: []string{"| a |", "| d |"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markdown := current.MarkdownDiff(tt.baseline)
+			for _, s := range tt.want {
+				if !strings.Contains(markdown, s) {
+					t.Errorf("Markdown lacks %q:\n%s", s, markdown)
+				}
+			}
+			for _, s := range tt.wantNot {
+				if strings.Contains(markdown, s) {
+					t.Errorf("Markdown contains %q:\n%s", s, markdown)
+				}
+			}
+		})
+	}
+}
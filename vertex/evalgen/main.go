@@ -0,0 +1,128 @@
+package main
+
+// Generates synthetic evaluation cases for ./vertex/eval from the hotel_reviews table.
+// Reviews are sampled per city and an LLM writes traveller questions each review answers. Every question becomes a
+// case with the city filter and the review's review_embeddings ID as the expected result. Reviews that were never
+// embedded are skipped, retrieval cannot return them.
+//
+//   go run ./vertex/evalgen -out eval/golden.jsonl -per-city 5 -per-continent 100
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+	"google.golang.org/genai"
+)
+
+func main() {
+	outPath := flag.String("out", "eval/golden.jsonl", "JSONL output of evaluation cases")
+	perCity := flag.Int("per-city", 5, "maximum cases per city")
+	perContinent := flag.Int("per-continent", 0, "maximum cases per continent, 0 for no limit")
+	perReview := flag.Int("questions-per-review", 2, "questions generated per sampled review")
+	minLength := flag.Int("min-review-length", 200, "minimum review length in characters")
+	model := flag.String("llm", "", "preferred LLM, defaults to preferred_model")
+	flag.Parse()
+
+	config, err := vertex.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *model == "" {
+		*model = config.PreferredModel
+	}
+
+	ctx := context.Background()
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		Backend:  genai.BackendVertexAI,
+		Project:  config.ProjectID,
+		Location: config.Location,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create GenAI client: %v", err)
+	}
+	router, err := vertex.NewCompletionRouter(ctx, config, *client)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := vertex.OpenDatabase(config)
+	if err != nil {
+		log.Fatal("Failed to open database:", err)
+	}
+	defer db.Close()
+
+	// Oversample, some reviews are not embedded and some questions are dropped as duplicates
+	samples, err := db.SampleReviewsPerCity(ctx, *perCity*2, *minLength)
+	if err != nil {
+		log.Fatalf("Failed to sample reviews: %v", err)
+	}
+	log.Printf("📥 Sampled %d reviews", len(samples))
+
+	if err := os.MkdirAll(filepath.Dir(*outPath), 0755); err != nil {
+		log.Fatalf("Failed to create output directory: %v", err)
+	}
+	out, err := os.Create(*outPath)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", *outPath, err)
+	}
+	defer out.Close()
+	encoder := json.NewEncoder(out)
+
+	deduper := &vertex.QuestionDeduper{}
+	cityCounts := make(map[string]int)
+	continentCounts := make(map[string]int)
+	var written, unembedded, duplicates int
+
+	for _, sample := range samples {
+		city := sample.City + ", " + sample.Country
+		if cityCounts[city] >= *perCity {
+			continue
+		}
+		if *perContinent > 0 && continentCounts[sample.Continent] >= *perContinent {
+			continue
+		}
+		if sample.EmbeddingID == nil {
+			unembedded++
+			continue
+		}
+
+		questions, err := vertex.GenerateEvalQuestions(ctx, router, *model, sample, *perReview)
+		if err != nil {
+			log.Printf("Failed to generate questions for review %d: %v", sample.ReviewID, err)
+			continue
+		}
+
+		for i, question := range questions {
+			if cityCounts[city] >= *perCity || (*perContinent > 0 && continentCounts[sample.Continent] >= *perContinent) {
+				break
+			}
+			if !deduper.Add(question) {
+				duplicates++
+				continue
+			}
+			c := vertex.EvalCase{
+				ID:                fmt.Sprintf("syn-%d-%d", sample.ReviewID, i),
+				Question:          question,
+				City:              sample.City,
+				Country:           sample.Country,
+				ExpectedHotels:    []string{sample.HotelName},
+				ExpectedReviewIDs: []string{fmt.Sprintf("%d", *sample.EmbeddingID)},
+			}
+			if err := encoder.Encode(c); err != nil {
+				log.Fatalf("Failed to write case: %v", err)
+			}
+			cityCounts[city]++
+			continentCounts[sample.Continent]++
+			written++
+		}
+	}
+
+	log.Printf("✅ Wrote %d cases for %d cities to %s (%d duplicate questions, %d reviews not embedded)",
+		written, len(cityCounts), *outPath, duplicates, unembedded)
+}
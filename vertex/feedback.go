@@ -0,0 +1,160 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chukiagosoftware/alpaca/internal/orm"
+	"github.com/chukiagosoftware/alpaca/models"
+	"gorm.io/gorm/clause"
+)
+
+// MaxJudgmentGrade is the highest graded relevance label accepted in feedback
+const MaxJudgmentGrade = 3
+
+// MaxRequestIDLength is the size of the request_id columns, longer caller IDs are replaced by a generated one
+const MaxRequestIDLength = 64
+
+var ErrUnknownRequestID = errors.New("unknown request ID")
+
+// FeedbackForm is the body of POST /api/feedback, either a thumbs Label or a Grade is required
+type FeedbackForm struct {
+	RequestID string `form:"request_id" json:"request_id"`
+	HotelID   string `form:"hotel_id" json:"hotel_id"`
+	ReviewID  string `form:"review_id" json:"review_id"`
+	Label     string `form:"label" json:"label"`
+	Grade     *int   `form:"grade" json:"grade"`
+	Comment   string `form:"comment" json:"comment"`
+}
+
+// Judgment validates the form into the stored model
+func (f FeedbackForm) Judgment() (*models.Judgment, error) {
+	requestID := strings.TrimSpace(f.RequestID)
+	if requestID == "" {
+		return nil, fmt.Errorf("request_id is required")
+	}
+	if len(requestID) > MaxRequestIDLength {
+		return nil, fmt.Errorf("request_id is longer than %d characters", MaxRequestIDLength)
+	}
+	hotelID, reviewID := strings.TrimSpace(f.HotelID), strings.TrimSpace(f.ReviewID)
+	if hotelID == "" && reviewID == "" {
+		return nil, fmt.Errorf("hotel_id or review_id is required")
+	}
+
+	label := strings.ToLower(strings.TrimSpace(f.Label))
+	switch label {
+	case "", models.JudgmentUp, models.JudgmentDown:
+	default:
+		return nil, fmt.Errorf("label must be %q or %q", models.JudgmentUp, models.JudgmentDown)
+	}
+	if f.Grade != nil && (*f.Grade < 0 || *f.Grade > MaxJudgmentGrade) {
+		return nil, fmt.Errorf("grade must be between 0 and %d", MaxJudgmentGrade)
+	}
+	if label == "" && f.Grade == nil {
+		return nil, fmt.Errorf("label or grade is required")
+	}
+
+	return &models.Judgment{
+		RequestID: requestID,
+		HotelID:   hotelID,
+		ReviewID:  reviewID,
+		Label:     label,
+		Grade:     f.Grade,
+		Comment:   strings.TrimSpace(f.Comment),
+	}, nil
+}
+
+// SearchLog is the context of a search recorded for joining feedback back to it
+type SearchLog struct {
+	RequestID    string
+	SessionID    string
+	Input        SearchInput
+	RetrievedIDs []string
+	HotelIDs     []string
+	Model        string
+	Reranker     string
+	Partial      bool
+}
+
+// searchLogFilters are the effective retrieval filters, after merging the form with what was inferred from the question
+type searchLogFilters struct {
+	Continent  string     `json:"continent,omitempty"`
+	City       string     `json:"city,omitempty"`
+	Country    string     `json:"country,omitempty"`
+	MinRating  int        `json:"min_rating,omitempty"`
+	TravelType string     `json:"travel_type,omitempty"`
+	Aspects    []string   `json:"aspects,omitempty"`
+	Geo        *GeoFilter `json:"geo,omitempty"`
+}
+
+// FeedbackStore persists the per-search request log and the relevance judgments referring to it
+type FeedbackStore struct {
+	db *orm.DB
+}
+
+func NewFeedbackStore(db *orm.DB) *FeedbackStore {
+	return &FeedbackStore{db: db}
+}
+
+func (s *FeedbackStore) LogSearch(ctx context.Context, entry SearchLog) error {
+	filters := searchLogFilters{
+		Continent:  entry.Input.Continent,
+		City:       entry.Input.City,
+		Country:    entry.Input.Country,
+		TravelType: entry.Input.TravelType,
+		Aspects:    entry.Input.Aspects,
+		Geo:        entry.Input.Geo,
+	}
+	if entry.Input.FilterRating {
+		filters.MinRating = entry.Input.Rating
+	}
+
+	row := models.SearchRequestLog{
+		RequestID:           entry.RequestID,
+		SessionID:           entry.SessionID,
+		Question:            entry.Input.Question,
+		Mode:                entry.Input.Mode,
+		Filters:             jsonString(filters),
+		RetrievedIDs:        jsonString(entry.RetrievedIDs),
+		HotelIDs:            jsonString(entry.HotelIDs),
+		Model:               entry.Model,
+		Reranker:            entry.Reranker,
+		Partial:             entry.Partial,
+		PromptVersion:       PromptID(PromptKindCompletion, entry.Input.PromptVersion),
+		SafetyPromptVersion: PromptID(PromptKindSafety, entry.Input.SafetyPromptVersion),
+	}
+	// A client retry may reuse its request ID, the log then keeps the latest search
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "request_id"}},
+		UpdateAll: true,
+	}).Create(&row).Error; err != nil {
+		return fmt.Errorf("failed to log search %s: %w", entry.RequestID, err)
+	}
+	return nil
+}
+
+// SaveJudgment stores feedback for a logged search, returning ErrUnknownRequestID for searches never logged
+func (s *FeedbackStore) SaveJudgment(ctx context.Context, judgment *models.Judgment) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.SearchRequestLog{}).Where("request_id = ?", judgment.RequestID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to look up search %s: %w", judgment.RequestID, err)
+	}
+	if count == 0 {
+		return ErrUnknownRequestID
+	}
+	if err := s.db.WithContext(ctx).Create(judgment).Error; err != nil {
+		return fmt.Errorf("failed to save judgment: %w", err)
+	}
+	return nil
+}
+
+func jsonString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
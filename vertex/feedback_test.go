@@ -0,0 +1,70 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chukiagosoftware/alpaca/models"
+)
+
+func TestFeedbackStoreLogSearch(t *testing.T) {
+	ctx := context.Background()
+	db := testDatabase(t)
+	store := NewFeedbackStore(db)
+
+	// A client retry reuses the request ID of the first search
+	for _, question := range []string{"quiet hotel", "quiet hotel near the park"} {
+		entry := SearchLog{RequestID: strings.Repeat("r", MaxRequestIDLength), Input: SearchInput{Question: question}}
+		if err := store.LogSearch(ctx, entry); err != nil {
+			t.Fatalf("LogSearch(%q): %v", question, err)
+		}
+	}
+	var rows []models.SearchRequestLog
+	if err := db.Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Question != "quiet hotel near the park" {
+		t.Errorf("logged rows = %+v, want the latest search only", rows)
+	}
+}
+
+func TestFeedbackFormJudgment(t *testing.T) {
+	grade := 2
+	tests := []struct {
+		name    string
+		form    FeedbackForm
+		wantErr bool
+	}{
+		{name: "label", form: FeedbackForm{RequestID: "r1", HotelID: "h1", Label: "Up"}},
+		{name: "grade", form: FeedbackForm{RequestID: "r1", ReviewID: "1", Grade: &grade}},
+		{name: "missing request ID", form: FeedbackForm{HotelID: "h1", Label: "up"}, wantErr: true},
+		{name: "request ID too long", form: FeedbackForm{RequestID: strings.Repeat("r", MaxRequestIDLength+1), HotelID: "h1", Label: "up"}, wantErr: true},
+		{name: "missing target", form: FeedbackForm{RequestID: "r1", Label: "up"}, wantErr: true},
+		{name: "unknown label", form: FeedbackForm{RequestID: "r1", HotelID: "h1", Label: "meh"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.form.Judgment()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Judgment error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFeedbackStoreSaveJudgment(t *testing.T) {
+	ctx := context.Background()
+	store := NewFeedbackStore(testDatabase(t))
+	if err := store.LogSearch(ctx, SearchLog{RequestID: "r1"}); err != nil {
+		t.Fatalf("LogSearch: %v", err)
+	}
+	if err := store.SaveJudgment(ctx, &models.Judgment{RequestID: "r1", HotelID: "h1", Label: models.JudgmentUp}); err != nil {
+		t.Errorf("SaveJudgment of a logged search: %v", err)
+	}
+	err := store.SaveJudgment(ctx, &models.Judgment{RequestID: "r2", HotelID: "h1", Label: models.JudgmentUp})
+	if !errors.Is(err, ErrUnknownRequestID) {
+		t.Errorf("SaveJudgment of an unknown search error = %v, want ErrUnknownRequestID", err)
+	}
+}
@@ -0,0 +1,184 @@
+package vertex
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	earthRadiusKm    = 6371.0
+	defaultRadiusKm  = 5.0
+	maxRadiusKm      = 500.0
+	maxGeoHotelNames = 500
+)
+
+// GeoFilter restricts a search to hotels within RadiusKm of a point
+type GeoFilter struct {
+	Lat      float64 `json:"lat"`
+	Lng      float64 `json:"lng"`
+	RadiusKm float64 `json:"radius_km"`
+}
+
+// HotelCoordinate is a hotel inside a GeoFilter radius with its distance from the point
+type HotelCoordinate struct {
+	HotelID    string  `json:"hotel_id"`
+	Name       string  `json:"name"`
+	City       string  `json:"city"`
+	Lat        float64 `json:"lat"`
+	Lng        float64 `json:"lng"`
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// HotelLocator returns the hotels with coordinates inside a lat/lng bounding box, longitude bounds may be ignored
+// when the box crosses the antimeridian
+type HotelLocator interface {
+	HotelsInBox(ctx context.Context, minLat, maxLat, minLng, maxLng float64) ([]HotelCoordinate, error)
+}
+
+// ParseGeoFilter parses near=lat,lng and radius_km, defaulting the radius to 5km. An empty near disables the filter.
+func ParseGeoFilter(near, radiusKm string) (*GeoFilter, error) {
+	near = strings.TrimSpace(near)
+	if near == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(near, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("near must be lat,lng")
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid latitude in near: %s", parts[0])
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("invalid longitude in near: %s", parts[1])
+	}
+
+	radius := defaultRadiusKm
+	if strings.TrimSpace(radiusKm) != "" {
+		radius, err = strconv.ParseFloat(strings.TrimSpace(radiusKm), 64)
+		if err != nil || radius <= 0 || radius > maxRadiusKm {
+			return nil, fmt.Errorf("radius_km must be between 0 and %.0f", maxRadiusKm)
+		}
+	}
+	return &GeoFilter{Lat: lat, Lng: lng, RadiusKm: radius}, nil
+}
+
+// HaversineKm is the great-circle distance between two points
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BoundingBox is a lat/lng box containing the radius, used as an indexable prefilter before Haversine
+func (g *GeoFilter) BoundingBox() (minLat, maxLat, minLng, maxLng float64) {
+	dLat := g.RadiusKm / earthRadiusKm * 180 / math.Pi
+	minLat = math.Max(-90, g.Lat-dLat)
+	maxLat = math.Min(90, g.Lat+dLat)
+
+	// The circle is widest slightly poleward of its center, at asin(sin(r)/cos(lat)) rather than r/cos(lat)
+	cosLat := math.Cos(g.Lat * math.Pi / 180)
+	sinSpan := math.Sin(g.RadiusKm/earthRadiusKm) / cosLat
+	if cosLat < 1e-6 || sinSpan >= 1 || minLat == -90 || maxLat == 90 {
+		return minLat, maxLat, -180, 180
+	}
+	dLng := math.Asin(sinSpan) * 180 / math.Pi
+	minLng, maxLng = g.Lng-dLng, g.Lng+dLng
+	if minLng < -180 || maxLng > 180 {
+		// Crosses the antimeridian, rely on the Haversine pass only
+		return minLat, maxLat, -180, 180
+	}
+	return minLat, maxLat, minLng, maxLng
+}
+
+// HotelsNear returns the hotels within the radius sorted by distance, capped to the size of a restrict allow list
+func HotelsNear(ctx context.Context, locator HotelLocator, geo *GeoFilter) ([]HotelCoordinate, error) {
+	if locator == nil {
+		return nil, fmt.Errorf("geo search is not configured")
+	}
+	minLat, maxLat, minLng, maxLng := geo.BoundingBox()
+	candidates, err := locator.HotelsInBox(ctx, minLat, maxLat, minLng, maxLng)
+	if err != nil {
+		return nil, err
+	}
+
+	var hotels []HotelCoordinate
+	for _, h := range candidates {
+		h.DistanceKm = HaversineKm(geo.Lat, geo.Lng, h.Lat, h.Lng)
+		if h.DistanceKm <= geo.RadiusKm {
+			hotels = append(hotels, h)
+		}
+	}
+	sort.Slice(hotels, func(i, j int) bool { return hotels[i].DistanceKm < hotels[j].DistanceKm })
+	if len(hotels) > maxGeoHotelNames {
+		hotels = hotels[:maxGeoHotelNames]
+	}
+	return hotels, nil
+}
+
+// ApplyGeoFilter resolves input.Geo to the hotel_name restrict used by every retrieval backend.
+// With no hotel in range, retrieval is skipped rather than run unrestricted.
+func ApplyGeoFilter(ctx context.Context, locator HotelLocator, input SearchInput) (SearchInput, []HotelCoordinate, error) {
+	if input.Geo == nil {
+		return input, nil, nil
+	}
+	hotels, err := HotelsNear(ctx, locator, input.Geo)
+	if err != nil {
+		input.HotelNames = nil
+		return input, nil, err
+	}
+
+	seen := make(map[string]bool, len(hotels))
+	input.HotelNames = nil
+	for _, h := range hotels {
+		if !seen[h.Name] {
+			seen[h.Name] = true
+			input.HotelNames = append(input.HotelNames, h.Name)
+		}
+	}
+	return input, hotels, nil
+}
+
+// AttachGeoDistance sets "distance_km" on each result and drops reviews of same-named hotels outside the radius,
+// matching results to hotels by name and city
+func AttachGeoDistance(results []map[string]any, geo *GeoFilter, hotels []HotelCoordinate) []map[string]any {
+	if geo == nil {
+		return results
+	}
+
+	byNameCity := make(map[string]HotelCoordinate, len(hotels))
+	byName := make(map[string]HotelCoordinate, len(hotels))
+	for _, h := range hotels {
+		byNameCity[strings.ToLower(h.Name+"|"+h.City)] = h
+		if _, ok := byName[strings.ToLower(h.Name)]; !ok {
+			byName[strings.ToLower(h.Name)] = h
+		}
+	}
+
+	filtered := make([]map[string]any, 0, len(results))
+	for _, r := range results {
+		name := strings.ToLower(fmt.Sprintf("%v", r["hotel_name"]))
+		h, ok := byNameCity[name+"|"+strings.ToLower(fmt.Sprintf("%v", r["city"]))]
+		if !ok {
+			h, ok = byName[name]
+			if ok && h.City != "" && !strings.EqualFold(h.City, fmt.Sprintf("%v", r["city"])) {
+				ok = false
+			}
+		}
+		if !ok {
+			continue
+		}
+		r["distance_km"] = math.Round(h.DistanceKm*100) / 100
+		filtered = append(filtered, r)
+	}
+	return filtered
+}
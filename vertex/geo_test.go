@@ -0,0 +1,199 @@
+package vertex
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{name: "same point", lat1: 48.8566, lng1: 2.3522, lat2: 48.8566, lng2: 2.3522, want: 0},
+		{name: "Paris to London", lat1: 48.8566, lng1: 2.3522, lat2: 51.5074, lng2: -0.1278, want: 343.5},
+		{name: "one degree of latitude", lat1: 0, lng1: 0, lat2: 1, lng2: 0, want: 111.19},
+		{name: "across the antimeridian", lat1: 0, lng1: 179.5, lat2: 0, lng2: -179.5, want: 111.19},
+		{name: "antipodes", lat1: 0, lng1: 0, lat2: 0, lng2: 180, want: math.Pi * earthRadiusKm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HaversineKm(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+			if math.Abs(got-tt.want) > 0.5 {
+				t.Errorf("HaversineKm = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoFilterBoundingBox(t *testing.T) {
+	tests := []struct {
+		name        string
+		geo         GeoFilter
+		wantFullLng bool
+	}{
+		{name: "mid latitude", geo: GeoFilter{Lat: 48.8566, Lng: 2.3522, RadiusKm: 10}},
+		{name: "maximum radius at high latitude", geo: GeoFilter{Lat: 60, Lng: 10, RadiusKm: maxRadiusKm}},
+		{name: "crosses the antimeridian", geo: GeoFilter{Lat: 0, Lng: 179.99, RadiusKm: 10}, wantFullLng: true},
+		{name: "reaches the pole", geo: GeoFilter{Lat: 89.99, Lng: 0, RadiusKm: 10}, wantFullLng: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minLat, maxLat, minLng, maxLng := tt.geo.BoundingBox()
+			if minLat > tt.geo.Lat || maxLat < tt.geo.Lat {
+				t.Errorf("latitude %v outside [%v, %v]", tt.geo.Lat, minLat, maxLat)
+			}
+			fullLng := minLng == -180 && maxLng == 180
+			if fullLng != tt.wantFullLng {
+				t.Errorf("longitude bounds [%v, %v], want full range %v", minLng, maxLng, tt.wantFullLng)
+			}
+			if !fullLng {
+				// Every point of the circle lies inside the box, up to rounding on its edges
+				const eps = 1e-9
+				for bearing := 0.0; bearing < 360; bearing += 0.5 {
+					lat, lng := destination(tt.geo.Lat, tt.geo.Lng, tt.geo.RadiusKm, bearing)
+					if lat < minLat-eps || lat > maxLat+eps || lng < minLng-eps || lng > maxLng+eps {
+						t.Fatalf("point %.6f,%.6f at bearing %v outside the box", lat, lng, bearing)
+					}
+				}
+			}
+		})
+	}
+}
+
+// destination is the point distanceKm away from lat,lng along bearing degrees
+func destination(lat, lng, distanceKm, bearing float64) (float64, float64) {
+	toRad, toDeg := math.Pi/180, 180/math.Pi
+	d := distanceKm / earthRadiusKm
+	lat1, lng1, b := lat*toRad, lng*toRad, bearing*toRad
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(b))
+	lng2 := lng1 + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+	return lat2 * toDeg, lng2 * toDeg
+}
+
+// boxLocator returns every hotel inside the box, ignoring longitude when the box spans all of it
+type boxLocator []HotelCoordinate
+
+func (l boxLocator) HotelsInBox(ctx context.Context, minLat, maxLat, minLng, maxLng float64) ([]HotelCoordinate, error) {
+	var hotels []HotelCoordinate
+	for _, h := range l {
+		if h.Lat >= minLat && h.Lat <= maxLat && h.Lng >= minLng && h.Lng <= maxLng {
+			hotels = append(hotels, h)
+		}
+	}
+	return hotels, nil
+}
+
+func TestApplyGeoFilter(t *testing.T) {
+	locator := boxLocator{
+		{Name: "Louvre Hotel", City: "Paris", Lat: 48.8606, Lng: 2.3376},
+		{Name: "Eiffel Hotel", City: "Paris", Lat: 48.8584, Lng: 2.2945},
+		{Name: "Versailles Hotel", City: "Versailles", Lat: 48.8049, Lng: 2.1204},
+		// Inside the bounding box corner but outside the radius
+		{Name: "Corner Hotel", City: "Paris", Lat: 48.8966, Lng: 2.4122},
+		{Name: "Fiji Hotel", City: "Suva", Lat: -17.71, Lng: 179.99},
+		{Name: "Taveuni Hotel", City: "Taveuni", Lat: -17.71, Lng: -179.99},
+	}
+	tests := []struct {
+		name       string
+		geo        *GeoFilter
+		wantHotels []string
+	}{
+		{name: "no filter", geo: nil, wantHotels: nil},
+		{name: "sorted by distance", geo: &GeoFilter{Lat: 48.8566, Lng: 2.3522, RadiusKm: 5}, wantHotels: []string{"Louvre Hotel", "Eiffel Hotel"}},
+		{name: "wider radius", geo: &GeoFilter{Lat: 48.8566, Lng: 2.3522, RadiusKm: 20}, wantHotels: []string{"Louvre Hotel", "Eiffel Hotel", "Corner Hotel", "Versailles Hotel"}},
+		{name: "no hotel in range", geo: &GeoFilter{Lat: 0, Lng: 0, RadiusKm: 5}, wantHotels: nil},
+		{name: "across the antimeridian", geo: &GeoFilter{Lat: -17.71, Lng: 179.995, RadiusKm: 5}, wantHotels: []string{"Fiji Hotel", "Taveuni Hotel"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, hotels, err := ApplyGeoFilter(context.Background(), locator, SearchInput{Geo: tt.geo, HotelNames: []string{"stale"}})
+			if err != nil {
+				t.Fatalf("ApplyGeoFilter: %v", err)
+			}
+			if tt.geo == nil {
+				if !slices.Equal(input.HotelNames, []string{"stale"}) {
+					t.Errorf("HotelNames changed without a filter: %v", input.HotelNames)
+				}
+				return
+			}
+			if !slices.Equal(input.HotelNames, tt.wantHotels) {
+				t.Errorf("HotelNames = %v, want %v", input.HotelNames, tt.wantHotels)
+			}
+			for _, h := range hotels {
+				if h.DistanceKm > tt.geo.RadiusKm {
+					t.Errorf("%s is %.2fkm away, outside %.2fkm", h.Name, h.DistanceKm, tt.geo.RadiusKm)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyGeoFilterWithoutLocator(t *testing.T) {
+	input, _, err := ApplyGeoFilter(context.Background(), nil, SearchInput{Geo: &GeoFilter{RadiusKm: 5}, HotelNames: []string{"stale"}})
+	if err == nil {
+		t.Fatal("ApplyGeoFilter without a locator succeeded")
+	}
+	if input.HotelNames != nil {
+		t.Errorf("HotelNames = %v, want nil so retrieval is not unrestricted", input.HotelNames)
+	}
+}
+
+func TestParseGeoFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		near     string
+		radiusKm string
+		want     *GeoFilter
+		wantErr  bool
+	}{
+		{name: "empty disables", near: "", want: nil},
+		{name: "default radius", near: "48.85, 2.35", want: &GeoFilter{Lat: 48.85, Lng: 2.35, RadiusKm: defaultRadiusKm}},
+		{name: "explicit radius", near: "48.85,2.35", radiusKm: "12.5", want: &GeoFilter{Lat: 48.85, Lng: 2.35, RadiusKm: 12.5}},
+		{name: "missing longitude", near: "48.85", wantErr: true},
+		{name: "latitude out of range", near: "91,0", wantErr: true},
+		{name: "longitude out of range", near: "0,181", wantErr: true},
+		{name: "radius too large", near: "0,0", radiusKm: "501", wantErr: true},
+		{name: "zero radius", near: "0,0", radiusKm: "0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGeoFilter(tt.near, tt.radiusKm)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGeoFilter error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("ParseGeoFilter = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachGeoDistance(t *testing.T) {
+	hotels := []HotelCoordinate{
+		{Name: "Grand Hotel", City: "Paris", DistanceKm: 1.234},
+		{Name: "Plaza", City: "", DistanceKm: 2},
+	}
+	results := []map[string]any{
+		{"id": 1, "hotel_name": "Grand Hotel", "city": "Paris"},
+		// Same name in another city is outside the radius
+		{"id": 2, "hotel_name": "Grand Hotel", "city": "Rome"},
+		{"id": 3, "hotel_name": "plaza", "city": "Lyon"},
+		{"id": 4, "hotel_name": "Unknown", "city": "Paris"},
+	}
+	got := AttachGeoDistance(results, &GeoFilter{RadiusKm: 5}, hotels)
+
+	var ids []any
+	for _, r := range got {
+		ids = append(ids, r["id"])
+	}
+	if !slices.Equal(ids, []any{1, 3}) {
+		t.Fatalf("AttachGeoDistance IDs = %v, want [1 3]", ids)
+	}
+	if got[0]["distance_km"] != 1.23 {
+		t.Errorf("distance_km = %v, want 1.23", got[0]["distance_km"])
+	}
+}
@@ -0,0 +1,114 @@
+package vertex
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	aiplatformpb "cloud.google.com/go/aiplatform/apiv1/aiplatformpb"
+)
+
+// Pinger is implemented by vector backends and LLM providers that can check they are reachable without doing
+// billable work. Backends without it, such as the in-memory local searcher, are always reachable.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingVectorBackend checks the configured vector backend is reachable
+func (s *VertexSearchService) PingVectorBackend(ctx context.Context) error {
+	if p, ok := s.searcher.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// VectorBackendName is the name of the configured vector backend, e.g. "vertex" or "qdrant"
+func (s *VertexSearchService) VectorBackendName() string {
+	return s.searcher.Name()
+}
+
+// LLMChoices lists the configured providers in name order
+func (s *VertexSearchService) LLMChoices() []LLMChoice {
+	choices := make([]LLMChoice, 0, len(s.completionRouter.providers))
+	for choice := range s.completionRouter.providers {
+		choices = append(choices, choice)
+	}
+	sort.Slice(choices, func(i, j int) bool { return choices[i] < choices[j] })
+	return choices
+}
+
+// PingProvider checks the model of a configured provider is reachable with its credentials
+func (s *VertexSearchService) PingProvider(ctx context.Context, choice LLMChoice) error {
+	provider, ok := s.completionRouter.providers[choice]
+	if !ok {
+		return fmt.Errorf("%s provider not configured", choice)
+	}
+	if p, ok := provider.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ProviderStats reports the circuit breaker state and rolling latency and error stats of each provider
+func (s *VertexSearchService) ProviderStats() []ProviderStats {
+	return s.completionRouter.ProviderStats()
+}
+
+// Ping sends a FindNeighbors request without queries, which checks the endpoint and deployed index exist
+func (v *VertexMatchSearcher) Ping(ctx context.Context) error {
+	_, err := v.matchClient.FindNeighbors(ctx, &aiplatformpb.FindNeighborsRequest{
+		IndexEndpoint:   v.endpointPath,
+		DeployedIndexId: v.deployedIndexID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reach index endpoint %s: %w", v.endpointPath, err)
+	}
+	return nil
+}
+
+// Ping checks the collection exists
+func (q *QdrantSearcher) Ping(ctx context.Context) error {
+	if err := q.do(ctx, http.MethodGet, "/collections/"+q.collection, nil, nil); err != nil {
+		return fmt.Errorf("failed to reach collection %s: %w", q.collection, err)
+	}
+	return nil
+}
+
+func (s *SQLStore) Ping(ctx context.Context) error {
+	sqlDB, err := s.db.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Ping looks up the model, which fails for unknown models and missing credentials
+func (p *GeminiProvider) Ping(ctx context.Context) error {
+	if _, err := p.client.Models.Get(ctx, p.model, nil); err != nil {
+		return fmt.Errorf("failed to get gemini model %s: %w", p.model, err)
+	}
+	return nil
+}
+
+// Ping lists the models, which checks the server is up and accepts the key. Local servers do not all serve
+// /v1/models/{model}, so the model itself is not looked up.
+func (p *OpenAICompatibleProvider) Ping(ctx context.Context) error {
+	req, err := p.newRequest(ctx, http.MethodGet, "/v1/models", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBytes, _ := io.ReadAll(resp.Body)
+		return newHTTPProviderError(p.name, resp, respBytes)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package vertex
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Retrieval modes accepted in SearchForm.Mode
+const (
+	SearchModeVector  = "vector"
+	SearchModeKeyword = "keyword"
+	SearchModeHybrid  = "hybrid"
+)
+
+// rrfK dampens the weight of top ranks in reciprocal rank fusion, 60 is the value from the original paper
+const rrfK = 60
+
+const maxKeywordTerms = 16
+
+// KeywordSearcher ranks review embeddings by exact term matches. It returns the same IDs as the VectorSearcher
+// so both rankings can be fused, with Distance holding the keyword relevance score (higher is better).
+type KeywordSearcher interface {
+	Name() string
+	FindKeyword(ctx context.Context, terms []string, restricts []Restrict, numericRestricts []NumericRestrict, limit int) ([]VectorResult, error)
+}
+
+var keywordStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "best": true, "by": true,
+	"can": true, "do": true, "for": true, "from": true, "good": true, "great": true, "has": true, "have": true,
+	"hotel": true, "hotels": true, "i": true, "in": true, "is": true, "it": true, "me": true, "my": true,
+	"near": true, "of": true, "on": true, "or": true, "recommend": true, "that": true, "the": true, "there": true,
+	"to": true, "want": true, "was": true, "we": true, "what": true, "where": true, "which": true, "with": true,
+	"would": true, "you": true,
+}
+
+// ParseSearchMode validates a requested retrieval mode, falling back to the configured default and then to vector
+func ParseSearchMode(mode, fallback string) (string, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		mode = strings.ToLower(strings.TrimSpace(fallback))
+	}
+	switch mode {
+	case "":
+		return SearchModeVector, nil
+	case SearchModeVector, SearchModeKeyword, SearchModeHybrid:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown search mode %q, expected vector, keyword or hybrid", mode)
+	}
+}
+
+// KeywordTerms splits a question into lowercase alphanumeric terms without stop words, so backends can OR them
+// together without having to escape their own query syntax
+func KeywordTerms(question string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool, len(fields))
+	var terms []string
+	for _, f := range fields {
+		if len([]rune(f)) < 2 || keywordStopWords[f] || seen[f] {
+			continue
+		}
+		seen[f] = true
+		terms = append(terms, f)
+		if len(terms) == maxKeywordTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// KeywordSearch runs keyword retrieval with the restricts derived from the search input
+func KeywordSearch(ctx context.Context, keyword KeywordSearcher, config Config, params SearchInput) ([]VectorResult, error) {
+	if keyword == nil {
+		return nil, fmt.Errorf("keyword search is not configured")
+	}
+	terms := KeywordTerms(params.Question + " " + strings.Join(params.Aspects, " "))
+	if len(terms) == 0 || params.GeoExcludesAll() {
+		return nil, nil
+	}
+	restricts, numericRestricts := BuildRestricts(params)
+	return keyword.FindKeyword(ctx, terms, restricts, numericRestricts, config.Limit)
+}
+
+// FuseRRF merges rankings with reciprocal rank fusion, score = sum of 1/(rrfK + rank). The fused Distance is the
+// RRF score, so results stay ordered by descending Distance like the DOT_PRODUCT_DISTANCE vector results.
+func FuseRRF(limit int, rankings ...[]VectorResult) []VectorResult {
+	scores := make(map[string]float64)
+	var order []string
+	for _, ranking := range rankings {
+		for rank, r := range ranking {
+			if _, ok := scores[r.ID]; !ok {
+				order = append(order, r.ID)
+			}
+			scores[r.ID] += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+
+	fused := make([]VectorResult, 0, len(order))
+	for _, id := range order {
+		fused = append(fused, VectorResult{ID: id, Distance: scores[id]})
+	}
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].Distance > fused[j].Distance
+	})
+
+	if limit > 0 && len(fused) > limit {
+		fused = fused[:limit]
+	}
+	return fused
+}
+
+// MetadataForResults reorders metadata prefetched by a MetadataSearcher to match results, updating distances.
+// It returns nil when any result is missing, so the caller falls back to a MetadataProvider lookup.
+func MetadataForResults(prefetched []map[string]any, results []VectorResult) []map[string]any {
+	if prefetched == nil {
+		return nil
+	}
+	byID := make(map[string]map[string]any, len(prefetched))
+	for _, row := range prefetched {
+		byID[fmt.Sprintf("%v", row["id"])] = row
+	}
+
+	rows := make([]map[string]any, 0, len(results))
+	for _, r := range results {
+		row, ok := byID[r.ID]
+		if !ok {
+			return nil
+		}
+		row["distance"] = r.Distance
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,111 @@
+package vertex
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func rankingOf(ids ...string) []VectorResult {
+	ranking := make([]VectorResult, len(ids))
+	for i, id := range ids {
+		ranking[i] = VectorResult{ID: id}
+	}
+	return ranking
+}
+
+func TestFuseRRF(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		rankings [][]VectorResult
+		wantIDs  []string
+		// wantTop is the expected score of the first result
+		wantTop float64
+	}{
+		{
+			name:    "no rankings",
+			wantIDs: nil,
+		},
+		{
+			name:     "single ranking keeps its order",
+			rankings: [][]VectorResult{rankingOf("a", "b", "c")},
+			wantIDs:  []string{"a", "b", "c"},
+			wantTop:  1.0 / 61,
+		},
+		{
+			name:     "shared results rank above results of one list",
+			rankings: [][]VectorResult{rankingOf("a", "b", "c"), rankingOf("c", "d")},
+			wantIDs:  []string{"c", "a", "b", "d"},
+			wantTop:  1.0/63 + 1.0/61,
+		},
+		{
+			name:     "ties keep first seen order",
+			rankings: [][]VectorResult{rankingOf("a", "b"), rankingOf("b", "a")},
+			wantIDs:  []string{"a", "b"},
+			wantTop:  1.0/61 + 1.0/62,
+		},
+		{
+			name:     "an empty ranking is ignored",
+			rankings: [][]VectorResult{nil, rankingOf("x", "y")},
+			wantIDs:  []string{"x", "y"},
+			wantTop:  1.0 / 61,
+		},
+		{
+			name:     "limit trims after fusion",
+			limit:    2,
+			rankings: [][]VectorResult{rankingOf("a", "b", "c"), rankingOf("c", "b")},
+			wantIDs:  []string{"c", "b"},
+			wantTop:  1.0/63 + 1.0/61,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fused := FuseRRF(tt.limit, tt.rankings...)
+			var ids []string
+			for _, r := range fused {
+				ids = append(ids, r.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Fatalf("FuseRRF IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			if len(fused) > 0 && math.Abs(fused[0].Distance-tt.wantTop) > 1e-12 {
+				t.Errorf("top score = %v, want %v", fused[0].Distance, tt.wantTop)
+			}
+			for i := 1; i < len(fused); i++ {
+				if fused[i].Distance > fused[i-1].Distance {
+					t.Errorf("results not ordered by descending score: %v", fused)
+				}
+			}
+		})
+	}
+}
+
+func TestMetadataForResults(t *testing.T) {
+	prefetched := []map[string]any{{"id": int64(1)}, {"id": int64(2)}}
+	tests := []struct {
+		name       string
+		prefetched []map[string]any
+		results    []VectorResult
+		wantIDs    []any
+	}{
+		{name: "nothing prefetched", results: []VectorResult{{ID: "1"}}, wantIDs: nil},
+		{name: "reordered", prefetched: prefetched, results: []VectorResult{{ID: "2", Distance: 0.5}, {ID: "1"}}, wantIDs: []any{int64(2), int64(1)}},
+		{name: "missing result falls back", prefetched: prefetched, results: []VectorResult{{ID: "3"}}, wantIDs: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := MetadataForResults(tt.prefetched, tt.results)
+			var ids []any
+			for _, row := range rows {
+				ids = append(ids, row["id"])
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("MetadataForResults IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			if len(rows) > 0 && rows[0]["distance"] != tt.results[0].Distance {
+				t.Errorf("distance = %v, want %v", rows[0]["distance"], tt.results[0].Distance)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// bedrockConverser is the part of the bedrockruntime client the provider uses
+type bedrockConverser interface {
+	Converse(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error)
+}
+
+// BedrockProvider calls a model on AWS Bedrock through the Converse API, with credentials from the default AWS
+// chain. Structured output is a forced tool call like ClaudeProvider, which every Converse model with tool use
+// supports. Bedrock has no free call to check a model, so it does not implement Pinger.
+type BedrockProvider struct {
+	client bedrockConverser
+	model  string
+}
+
+func NewBedrockProvider(ctx context.Context, config *Config) (*BedrockProvider, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(config.BedrockRegion))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return &BedrockProvider{
+		client: bedrockruntime.NewFromConfig(awsCfg),
+		model:  firstNonEmpty(config.BedrockModel, "amazon.nova-lite-v1:0"),
+	}, nil
+}
+
+func (p *BedrockProvider) Name() string { return "bedrock" }
+
+func (p *BedrockProvider) CheckQuerySafety(ctx context.Context, prompt string) (bool, error) {
+	out, err := p.client.Converse(ctx, &bedrockruntime.ConverseInput{
+		ModelId:  aws.String(p.model),
+		System:   []types.SystemContentBlock{&types.SystemContentBlockMemberText{Value: "Answer only YES or NO. This is a hotel review relevance safety check."}},
+		Messages: bedrockUserMessage(prompt),
+		InferenceConfig: &types.InferenceConfiguration{
+			MaxTokens:   aws.Int32(32),
+			Temperature: aws.Float32(0),
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("bedrock safety check failed: %w", err)
+	}
+
+	var text strings.Builder
+	if msg, ok := out.Output.(*types.ConverseOutputMemberMessage); ok {
+		for _, block := range msg.Value.Content {
+			if t, ok := block.(*types.ContentBlockMemberText); ok {
+				text.WriteString(t.Value)
+			}
+		}
+	}
+	return strings.Contains(strings.ToLower(text.String()), "yes"), nil
+}
+
+func (p *BedrockProvider) PromptCompletion(ctx context.Context, prompt string) (CompletionResult, error) {
+	return p.CompleteJSON(ctx, prompt, "hotel_review_completion", completionJSONSchema())
+}
+
+// CompleteJSON forces a call of a tool taking schema as input, the tool input is the answer
+func (p *BedrockProvider) CompleteJSON(ctx context.Context, prompt, schemaName string, schema map[string]any) (CompletionResult, error) {
+	inputSchema, wrapped := toolInputSchema(schema)
+	out, err := p.client.Converse(ctx, &bedrockruntime.ConverseInput{
+		ModelId:  aws.String(p.model),
+		System:   []types.SystemContentBlock{&types.SystemContentBlockMemberText{Value: "Answer by calling the " + schemaName + " tool with input matching its schema."}},
+		Messages: bedrockUserMessage(prompt),
+		InferenceConfig: &types.InferenceConfiguration{
+			MaxTokens:   aws.Int32(8192),
+			Temperature: aws.Float32(0.2),
+		},
+		ToolConfig: &types.ToolConfiguration{
+			Tools: []types.Tool{&types.ToolMemberToolSpec{Value: types.ToolSpecification{
+				Name:        aws.String(schemaName),
+				Description: aws.String("Records the answer to the user's request."),
+				InputSchema: &types.ToolInputSchemaMemberJson{Value: document.NewLazyDocument(inputSchema)},
+			}}},
+			ToolChoice: &types.ToolChoiceMemberTool{Value: types.SpecificToolChoice{Name: aws.String(schemaName)}},
+		},
+	})
+	if err != nil {
+		return CompletionResult{}, fmt.Errorf("bedrock completion failed: %w", err)
+	}
+
+	msg, ok := out.Output.(*types.ConverseOutputMemberMessage)
+	if !ok {
+		return CompletionResult{}, &ProviderError{Provider: p.Name(), Kind: ProviderErrSchema, Err: errors.New("no message returned")}
+	}
+	for _, block := range msg.Value.Content {
+		toolUse, ok := block.(*types.ContentBlockMemberToolUse)
+		if !ok || aws.ToString(toolUse.Value.Name) != schemaName || toolUse.Value.Input == nil {
+			continue
+		}
+		input, err := toolUse.Value.Input.MarshalSmithyDocument()
+		if err != nil {
+			return CompletionResult{}, &ProviderError{Provider: p.Name(), Kind: ProviderErrSchema, Err: fmt.Errorf("invalid tool input: %w", err)}
+		}
+		content, err := toolInputContent(input, wrapped)
+		if err != nil {
+			return CompletionResult{}, &ProviderError{Provider: p.Name(), Kind: ProviderErrSchema, Err: fmt.Errorf("invalid tool input: %w", err)}
+		}
+		return CompletionResult{
+			Content: content,
+			Usage:   bedrockUsage(out.Usage),
+			Model:   p.model,
+		}, nil
+	}
+	return CompletionResult{}, &ProviderError{Provider: p.Name(), Kind: ProviderErrSchema, Err: fmt.Errorf("no %s tool call returned", schemaName)}
+}
+
+func bedrockUserMessage(prompt string) []types.Message {
+	return []types.Message{{
+		Role:    types.ConversationRoleUser,
+		Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: prompt}},
+	}}
+}
+
+func bedrockUsage(usage *types.TokenUsage) TokenUsage {
+	if usage == nil {
+		return TokenUsage{}
+	}
+	return TokenUsage{
+		PromptTokens:     int(aws.ToInt32(usage.InputTokens)),
+		CompletionTokens: int(aws.ToInt32(usage.OutputTokens)),
+		TotalTokens:      int(aws.ToInt32(usage.TotalTokens)),
+	}
+}
@@ -0,0 +1,171 @@
+package vertex
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+	// providerStatsWindow is the number of recent calls the rolling stats cover
+	providerStatsWindow = 100
+)
+
+// Circuit breaker states reported in ProviderStats.State
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// ProviderStats are the breaker state and rolling latency and error stats of a provider over its last calls
+type ProviderStats struct {
+	Provider     string     `json:"provider"`
+	State        string     `json:"state"`
+	Calls        int        `json:"calls"`
+	Errors       int        `json:"errors"`
+	ErrorRate    float64    `json:"error_rate"`
+	AvgLatencyMS int64      `json:"avg_latency_ms"`
+	P95LatencyMS int64      `json:"p95_latency_ms"`
+	LastError    string     `json:"last_error,omitempty"`
+	OpenUntil    *time.Time `json:"open_until,omitempty"`
+}
+
+type providerCall struct {
+	latency time.Duration
+	failed  bool
+}
+
+// providerBreaker opens after threshold consecutive failures that reflect on the provider's health, such as rate
+// limits, timeouts and server errors, and the router then skips the provider. Once the cooldown or the provider's
+// Retry-After has passed, one call is let through as a probe: success closes the breaker, failure opens it again.
+type providerBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openUntil time.Time
+	probing   bool
+	calls     []providerCall
+	next      int
+	lastError string
+}
+
+func newProviderBreaker(config *Config) *providerBreaker {
+	b := &providerBreaker{
+		threshold: defaultBreakerThreshold,
+		cooldown:  defaultBreakerCooldown,
+		state:     BreakerClosed,
+		calls:     make([]providerCall, 0, providerStatsWindow),
+	}
+	if config.BreakerThreshold > 0 {
+		b.threshold = config.BreakerThreshold
+	}
+	if config.BreakerCooldownSeconds > 0 {
+		b.cooldown = time.Duration(config.BreakerCooldownSeconds) * time.Second
+	}
+	return b
+}
+
+// allow reports whether a call may go ahead, or how long until the next probe when it may not
+func (b *providerBreaker) allow(now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if now.Before(b.openUntil) {
+			return false, b.openUntil.Sub(now)
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true, 0
+	case BreakerHalfOpen:
+		if b.probing {
+			return false, b.cooldown
+		}
+		b.probing = true
+		return true, 0
+	}
+	return true, 0
+}
+
+// record adds the outcome of an allowed call. Errors that are not a ProviderError, such as cancellation by the
+// caller, end a probe without counting either way.
+func (b *providerBreaker) record(now time.Time, latency time.Duration, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasProbe := b.probing
+	b.probing = false
+
+	var providerErr *ProviderError
+	if err != nil && !errors.As(err, &providerErr) {
+		return
+	}
+
+	call := providerCall{latency: latency, failed: err != nil}
+	if len(b.calls) < providerStatsWindow {
+		b.calls = append(b.calls, call)
+	} else {
+		b.calls[b.next] = call
+	}
+	b.next = (b.next + 1) % providerStatsWindow
+
+	if err == nil {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+	b.lastError = err.Error()
+	if !providerErr.tripsBreaker() {
+		if wasProbe {
+			b.state = BreakerClosed
+		}
+		return
+	}
+
+	b.failures++
+	// A Retry-After names its own wait, so it opens the breaker for that long even below the threshold
+	if wasProbe || b.failures >= b.threshold || providerErr.RetryAfter > 0 {
+		wait := b.cooldown
+		if providerErr.RetryAfter > 0 {
+			wait = providerErr.RetryAfter
+		}
+		b.state = BreakerOpen
+		b.openUntil = now.Add(wait)
+	}
+}
+
+func (b *providerBreaker) stats(provider string) ProviderStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := ProviderStats{Provider: provider, State: b.state, Calls: len(b.calls), LastError: b.lastError}
+	if b.state == BreakerOpen {
+		openUntil := b.openUntil
+		s.OpenUntil = &openUntil
+	}
+	if len(b.calls) == 0 {
+		return s
+	}
+
+	latencies := make([]time.Duration, 0, len(b.calls))
+	var total time.Duration
+	for _, call := range b.calls {
+		if call.failed {
+			s.Errors++
+		}
+		total += call.latency
+		latencies = append(latencies, call.latency)
+	}
+	slices.Sort(latencies)
+	s.ErrorRate = float64(s.Errors) / float64(len(b.calls))
+	s.AvgLatencyMS = (total / time.Duration(len(b.calls))).Milliseconds()
+	s.P95LatencyMS = latencies[(len(latencies)-1)*95/100].Milliseconds()
+	return s
+}
@@ -0,0 +1,203 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// breakerStep either asks the breaker for a call (record false) or records the outcome of one, at a time relative
+// to the start of the test
+type breakerStep struct {
+	at          time.Duration
+	record      bool
+	err         error
+	wantAllowed bool
+	// wantWait is checked on denied calls when set
+	wantWait  time.Duration
+	wantState string
+}
+
+func TestProviderBreaker(t *testing.T) {
+	unavailable := &ProviderError{Provider: "p", Kind: ProviderErrUnavailable, Err: errors.New("503")}
+	badRequest := &ProviderError{Provider: "p", Kind: ProviderErrBadRequest, Err: errors.New("400")}
+	schema := &ProviderError{Provider: "p", Kind: ProviderErrSchema, Err: errors.New("not an array")}
+	rateLimited := &ProviderError{Provider: "p", Kind: ProviderErrRateLimited, RetryAfter: time.Minute, Err: errors.New("429")}
+	// trip opens the breaker at time 0 with the threshold of 3 used by every case
+	trip := []breakerStep{
+		{record: true, err: unavailable, wantState: BreakerClosed},
+		{record: true, err: unavailable, wantState: BreakerClosed},
+		{record: true, err: unavailable, wantState: BreakerOpen},
+	}
+
+	tests := []struct {
+		name  string
+		steps []breakerStep
+	}{
+		{
+			name: "closed allows calls",
+			steps: []breakerStep{
+				{wantAllowed: true, wantState: BreakerClosed},
+				{record: true, wantState: BreakerClosed},
+			},
+		},
+		{
+			name: "opens after threshold consecutive failures",
+			steps: append(trip[:3:3],
+				breakerStep{at: time.Second, wantWait: 9 * time.Second, wantState: BreakerOpen},
+			),
+		},
+		{
+			name: "success resets the failure count",
+			steps: []breakerStep{
+				{record: true, err: unavailable, wantState: BreakerClosed},
+				{record: true, err: unavailable, wantState: BreakerClosed},
+				{record: true, wantState: BreakerClosed},
+				{record: true, err: unavailable, wantState: BreakerClosed},
+				{record: true, err: unavailable, wantState: BreakerClosed},
+				{wantAllowed: true, wantState: BreakerClosed},
+			},
+		},
+		{
+			name: "request errors do not count",
+			steps: []breakerStep{
+				{record: true, err: badRequest, wantState: BreakerClosed},
+				{record: true, err: schema, wantState: BreakerClosed},
+				{record: true, err: badRequest, wantState: BreakerClosed},
+				{record: true, err: schema, wantState: BreakerClosed},
+				{wantAllowed: true, wantState: BreakerClosed},
+			},
+		},
+		{
+			name: "cancellation does not count",
+			steps: []breakerStep{
+				{record: true, err: context.Canceled, wantState: BreakerClosed},
+				{record: true, err: context.Canceled, wantState: BreakerClosed},
+				{record: true, err: context.Canceled, wantState: BreakerClosed},
+				{wantAllowed: true, wantState: BreakerClosed},
+			},
+		},
+		{
+			name: "Retry-After opens below the threshold for its duration",
+			steps: []breakerStep{
+				{record: true, err: rateLimited, wantState: BreakerOpen},
+				{at: 30 * time.Second, wantWait: 30 * time.Second, wantState: BreakerOpen},
+				{at: time.Minute, wantAllowed: true, wantState: BreakerHalfOpen},
+			},
+		},
+		{
+			name: "successful probe closes",
+			steps: append(trip[:3:3],
+				breakerStep{at: 10 * time.Second, wantAllowed: true, wantState: BreakerHalfOpen},
+				// A single probe is in flight at a time
+				breakerStep{at: 10 * time.Second, wantWait: 10 * time.Second, wantState: BreakerHalfOpen},
+				breakerStep{at: 11 * time.Second, record: true, wantState: BreakerClosed},
+				breakerStep{at: 11 * time.Second, wantAllowed: true, wantState: BreakerClosed},
+			),
+		},
+		{
+			name: "failed probe opens for another cooldown",
+			steps: append(trip[:3:3],
+				breakerStep{at: 10 * time.Second, wantAllowed: true, wantState: BreakerHalfOpen},
+				breakerStep{at: 11 * time.Second, record: true, err: unavailable, wantState: BreakerOpen},
+				breakerStep{at: 20 * time.Second, wantWait: time.Second, wantState: BreakerOpen},
+				breakerStep{at: 21 * time.Second, wantAllowed: true, wantState: BreakerHalfOpen},
+			),
+		},
+		{
+			name: "request error on the probe closes",
+			steps: append(trip[:3:3],
+				breakerStep{at: 10 * time.Second, wantAllowed: true, wantState: BreakerHalfOpen},
+				breakerStep{at: 11 * time.Second, record: true, err: badRequest, wantState: BreakerClosed},
+			),
+		},
+		{
+			name: "cancelled probe lets the next call probe",
+			steps: append(trip[:3:3],
+				breakerStep{at: 10 * time.Second, wantAllowed: true, wantState: BreakerHalfOpen},
+				breakerStep{at: 11 * time.Second, record: true, err: context.Canceled, wantState: BreakerHalfOpen},
+				breakerStep{at: 11 * time.Second, wantAllowed: true, wantState: BreakerHalfOpen},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newProviderBreaker(&Config{BreakerThreshold: 3, BreakerCooldownSeconds: 10})
+			start := time.Now()
+			for i, step := range tt.steps {
+				now := start.Add(step.at)
+				if step.record {
+					b.record(now, 10*time.Millisecond, step.err)
+				} else {
+					allowed, wait := b.allow(now)
+					if allowed != step.wantAllowed {
+						t.Fatalf("step %d: allow = %v, want %v", i, allowed, step.wantAllowed)
+					}
+					if !allowed && step.wantWait != 0 && wait != step.wantWait {
+						t.Errorf("step %d: wait = %v, want %v", i, wait, step.wantWait)
+					}
+				}
+				if b.state != step.wantState {
+					t.Fatalf("step %d: state = %s, want %s", i, b.state, step.wantState)
+				}
+			}
+		})
+	}
+}
+
+func TestProviderBreakerStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		calls     int
+		wantCalls int
+		// every fourth call fails
+		wantErrors int
+		wantAvgMS  int64
+		wantP95MS  int64
+	}{
+		{name: "no calls"},
+		{name: "partial window", calls: 20, wantCalls: 20, wantErrors: 5, wantAvgMS: 9, wantP95MS: 18},
+		{name: "window rolls over", calls: 150, wantCalls: providerStatsWindow, wantErrors: 25, wantAvgMS: 99, wantP95MS: 144},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newProviderBreaker(&Config{BreakerThreshold: 1000})
+			now := time.Now()
+			for i := range tt.calls {
+				var err error
+				if i%4 == 3 {
+					err = &ProviderError{Provider: "p", Kind: ProviderErrUnavailable, Err: errors.New("503")}
+				}
+				// The i-th call takes i milliseconds
+				b.record(now, time.Duration(i)*time.Millisecond, err)
+			}
+			s := b.stats("p")
+			if s.Provider != "p" || s.State != BreakerClosed || s.OpenUntil != nil {
+				t.Errorf("stats = %+v, want a closed breaker for p", s)
+			}
+			if s.Calls != tt.wantCalls || s.Errors != tt.wantErrors {
+				t.Errorf("calls, errors = %d, %d, want %d, %d", s.Calls, s.Errors, tt.wantCalls, tt.wantErrors)
+			}
+			if tt.wantCalls > 0 && s.ErrorRate != float64(tt.wantErrors)/float64(tt.wantCalls) {
+				t.Errorf("ErrorRate = %v, want %v", s.ErrorRate, float64(tt.wantErrors)/float64(tt.wantCalls))
+			}
+			if s.AvgLatencyMS != tt.wantAvgMS || s.P95LatencyMS != tt.wantP95MS {
+				t.Errorf("latency avg, p95 = %d, %d, want %d, %d", s.AvgLatencyMS, s.P95LatencyMS, tt.wantAvgMS, tt.wantP95MS)
+			}
+		})
+	}
+}
+
+func TestProviderBreakerStatsOpen(t *testing.T) {
+	b := newProviderBreaker(&Config{BreakerThreshold: 1, BreakerCooldownSeconds: 10})
+	now := time.Now()
+	b.record(now, time.Millisecond, &ProviderError{Provider: "p", Kind: ProviderErrTimeout, Err: errors.New("deadline")})
+	s := b.stats("p")
+	if s.State != BreakerOpen || s.OpenUntil == nil || !s.OpenUntil.Equal(now.Add(10*time.Second)) {
+		t.Errorf("stats = %+v, want open until %v", s, now.Add(10*time.Second))
+	}
+	if s.LastError == "" {
+		t.Error("LastError is empty")
+	}
+}
@@ -0,0 +1,202 @@
+package vertex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const anthropicVersion = "2023-06-01"
+
+// ClaudeProvider calls the Anthropic Messages API. Structured output is a forced tool call whose input schema is
+// the requested JSON schema, so the tool input is the answer.
+type ClaudeProvider struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// claudeMessage is the subset of a Messages API response the provider reads
+type claudeMessage struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Name  string          `json:"name"`
+		Input json.RawMessage `json:"input"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (p *ClaudeProvider) Name() string { return "claude" }
+
+func (p *ClaudeProvider) CheckQuerySafety(ctx context.Context, prompt string) (bool, error) {
+	if p.apiKey == "" {
+		return false, &ProviderError{Provider: p.Name(), Kind: ProviderErrAuth, Err: errors.New("api key missing")}
+	}
+	body := map[string]any{
+		"model":       p.model,
+		"system":      "Answer only YES or NO. This is a hotel review relevance safety check.",
+		"messages":    []map[string]string{{"role": "user", "content": prompt}},
+		"temperature": 0.0,
+		"max_tokens":  32,
+	}
+	msg, err := p.createMessage(ctx, body)
+	if err != nil {
+		return false, err
+	}
+	var text strings.Builder
+	for _, block := range msg.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return strings.Contains(strings.ToLower(text.String()), "yes"), nil
+}
+
+func (p *ClaudeProvider) PromptCompletion(ctx context.Context, prompt string) (CompletionResult, error) {
+	return p.CompleteJSON(ctx, prompt, "hotel_review_completion", completionJSONSchema())
+}
+
+// CompleteJSON forces a call of a tool taking schema as input, the tool input is the answer
+func (p *ClaudeProvider) CompleteJSON(ctx context.Context, prompt, schemaName string, schema map[string]any) (CompletionResult, error) {
+	if p.apiKey == "" {
+		return CompletionResult{}, &ProviderError{Provider: p.Name(), Kind: ProviderErrAuth, Err: errors.New("api key missing")}
+	}
+	inputSchema, wrapped := toolInputSchema(schema)
+
+	body := map[string]any{
+		"model":       p.model,
+		"system":      "Answer by calling the " + schemaName + " tool with input matching its schema.",
+		"messages":    []map[string]string{{"role": "user", "content": prompt}},
+		"temperature": 0.2,
+		"max_tokens":  16384,
+		"tools": []map[string]any{{
+			"name":         schemaName,
+			"description":  "Records the answer to the user's request.",
+			"input_schema": inputSchema,
+		}},
+		"tool_choice": map[string]any{"type": "tool", "name": schemaName},
+	}
+	msg, err := p.createMessage(ctx, body)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+
+	for _, block := range msg.Content {
+		if block.Type != "tool_use" || block.Name != schemaName {
+			continue
+		}
+		content, err := toolInputContent(block.Input, wrapped)
+		if err != nil {
+			return CompletionResult{}, &ProviderError{Provider: p.Name(), Kind: ProviderErrSchema, Err: fmt.Errorf("invalid tool input: %w", err)}
+		}
+		return CompletionResult{
+			Content: content,
+			Usage: TokenUsage{
+				PromptTokens:     msg.Usage.InputTokens,
+				CompletionTokens: msg.Usage.OutputTokens,
+				TotalTokens:      msg.Usage.InputTokens + msg.Usage.OutputTokens,
+			},
+			Model: firstNonEmpty(msg.Model, p.model),
+		}, nil
+	}
+	return CompletionResult{}, &ProviderError{Provider: p.Name(), Kind: ProviderErrSchema, Err: fmt.Errorf("no %s tool call returned", schemaName)}
+}
+
+// Ping retrieves the model, which fails for unknown models and invalid keys
+func (p *ClaudeProvider) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.baseURL, "/")+"/v1/models/"+p.model, nil)
+	if err != nil {
+		return err
+	}
+	p.setHeaders(req)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBytes, _ := io.ReadAll(resp.Body)
+		return newHTTPProviderError(p.Name(), resp, respBytes)
+	}
+	return nil
+}
+
+func (p *ClaudeProvider) createMessage(ctx context.Context, body map[string]any) (*claudeMessage, error) {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(p.baseURL, "/")+"/v1/messages", bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	p.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Overloaded (529) is a server error, so it counts as unavailable
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newHTTPProviderError(p.Name(), resp, respBytes)
+	}
+
+	var msg claudeMessage
+	if err := json.Unmarshal(respBytes, &msg); err != nil {
+		return nil, newInvalidResponseError(p.Name(), err)
+	}
+	return &msg, nil
+}
+
+func (p *ClaudeProvider) setHeaders(req *http.Request) {
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+}
+
+// toolInputSchema adapts a JSON schema for the input of a forced tool call. Tool inputs must be objects, so other
+// schemas such as the completion array are wrapped in a "result" property.
+func toolInputSchema(schema map[string]any) (map[string]any, bool) {
+	if schema["type"] == "object" {
+		return schema, false
+	}
+	return map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"result": schema},
+		"required":   []string{"result"},
+	}, true
+}
+
+// toolInputContent is the answer held by a tool input, unwrapping the "result" property added by toolInputSchema
+func toolInputContent(input json.RawMessage, wrapped bool) (string, error) {
+	if !wrapped {
+		return string(input), nil
+	}
+	var parsed struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(input, &parsed); err != nil {
+		return "", err
+	}
+	return string(parsed.Result), nil
+}
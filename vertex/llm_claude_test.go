@@ -0,0 +1,169 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// claudeServer answers every Messages API call with status, headers and body, and keeps the last request body
+func claudeServer(t *testing.T, status int, header map[string]string, body string) (*ClaudeProvider, *map[string]any) {
+	t.Helper()
+	var request map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("unexpected request %s %s with headers %v", r.Method, r.URL.Path, r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		for k, v := range header {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return &ClaudeProvider{apiKey: "key", baseURL: srv.URL, model: "claude-test", client: srv.Client()}, &request
+}
+
+func TestClaudeProviderPromptCompletion(t *testing.T) {
+	const items = `[{"Hotel":"Grand Hotel","City":"Paris","Review":"Quiet","Rating":4.5,"Distance":0.1,"Address":"1 Rue"}]`
+	tests := []struct {
+		name        string
+		body        string
+		wantContent string
+		wantModel   string
+		wantKind    ProviderErrorKind
+	}{
+		{
+			name: "tool input is unwrapped into the items",
+			body: `{"model":"claude-test-20250101","content":[
+				{"type":"text","text":"Here are the hotels."},
+				{"type":"tool_use","name":"hotel_review_completion","input":{"result":` + items + `}}
+			],"usage":{"input_tokens":120,"output_tokens":30}}`,
+			wantContent: items,
+			wantModel:   "claude-test-20250101",
+		},
+		{
+			name:        "model falls back to the configured one",
+			body:        `{"content":[{"type":"tool_use","name":"hotel_review_completion","input":{"result":[]}}]}`,
+			wantContent: `[]`,
+			wantModel:   "claude-test",
+		},
+		{
+			name:     "no tool call",
+			body:     `{"content":[{"type":"text","text":"I cannot help with that."}]}`,
+			wantKind: ProviderErrSchema,
+		},
+		{
+			name:     "call of another tool",
+			body:     `{"content":[{"type":"tool_use","name":"other","input":{"result":[]}}]}`,
+			wantKind: ProviderErrSchema,
+		},
+		{
+			name:     "tool input without the result property",
+			body:     `{"content":[{"type":"tool_use","name":"hotel_review_completion","input":[1]}]}`,
+			wantKind: ProviderErrSchema,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, request := claudeServer(t, http.StatusOK, nil, tt.body)
+			result, err := provider.PromptCompletion(context.Background(), "hotels in Paris")
+			if tt.wantKind != "" {
+				var providerErr *ProviderError
+				if !errors.As(err, &providerErr) || providerErr.Kind != tt.wantKind {
+					t.Fatalf("PromptCompletion error = %v, want kind %s", err, tt.wantKind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PromptCompletion: %v", err)
+			}
+			if result.Content != tt.wantContent {
+				t.Errorf("Content = %s, want %s", result.Content, tt.wantContent)
+			}
+			if result.Model != tt.wantModel {
+				t.Errorf("Model = %q, want %q", result.Model, tt.wantModel)
+			}
+			choice, _ := (*request)["tool_choice"].(map[string]any)
+			if choice["type"] != "tool" || choice["name"] != "hotel_review_completion" {
+				t.Errorf("tool_choice = %v, want the completion tool forced", (*request)["tool_choice"])
+			}
+		})
+	}
+}
+
+func TestClaudeProviderUsage(t *testing.T) {
+	provider, _ := claudeServer(t, http.StatusOK, nil, `{"content":[
+		{"type":"tool_use","name":"hotel_review_completion","input":{"result":[]}}
+	],"usage":{"input_tokens":120,"output_tokens":30}}`)
+	result, err := provider.PromptCompletion(context.Background(), "hotels in Paris")
+	if err != nil {
+		t.Fatalf("PromptCompletion: %v", err)
+	}
+	want := TokenUsage{PromptTokens: 120, CompletionTokens: 30, TotalTokens: 150}
+	if result.Usage != want {
+		t.Errorf("Usage = %+v, want %+v", result.Usage, want)
+	}
+}
+
+func TestClaudeProviderErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		header         map[string]string
+		wantKind       ProviderErrorKind
+		wantRetryAfter time.Duration
+		wantRetryable  bool
+	}{
+		{name: "rate limited", status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "7"}, wantKind: ProviderErrRateLimited, wantRetryAfter: 7 * time.Second, wantRetryable: true},
+		{name: "overloaded", status: 529, wantKind: ProviderErrUnavailable, wantRetryable: true},
+		{name: "internal error", status: http.StatusInternalServerError, wantKind: ProviderErrUnavailable, wantRetryable: true},
+		{name: "service unavailable", status: http.StatusServiceUnavailable, header: map[string]string{"Retry-After": "2"}, wantKind: ProviderErrUnavailable, wantRetryAfter: 2 * time.Second, wantRetryable: true},
+		{name: "invalid key", status: http.StatusUnauthorized, wantKind: ProviderErrAuth, wantRetryable: true},
+		{name: "bad request", status: http.StatusBadRequest, wantKind: ProviderErrBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, _ := claudeServer(t, tt.status, tt.header, `{"type":"error","error":{"type":"api_error","message":"failed"}}`)
+			_, err := provider.PromptCompletion(context.Background(), "hotels in Paris")
+			var providerErr *ProviderError
+			if !errors.As(err, &providerErr) {
+				t.Fatalf("PromptCompletion error = %v, want a ProviderError", err)
+			}
+			if providerErr.Provider != "claude" || providerErr.Kind != tt.wantKind || providerErr.StatusCode != tt.status {
+				t.Errorf("error = %v, want claude %s (%d)", providerErr, tt.wantKind, tt.status)
+			}
+			if providerErr.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("RetryAfter = %v, want %v", providerErr.RetryAfter, tt.wantRetryAfter)
+			}
+			if providerErr.Retryable() != tt.wantRetryable {
+				t.Errorf("Retryable = %v, want %v", providerErr.Retryable(), tt.wantRetryable)
+			}
+		})
+	}
+}
+
+func TestClaudeProviderMissingKey(t *testing.T) {
+	provider := &ClaudeProvider{baseURL: "http://127.0.0.1:0", model: "claude-test", client: http.DefaultClient}
+	_, err := provider.PromptCompletion(context.Background(), "hotels in Paris")
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Kind != ProviderErrAuth {
+		t.Errorf("PromptCompletion error = %v, want an auth ProviderError", err)
+	}
+}
+
+func TestClaudeProviderInvalidResponse(t *testing.T) {
+	provider, _ := claudeServer(t, http.StatusOK, nil, `{"content":[{"type":"tool_use"`)
+	_, err := provider.PromptCompletion(context.Background(), "hotels in Paris")
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Kind != ProviderErrInvalidResponse || !providerErr.Retryable() {
+		t.Errorf("PromptCompletion error = %v, want a retryable invalid_response ProviderError", err)
+	}
+}
@@ -0,0 +1,152 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// ProviderErrorKind classifies a failed LLM provider call
+type ProviderErrorKind string
+
+const (
+	ProviderErrRateLimited ProviderErrorKind = "rate_limited"
+	ProviderErrTimeout     ProviderErrorKind = "timeout"
+	ProviderErrAuth        ProviderErrorKind = "auth"
+	ProviderErrBadRequest  ProviderErrorKind = "bad_request"
+	// ProviderErrSchema is an answer that does not match the requested JSON schema
+	ProviderErrSchema ProviderErrorKind = "schema_violation"
+	// ProviderErrUnavailable covers server errors, overload and network failures
+	ProviderErrUnavailable ProviderErrorKind = "unavailable"
+	// ProviderErrCircuitOpen is a provider skipped by its circuit breaker
+	ProviderErrCircuitOpen ProviderErrorKind = "circuit_open"
+	// ProviderErrInvalidResponse is a successful response whose body cannot be decoded. Another provider may still
+	// answer, but the failure does not count against the provider's breaker.
+	ProviderErrInvalidResponse ProviderErrorKind = "invalid_response"
+)
+
+// ProviderError is a failed call to one provider. StatusCode is the HTTP status when the provider answered, and
+// RetryAfter its Retry-After header or, for an open circuit, the time left until the next probe.
+type ProviderError struct {
+	Provider   string
+	Kind       ProviderErrorKind
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s %s (%d): %v", e.Provider, e.Kind, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %v", e.Provider, e.Kind, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error { return e.Err }
+
+// Retryable reports whether another provider may succeed where this one failed. A bad request would fail the same
+// way everywhere.
+func (e *ProviderError) Retryable() bool {
+	return e.Kind != ProviderErrBadRequest
+}
+
+// tripsBreaker reports whether the failure says something about the provider's health rather than the request
+func (e *ProviderError) tripsBreaker() bool {
+	switch e.Kind {
+	case ProviderErrRateLimited, ProviderErrTimeout, ProviderErrAuth, ProviderErrUnavailable:
+		return true
+	}
+	return false
+}
+
+// newHTTPProviderError classifies a non-2xx response of an HTTP provider
+func newHTTPProviderError(provider string, resp *http.Response, body []byte) *ProviderError {
+	return &ProviderError{
+		Provider:   provider,
+		Kind:       providerErrorKindForStatus(resp.StatusCode),
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		Err:        fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body))),
+	}
+}
+
+// newInvalidResponseError is a 2xx response of an HTTP provider whose body could not be decoded
+func newInvalidResponseError(provider string, err error) *ProviderError {
+	return &ProviderError{Provider: provider, Kind: ProviderErrInvalidResponse, Err: fmt.Errorf("invalid response body: %w", err)}
+}
+
+func providerErrorKindForStatus(code int) ProviderErrorKind {
+	switch {
+	case code == http.StatusTooManyRequests:
+		return ProviderErrRateLimited
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return ProviderErrAuth
+	case code == http.StatusRequestTimeout || code == http.StatusGatewayTimeout:
+		return ProviderErrTimeout
+	case code >= 400 && code < 500:
+		return ProviderErrBadRequest
+	default:
+		return ProviderErrUnavailable
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(header string) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// classifyProviderError types the error of a provider call. Errors already typed by the provider are kept, SDK
+// errors are classified by their HTTP status and transport errors by timeout. Cancellation is returned as is,
+// since it comes from the caller rather than the provider.
+func classifyProviderError(provider string, err error) error {
+	var typed *ProviderError
+	if err == nil || errors.As(err, &typed) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	e := &ProviderError{Provider: provider, Kind: ProviderErrUnavailable, Err: err}
+	var apiErr genai.APIError
+	var apiErrPtr *genai.APIError
+	// AWS SDK response errors expose the status this way
+	var statusErr interface{ HTTPStatusCode() int }
+	var netErr net.Error
+	switch {
+	case errors.As(err, &apiErr):
+		e.StatusCode = apiErr.Code
+	case errors.As(err, &apiErrPtr):
+		e.StatusCode = apiErrPtr.Code
+	case errors.As(err, &statusErr):
+		e.StatusCode = statusErr.HTTPStatusCode()
+	case errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout():
+		e.Kind = ProviderErrTimeout
+	}
+	if e.StatusCode != 0 {
+		e.Kind = providerErrorKindForStatus(e.StatusCode)
+	}
+	return e
+}
+
+// isRetryableLLMError reports whether the chain should fall back to the next provider after err
+func isRetryableLLMError(err error) bool {
+	var e *ProviderError
+	return errors.As(err, &e) && e.Retryable()
+}
@@ -0,0 +1,151 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// statusError is an SDK error exposing its HTTP status the way AWS response errors do
+type statusError int
+
+func (e statusError) Error() string       { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) HTTPStatusCode() int { return int(e) }
+
+// timeoutError is a transport error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyProviderError(t *testing.T) {
+	typed := &ProviderError{Provider: "claude", Kind: ProviderErrSchema, Err: errors.New("no tool call")}
+	tests := []struct {
+		name       string
+		err        error
+		wantKind   ProviderErrorKind
+		wantStatus int
+		// wantSame is an error returned unchanged
+		wantSame bool
+	}{
+		{name: "nil", err: nil, wantSame: true},
+		{name: "already typed", err: fmt.Errorf("call: %w", typed), wantSame: true},
+		{name: "cancelled by the caller", err: fmt.Errorf("call: %w", context.Canceled), wantSame: true},
+		{name: "genai value", err: genai.APIError{Code: http.StatusTooManyRequests}, wantKind: ProviderErrRateLimited, wantStatus: 429},
+		{name: "genai pointer", err: &genai.APIError{Code: http.StatusForbidden}, wantKind: ProviderErrAuth, wantStatus: 403},
+		{name: "wrapped genai", err: fmt.Errorf("generate: %w", genai.APIError{Code: http.StatusInternalServerError}), wantKind: ProviderErrUnavailable, wantStatus: 500},
+		{name: "status error", err: statusError(http.StatusBadRequest), wantKind: ProviderErrBadRequest, wantStatus: 400},
+		{name: "deadline", err: fmt.Errorf("call: %w", context.DeadlineExceeded), wantKind: ProviderErrTimeout},
+		{name: "network timeout", err: timeoutError{}, wantKind: ProviderErrTimeout},
+		{name: "other error", err: errors.New("connection refused"), wantKind: ProviderErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyProviderError("vertex", tt.err)
+			if tt.wantSame {
+				if err != tt.err {
+					t.Errorf("classifyProviderError = %v, want %v unchanged", err, tt.err)
+				}
+				return
+			}
+			var providerErr *ProviderError
+			if !errors.As(err, &providerErr) {
+				t.Fatalf("classifyProviderError = %v, want a ProviderError", err)
+			}
+			if providerErr.Provider != "vertex" || providerErr.Kind != tt.wantKind || providerErr.StatusCode != tt.wantStatus {
+				t.Errorf("classifyProviderError = %v, want vertex %s (%d)", providerErr, tt.wantKind, tt.wantStatus)
+			}
+			if providerErr.Err == nil || providerErr.Err.Error() != tt.err.Error() {
+				t.Errorf("Err = %v, want %v", providerErr.Err, tt.err)
+			}
+		})
+	}
+}
+
+func TestProviderErrorKindForStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   ProviderErrorKind
+	}{
+		{status: http.StatusBadRequest, want: ProviderErrBadRequest},
+		{status: http.StatusUnauthorized, want: ProviderErrAuth},
+		{status: http.StatusForbidden, want: ProviderErrAuth},
+		{status: http.StatusNotFound, want: ProviderErrBadRequest},
+		{status: http.StatusRequestTimeout, want: ProviderErrTimeout},
+		{status: http.StatusTooManyRequests, want: ProviderErrRateLimited},
+		{status: http.StatusInternalServerError, want: ProviderErrUnavailable},
+		{status: http.StatusBadGateway, want: ProviderErrUnavailable},
+		{status: http.StatusGatewayTimeout, want: ProviderErrTimeout},
+		{status: 529, want: ProviderErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			if got := providerErrorKindForStatus(tt.status); got != tt.want {
+				t.Errorf("providerErrorKindForStatus(%d) = %s, want %s", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		// want is checked within a second for HTTP dates
+		want time.Duration
+	}{
+		{name: "missing", header: "", want: 0},
+		{name: "seconds", header: "30", want: 30 * time.Second},
+		{name: "padded seconds", header: " 5 ", want: 5 * time.Second},
+		{name: "zero", header: "0", want: 0},
+		{name: "negative", header: "-3", want: 0},
+		{name: "future date", header: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), want: time.Minute},
+		{name: "past date", header: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), want: 0},
+		{name: "garbage", header: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRetryAfter(tt.header)
+			if got < tt.want-time.Second || got > tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderErrorRetryable(t *testing.T) {
+	tests := []struct {
+		kind          ProviderErrorKind
+		wantRetryable bool
+		wantTrips     bool
+	}{
+		{kind: ProviderErrRateLimited, wantRetryable: true, wantTrips: true},
+		{kind: ProviderErrTimeout, wantRetryable: true, wantTrips: true},
+		{kind: ProviderErrAuth, wantRetryable: true, wantTrips: true},
+		{kind: ProviderErrUnavailable, wantRetryable: true, wantTrips: true},
+		{kind: ProviderErrBadRequest},
+		{kind: ProviderErrSchema, wantRetryable: true},
+		{kind: ProviderErrCircuitOpen, wantRetryable: true},
+		{kind: ProviderErrInvalidResponse, wantRetryable: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			e := &ProviderError{Provider: "p", Kind: tt.kind, Err: errors.New("failed")}
+			if e.Retryable() != tt.wantRetryable {
+				t.Errorf("Retryable = %v, want %v", e.Retryable(), tt.wantRetryable)
+			}
+			if e.tripsBreaker() != tt.wantTrips {
+				t.Errorf("tripsBreaker = %v, want %v", e.tripsBreaker(), tt.wantTrips)
+			}
+			if isRetryableLLMError(fmt.Errorf("completion: %w", e)) != tt.wantRetryable {
+				t.Errorf("isRetryableLLMError of the wrapped error = %v, want %v", !tt.wantRetryable, tt.wantRetryable)
+			}
+		})
+	}
+}
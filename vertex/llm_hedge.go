@@ -0,0 +1,83 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// hedgeOutcome is the result of one hedged provider call, at index in the chain
+type hedgeOutcome struct {
+	index int
+	resp  CompletionResult
+	err   error
+}
+
+// hedgedCompletion starts the first provider of chain and launches the next one whenever delay passes without an
+// answer, or right away when a provider fails. The first valid completion wins and cancels the calls still running.
+// A non-retryable failure, such as a bad request every provider would reject, launches no further provider, but the
+// calls already running may still win.
+func (r *CompletionRouter) hedgedCompletion(ctx context.Context, chain []LLMProvider, prompt string, delay time.Duration) (CompletionResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered so the calls cancelled after the winner can finish without a reader
+	outcomes := make(chan hedgeOutcome, len(chain))
+	next, running := 0, 0
+	stopped := false
+	var hedge <-chan time.Time
+	launch := func() {
+		index, provider := next, chain[next]
+		next++
+		running++
+		go func() {
+			resp, err := r.validCompletion(ctx, provider, prompt)
+			outcomes <- hedgeOutcome{index: index, resp: resp, err: err}
+		}()
+		hedge = nil
+		if next < len(chain) {
+			hedge = time.After(delay)
+		}
+	}
+
+	errs := make([]error, len(chain))
+	launch()
+	for running > 0 {
+		select {
+		case <-hedge:
+			launch()
+		case out := <-outcomes:
+			running--
+			if out.err == nil {
+				recordHedgeWinner(ctx, chain[out.index].Name(), out.index, next)
+				return out.resp, nil
+			}
+			errs[out.index] = out.err
+			if !isRetryableLLMError(out.err) {
+				stopped = true
+				hedge = nil
+			}
+			if !stopped && next < len(chain) {
+				launch()
+			}
+		}
+	}
+	return CompletionResult{}, fmt.Errorf("all completion providers failed: %w", errors.Join(errs...))
+}
+
+// recordHedgeWinner counts the provider that answered a hedged completion, with its position in the chain and how
+// many providers had been launched by then
+func recordHedgeWinner(ctx context.Context, provider string, position, launched int) {
+	meter := otel.Meter("vertex-search")
+	counter, _ := meter.Int64Counter("llm.hedge.winner")
+	counter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("provider", provider),
+		attribute.Int("position", position),
+		attribute.Int("launched", launched),
+	))
+}
@@ -0,0 +1,216 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeProvider answers after delay with err or an empty recommendation list, unless its call is cancelled first
+type fakeProvider struct {
+	name  string
+	delay time.Duration
+	err   error
+
+	calls atomic.Int32
+	// cancelled is closed when a call sees its context cancelled
+	cancelled chan struct{}
+}
+
+func newFakeProvider(name string, delay time.Duration, err error) *fakeProvider {
+	return &fakeProvider{name: name, delay: delay, err: err, cancelled: make(chan struct{})}
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) CheckQuerySafety(ctx context.Context, prompt string) (bool, error) {
+	return true, nil
+}
+
+func (p *fakeProvider) PromptCompletion(ctx context.Context, prompt string) (CompletionResult, error) {
+	p.calls.Add(1)
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		close(p.cancelled)
+		return CompletionResult{}, ctx.Err()
+	}
+	if p.err != nil {
+		return CompletionResult{}, p.err
+	}
+	return CompletionResult{Content: "[]", Model: p.name}, nil
+}
+
+func hedgeRouter(providers ...*fakeProvider) (*CompletionRouter, []LLMProvider) {
+	r := &CompletionRouter{config: &Config{}, breakers: map[string]*providerBreaker{}}
+	chain := make([]LLMProvider, len(providers))
+	for i, p := range providers {
+		r.breakers[p.name] = newProviderBreaker(&Config{BreakerThreshold: 1})
+		chain[i] = p
+	}
+	return r, chain
+}
+
+func TestHedgedCompletion(t *testing.T) {
+	unavailable := &ProviderError{Provider: "first", Kind: ProviderErrUnavailable, Err: errors.New("503")}
+	tests := []struct {
+		name      string
+		providers []*fakeProvider
+		delay     time.Duration
+		wantModel string
+		// wantCalls is the number of calls of each provider
+		wantCalls []int32
+		wantKinds []ProviderErrorKind
+	}{
+		{
+			name:      "first answer within the delay",
+			providers: []*fakeProvider{newFakeProvider("first", 0, nil), newFakeProvider("second", 0, nil)},
+			delay:     time.Hour,
+			wantModel: "first",
+			wantCalls: []int32{1, 0},
+		},
+		{
+			name:      "failure launches the next provider without waiting",
+			providers: []*fakeProvider{newFakeProvider("first", 0, unavailable), newFakeProvider("second", 0, nil)},
+			delay:     time.Hour,
+			wantModel: "second",
+			wantCalls: []int32{1, 1},
+		},
+		{
+			name: "bad request stops the hedging",
+			providers: []*fakeProvider{
+				newFakeProvider("first", 0, &ProviderError{Provider: "first", Kind: ProviderErrBadRequest, Err: errors.New("400")}),
+				newFakeProvider("second", 0, nil),
+			},
+			delay:     time.Hour,
+			wantCalls: []int32{1, 0},
+			wantKinds: []ProviderErrorKind{ProviderErrBadRequest},
+		},
+		{
+			name: "invalid response falls back to the next provider",
+			providers: []*fakeProvider{
+				newFakeProvider("first", 0, newInvalidResponseError("first", errors.New("unexpected end of JSON input"))),
+				newFakeProvider("second", 0, nil),
+			},
+			delay:     time.Hour,
+			wantModel: "second",
+			wantCalls: []int32{1, 1},
+		},
+		{
+			name: "primary answers after the backup returns an invalid response",
+			providers: []*fakeProvider{
+				newFakeProvider("first", 50*time.Millisecond, nil),
+				newFakeProvider("second", 0, newInvalidResponseError("second", errors.New("unexpected end of JSON input"))),
+				newFakeProvider("third", time.Hour, nil),
+			},
+			delay:     10 * time.Millisecond,
+			wantModel: "first",
+			wantCalls: []int32{1, 1, 1},
+		},
+		{
+			name: "primary answers after the backup's bad request",
+			providers: []*fakeProvider{
+				newFakeProvider("first", 50*time.Millisecond, nil),
+				newFakeProvider("second", 0, &ProviderError{Provider: "second", Kind: ProviderErrBadRequest, Err: errors.New("400")}),
+				newFakeProvider("third", 0, nil),
+			},
+			delay:     10 * time.Millisecond,
+			wantModel: "first",
+			wantCalls: []int32{1, 1, 0},
+		},
+		{
+			name: "every provider fails",
+			providers: []*fakeProvider{
+				newFakeProvider("first", 0, unavailable),
+				newFakeProvider("second", 0, &ProviderError{Provider: "second", Kind: ProviderErrTimeout, Err: errors.New("deadline")}),
+			},
+			delay:     time.Hour,
+			wantCalls: []int32{1, 1},
+			wantKinds: []ProviderErrorKind{ProviderErrUnavailable, ProviderErrTimeout},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, chain := hedgeRouter(tt.providers...)
+			resp, err := r.hedgedCompletion(context.Background(), chain, "prompt", tt.delay)
+			if tt.wantKinds != nil {
+				var kinds []ProviderErrorKind
+				for _, e := range providerErrors(err) {
+					kinds = append(kinds, e.Kind)
+				}
+				if len(kinds) != len(tt.wantKinds) {
+					t.Fatalf("error kinds = %v (%v), want %v", kinds, err, tt.wantKinds)
+				}
+				for i := range kinds {
+					if kinds[i] != tt.wantKinds[i] {
+						t.Errorf("error kinds = %v, want %v", kinds, tt.wantKinds)
+					}
+				}
+			} else if err != nil {
+				t.Fatalf("hedgedCompletion: %v", err)
+			}
+			if resp.Model != tt.wantModel {
+				t.Errorf("winner = %q, want %q", resp.Model, tt.wantModel)
+			}
+			for i, p := range tt.providers {
+				if got := p.calls.Load(); got != tt.wantCalls[i] {
+					t.Errorf("%s called %d times, want %d", p.name, got, tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHedgedCompletionCancelsSlowerCalls(t *testing.T) {
+	slow := newFakeProvider("slow", time.Hour, nil)
+	fast := newFakeProvider("fast", 0, nil)
+	r, chain := hedgeRouter(slow, fast)
+
+	resp, err := r.hedgedCompletion(context.Background(), chain, "prompt", 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("hedgedCompletion: %v", err)
+	}
+	if resp.Model != "fast" {
+		t.Errorf("winner = %q, want fast", resp.Model)
+	}
+	select {
+	case <-slow.cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow call was not cancelled after the hedge won")
+	}
+	// The cancellation comes from the router, so it says nothing about the slow provider's health
+	if state := r.breakers["slow"].stats("slow").State; state != BreakerClosed {
+		t.Errorf("slow breaker = %s, want closed", state)
+	}
+}
+
+func TestHedgedCompletionCallerCancellation(t *testing.T) {
+	first := newFakeProvider("first", time.Hour, nil)
+	second := newFakeProvider("second", time.Hour, nil)
+	r, chain := hedgeRouter(first, second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := r.hedgedCompletion(ctx, chain, "prompt", 10*time.Millisecond); err == nil {
+		t.Fatal("hedgedCompletion succeeded after the caller's deadline")
+	}
+	for _, p := range []*fakeProvider{first, second} {
+		select {
+		case <-p.cancelled:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s call was not cancelled", p.name)
+		}
+	}
+}
+
+func TestInvalidResponseDoesNotTripBreaker(t *testing.T) {
+	b := newProviderBreaker(&Config{BreakerThreshold: 1})
+	for range 3 {
+		b.record(time.Now(), time.Millisecond, newInvalidResponseError("p", errors.New("unexpected end of JSON input")))
+	}
+	if ok, _ := b.allow(time.Now()); !ok || b.state != BreakerClosed {
+		t.Errorf("breaker = %s after invalid responses, want closed", b.state)
+	}
+}
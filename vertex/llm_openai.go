@@ -0,0 +1,271 @@
+package vertex
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAICompatibleProvider calls a /v1/chat/completions API. It backs the built-in Grok and OpenAI providers and
+// the openai_compatible entries of config.yaml, such as vLLM, Ollama or LM Studio for offline development.
+type OpenAICompatibleProvider struct {
+	name       string
+	apiKey     string
+	baseURL    string
+	model      string
+	authHeader string
+	jsonSchema bool
+	maxTokens  int
+	client     *http.Client
+}
+
+// chatCompletion is the subset of a chat completion response the provider reads
+type chatCompletion struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage *TokenUsage `json:"usage"`
+}
+
+func newOpenAICompatibleProvider(name string, c OpenAICompatibleConfig) *OpenAICompatibleProvider {
+	// Servers are often documented with the /v1 suffix, the provider adds it to each path
+	baseURL := strings.TrimSuffix(strings.TrimRight(c.BaseURL, "/"), "/v1")
+	return &OpenAICompatibleProvider{
+		name:       name,
+		apiKey:     c.APIKey,
+		baseURL:    baseURL,
+		model:      c.Model,
+		authHeader: firstNonEmpty(c.AuthHeader, "Authorization"),
+		jsonSchema: c.JSONSchema,
+		maxTokens:  c.MaxTokens,
+		client:     &http.Client{},
+	}
+}
+
+func (p *OpenAICompatibleProvider) Name() string { return p.name }
+
+func (p *OpenAICompatibleProvider) CheckQuerySafety(ctx context.Context, prompt string) (bool, error) {
+	body := map[string]any{
+		"model": p.model,
+		"messages": []map[string]string{
+			{"role": "system", "content": "Answer only YES or NO. This is a hotel review relevance safety check."},
+			{"role": "user", "content": prompt},
+		},
+		"temperature": 0.0,
+		"max_tokens":  32,
+	}
+	content, _, err := p.doChatCompletion(ctx, body)
+	if err != nil {
+		return false, err
+	}
+	text := strings.ToLower(strings.TrimSpace(content))
+	return strings.Contains(text, "yes"), nil
+}
+
+func (p *OpenAICompatibleProvider) PromptCompletion(ctx context.Context, prompt string) (CompletionResult, error) {
+	content, usage, err := p.doChatCompletion(ctx, p.completionBody(prompt))
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	return CompletionResult{
+		Content: content,
+		Usage:   usage,
+		Model:   p.model,
+	}, nil
+}
+
+func (p *OpenAICompatibleProvider) StreamCompletion(ctx context.Context, prompt string, onChunk func(string)) (CompletionResult, error) {
+	content, usage, err := p.streamChatCompletion(ctx, p.completionBody(prompt), onChunk)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	return CompletionResult{
+		Content: content,
+		Usage:   usage,
+		Model:   p.model,
+	}, nil
+}
+
+func (p *OpenAICompatibleProvider) CompleteJSON(ctx context.Context, prompt, schemaName string, schema map[string]any) (CompletionResult, error) {
+	body := p.jsonBody(prompt, schemaName, schema)
+	body["temperature"] = 0.0
+	content, usage, err := p.doChatCompletion(ctx, body)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	return CompletionResult{
+		Content: content,
+		Usage:   usage,
+		Model:   p.model,
+	}, nil
+}
+
+func (p *OpenAICompatibleProvider) completionBody(prompt string) map[string]any {
+	body := p.jsonBody(prompt, "hotel_review_completion", completionJSONSchema())
+	body["temperature"] = 0.2
+	if p.maxTokens > 0 {
+		body["max_tokens"] = p.maxTokens
+	}
+	return body
+}
+
+// jsonBody constrains the answer to schema with response_format when the server supports json_schema, otherwise
+// the schema is spelled out in the system prompt and the answer parsed leniently
+func (p *OpenAICompatibleProvider) jsonBody(prompt, schemaName string, schema map[string]any) map[string]any {
+	system := "Return only valid JSON matching the schema."
+	if !p.jsonSchema {
+		system = "Return only valid JSON matching this JSON schema: " + jsonString(schema)
+	}
+	body := map[string]any{
+		"model": p.model,
+		"messages": []map[string]string{
+			{"role": "system", "content": system},
+			{"role": "user", "content": prompt},
+		},
+	}
+	if p.jsonSchema {
+		body["response_format"] = map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   schemaName,
+				"schema": schema,
+				"strict": true,
+			},
+		}
+	}
+	return body
+}
+
+func (p *OpenAICompatibleProvider) newRequest(ctx context.Context, method, path string, body map[string]any) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(bodyBytes)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// Local servers usually run without a key
+	if p.apiKey != "" {
+		if strings.EqualFold(p.authHeader, "Authorization") {
+			req.Header.Set("Authorization", "Bearer "+p.apiKey)
+		} else {
+			req.Header.Set(p.authHeader, p.apiKey)
+		}
+	}
+	return req, nil
+}
+
+func (p *OpenAICompatibleProvider) doChatCompletion(ctx context.Context, body map[string]any) (string, TokenUsage, error) {
+	req, err := p.newRequest(ctx, http.MethodPost, "/v1/chat/completions", body)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", TokenUsage{}, newHTTPProviderError(p.name, resp, respBytes)
+	}
+
+	var parsed chatCompletion
+	if err := json.Unmarshal(respBytes, &parsed); err != nil {
+		return "", TokenUsage{}, newInvalidResponseError(p.name, err)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", TokenUsage{}, &ProviderError{Provider: p.name, Kind: ProviderErrSchema, Err: errors.New("no choices returned")}
+	}
+	usage := TokenUsage{}
+	if parsed.Usage != nil {
+		usage = *parsed.Usage
+	}
+	return parsed.Choices[0].Message.Content, usage, nil
+}
+
+// streamChatCompletion posts the chat completion with stream enabled and forwards each content delta to onChunk.
+// Usage arrives in the final chunk when stream_options.include_usage is honored.
+func (p *OpenAICompatibleProvider) streamChatCompletion(ctx context.Context, body map[string]any, onChunk func(string)) (string, TokenUsage, error) {
+	body["stream"] = true
+	body["stream_options"] = map[string]any{"include_usage": true}
+
+	req, err := p.newRequest(ctx, http.MethodPost, "/v1/chat/completions", body)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", TokenUsage{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBytes, _ := io.ReadAll(resp.Body)
+		return "", TokenUsage{}, newHTTPProviderError(p.name, resp, respBytes)
+	}
+
+	var content strings.Builder
+	usage := TokenUsage{}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *TokenUsage `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", TokenUsage{}, newInvalidResponseError(p.name, fmt.Errorf("invalid stream chunk: %w", err))
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				content.WriteString(choice.Delta.Content)
+				onChunk(choice.Delta.Content)
+			}
+		}
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", TokenUsage{}, err
+	}
+	return content.String(), usage, nil
+}
@@ -0,0 +1,44 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAICompatibleProviderInvalidResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream bool
+		body   string
+	}{
+		{name: "truncated body", body: `{"choices":[{"message":`},
+		{name: "html error page", body: `<html>gateway</html>`},
+		{name: "invalid stream chunk", stream: true, body: "data: {\"choices\":[{\"delta\":{\"content\":\"[\"}}]}\n\ndata: {\"choices\":\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+			provider := &OpenAICompatibleProvider{name: "openai", apiKey: "key", authHeader: "Authorization", baseURL: srv.URL, model: "gpt-test", client: srv.Client()}
+
+			var err error
+			if tt.stream {
+				_, err = provider.StreamCompletion(context.Background(), "hotels in Paris", func(string) {})
+			} else {
+				_, err = provider.PromptCompletion(context.Background(), "hotels in Paris")
+			}
+			var providerErr *ProviderError
+			if !errors.As(err, &providerErr) || providerErr.Kind != ProviderErrInvalidResponse {
+				t.Fatalf("error = %v, want an invalid_response ProviderError", err)
+			}
+			if providerErr.Provider != "openai" || !providerErr.Retryable() || providerErr.tripsBreaker() {
+				t.Errorf("error = %v, want a retryable openai error that does not trip the breaker", providerErr)
+			}
+		})
+	}
+}
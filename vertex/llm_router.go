@@ -2,11 +2,17 @@ package vertex
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"net/http"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"google.golang.org/genai"
 )
@@ -21,31 +27,58 @@ type CompletionResult struct {
 	Content string     `json:"content"`
 	Usage   TokenUsage `json:"usage"`
 	Model   string     `json:"model,omitempty"`
+	// PromptVersion is the ID of the completion prompt template, e.g. "completion/default"
+	PromptVersion string `json:"prompt_version,omitempty"`
 }
 
 type LLMChoice string
 
 const (
-	LLMChoiceAuto   LLMChoice = "auto"
-	LLMChoiceGemini LLMChoice = "gemini"
-	LLMChoiceGrok   LLMChoice = "grok"
-	LLMChoiceOpenAI LLMChoice = "openai"
+	LLMChoiceAuto    LLMChoice = "auto"
+	LLMChoiceGemini  LLMChoice = "gemini"
+	LLMChoiceGrok    LLMChoice = "grok"
+	LLMChoiceOpenAI  LLMChoice = "openai"
+	LLMChoiceClaude  LLMChoice = "claude"
+	LLMChoiceBedrock LLMChoice = "bedrock"
 )
 
+// llmFallbackOrder is the order the built-in providers are tried in after the preferred one
+var llmFallbackOrder = []LLMChoice{LLMChoiceGemini, LLMChoiceGrok, LLMChoiceOpenAI, LLMChoiceClaude, LLMChoiceBedrock}
+
 type LLMProvider interface {
 	Name() string
-	CheckQuerySafety(ctx context.Context, question string) (bool, error)
-	PromptCompletion(ctx context.Context, question string, results []map[string]any) (CompletionResult, error)
+	CheckQuerySafety(ctx context.Context, prompt string) (bool, error)
+	PromptCompletion(ctx context.Context, prompt string) (CompletionResult, error)
+}
+
+// StreamingLLMProvider is implemented by providers that can stream completion text as it is generated
+type StreamingLLMProvider interface {
+	LLMProvider
+	StreamCompletion(ctx context.Context, prompt string, onChunk func(string)) (CompletionResult, error)
 }
 
+// StructuredLLMProvider is implemented by providers that can answer an arbitrary prompt with JSON matching a schema
+type StructuredLLMProvider interface {
+	LLMProvider
+	CompleteJSON(ctx context.Context, prompt, schemaName string, schema map[string]any) (CompletionResult, error)
+}
+
+// CompletionRouter renders the prompt templates and runs them through the provider fallback chain, so providers
+// only see the final prompt text
 type CompletionRouter struct {
 	config    *Config
 	providers map[LLMChoice]LLMProvider
+	// order is the fallback order of the providers after the preferred one
+	order []LLMChoice
+	// breakers are keyed by provider name
+	breakers map[string]*providerBreaker
+	prompts  *PromptRegistry
 }
 
-func NewCompletionRouter(config *Config, geminiClient genai.Client) (*CompletionRouter, error) {
+func NewCompletionRouter(ctx context.Context, config *Config, geminiClient genai.Client) (*CompletionRouter, error) {
 	grokKey := firstNonEmpty(config.GrokAPIKey, os.Getenv("GROK_API_KEY"))
 	openAIKey := firstNonEmpty(config.OpenAIAPIKey, os.Getenv("OPENAI_API_KEY"))
+	claudeKey := firstNonEmpty(config.ClaudeAPIKey, os.Getenv("ANTHROPIC_API_KEY"))
 
 	providers := map[LLMChoice]LLMProvider{
 		LLMChoiceGemini: &GeminiProvider{
@@ -56,65 +89,267 @@ func NewCompletionRouter(config *Config, geminiClient genai.Client) (*Completion
 	}
 
 	if grokKey != "" {
-		providers[LLMChoiceGrok] = &GrokProvider{
-			apiKey:  grokKey,
-			baseURL: "https://api.x.ai",
-			model:   firstNonEmpty(config.GrokModel, "grok-4-1-fast-non-reasoning"),
-			config:  *config,
-			client:  &http.Client{},
-		}
+		providers[LLMChoiceGrok] = newOpenAICompatibleProvider(string(LLMChoiceGrok), OpenAICompatibleConfig{
+			BaseURL:    "https://api.x.ai",
+			Model:      firstNonEmpty(config.GrokModel, "grok-4-1-fast-non-reasoning"),
+			APIKey:     grokKey,
+			JSONSchema: true,
+			MaxTokens:  16384,
+		})
 	} else {
 		log.Println("Grok API key not configured, Grok provider disabled")
 	}
 
 	if openAIKey != "" {
-		providers[LLMChoiceOpenAI] = &OpenAIProvider{
-			apiKey:  openAIKey,
-			baseURL: "https://api.openai.com",
-			model:   firstNonEmpty(config.OpenAIModel, "gpt-4.1-mini"),
-			config:  *config,
+		providers[LLMChoiceOpenAI] = newOpenAICompatibleProvider(string(LLMChoiceOpenAI), OpenAICompatibleConfig{
+			BaseURL:    "https://api.openai.com",
+			Model:      firstNonEmpty(config.OpenAIModel, "gpt-4.1-mini"),
+			APIKey:     openAIKey,
+			JSONSchema: true,
+		})
+	} else {
+		log.Println("OpenAI API key not configured, OpenAI provider disabled")
+	}
+
+	if claudeKey != "" {
+		providers[LLMChoiceClaude] = &ClaudeProvider{
+			apiKey:  claudeKey,
+			baseURL: "https://api.anthropic.com",
+			model:   firstNonEmpty(config.ClaudeModel, "claude-haiku-4-5"),
 			client:  &http.Client{},
 		}
 	} else {
-		log.Println("OpenAI API key not configured, OpenAI provider disabled")
+		log.Println("Claude API key not configured, Claude provider disabled")
+	}
+
+	if config.BedrockRegion != "" {
+		bedrock, err := NewBedrockProvider(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		providers[LLMChoiceBedrock] = bedrock
+	} else {
+		log.Println("Bedrock region not configured, Bedrock provider disabled")
+	}
+
+	// Named openai_compatible entries follow the built-in providers in the fallback chain, in name order
+	order := slices.Clone(llmFallbackOrder)
+	names := slices.Sorted(maps.Keys(config.OpenAICompatible))
+	for _, name := range names {
+		entry := config.OpenAICompatible[name]
+		choice := LLMChoice(strings.ToLower(strings.TrimSpace(name)))
+		if choice == LLMChoiceAuto || slices.Contains(llmFallbackOrder, choice) {
+			return nil, fmt.Errorf("openai_compatible entry %q clashes with a built-in provider", name)
+		}
+		if strings.TrimSpace(entry.BaseURL) == "" || strings.TrimSpace(entry.Model) == "" {
+			return nil, fmt.Errorf("openai_compatible entry %q requires base_url and model", name)
+		}
+		entry.APIKey = firstNonEmpty(entry.APIKey, os.Getenv(entry.APIKeyEnv))
+		providers[choice] = newOpenAICompatibleProvider(string(choice), entry)
+		order = append(order, choice)
+	}
+
+	prompts, err := NewPromptRegistry(config)
+	if err != nil {
+		return nil, err
+	}
+
+	breakers := make(map[string]*providerBreaker, len(providers))
+	for _, provider := range providers {
+		breakers[provider.Name()] = newProviderBreaker(config)
 	}
 
 	return &CompletionRouter{
 		config:    config,
 		providers: providers,
+		order:     order,
+		breakers:  breakers,
+		prompts:   prompts,
 	}, nil
 }
 
+// AssignPrompts fixes the prompt versions of a request, keeping requested versions and drawing the others by weight
+func (r *CompletionRouter) AssignPrompts(input SearchInput) (SearchInput, error) {
+	var err error
+	if input.PromptVersion, err = r.prompts.Assign(PromptKindCompletion, input.PromptVersion); err != nil {
+		return input, err
+	}
+	if input.SafetyPromptVersion, err = r.prompts.Assign(PromptKindSafety, input.SafetyPromptVersion); err != nil {
+		return input, err
+	}
+	return input, nil
+}
+
 func (r *CompletionRouter) CheckQuerySafety(ctx context.Context, input SearchInput) (bool, error) {
+	prompt, err := r.prompts.RenderSafety(input.SafetyPromptVersion, input)
+	if err != nil {
+		return false, err
+	}
 	chain := r.resolveChain(input.PreferredModel)
-	var errs []string
+	var errs []error
 	for _, provider := range chain {
-		ok, err := provider.CheckQuerySafety(ctx, input.Question)
+		var ok bool
+		err := r.callProvider(provider, func() (err error) {
+			ok, err = provider.CheckQuerySafety(ctx, prompt)
+			return err
+		})
 		if err == nil {
 			return ok, nil
 		}
-		errs = append(errs, fmt.Sprintf("%s: %v", provider.Name(), err))
+		errs = append(errs, err)
 		if !isRetryableLLMError(err) {
 			break
 		}
 	}
-	return false, fmt.Errorf("all safety providers failed: %s", strings.Join(errs, " | "))
+	return false, fmt.Errorf("all safety providers failed: %w", errors.Join(errs...))
 }
 
+// PromptCompletion answers the question from results with the input.PromptVersion template, with the prior turns
+// of input.History prepended so the provider can refine the recommendations of earlier turns. An answer that does
+// not parse as recommendations is a schema violation, falling back to the next provider. With hedge_delay_ms set
+// the fallbacks are hedged instead, see hedgedCompletion.
 func (r *CompletionRouter) PromptCompletion(ctx context.Context, input SearchInput, results []map[string]any) (CompletionResult, error) {
+	prompt, err := r.prompts.RenderCompletion(input.PromptVersion, input, results)
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	chain := r.resolveChain(input.PreferredModel)
+	if r.config.HedgeDelayMS > 0 && len(chain) > 1 {
+		resp, err := r.hedgedCompletion(ctx, chain, prompt, time.Duration(r.config.HedgeDelayMS)*time.Millisecond)
+		if err != nil {
+			return CompletionResult{}, err
+		}
+		resp.PromptVersion = PromptID(PromptKindCompletion, input.PromptVersion)
+		return resp, nil
+	}
+
+	var errs []error
+	for _, provider := range chain {
+		resp, err := r.validCompletion(ctx, provider, prompt)
+		if err == nil {
+			resp.PromptVersion = PromptID(PromptKindCompletion, input.PromptVersion)
+			return resp, nil
+		}
+		errs = append(errs, err)
+		if !isRetryableLLMError(err) {
+			break
+		}
+	}
+	return CompletionResult{}, fmt.Errorf("all completion providers failed: %w", errors.Join(errs...))
+}
+
+// validCompletion is a completion by one provider that parses as recommendations
+func (r *CompletionRouter) validCompletion(ctx context.Context, provider LLMProvider, prompt string) (CompletionResult, error) {
+	var resp CompletionResult
+	err := r.callProvider(provider, func() (err error) {
+		if resp, err = provider.PromptCompletion(ctx, prompt); err != nil {
+			return err
+		}
+		if _, err := ParseCompletionJSON(resp.Content); err != nil {
+			return &ProviderError{Provider: provider.Name(), Kind: ProviderErrSchema, Err: err}
+		}
+		return nil
+	})
+	return resp, err
+}
+
+// StreamCompletion follows the same fallback chain as PromptCompletion. Providers without streaming support emit
+// their whole answer as a single chunk. Once a chunk has been emitted there is no fallback, since a second provider
+// would interleave a different answer into the stream.
+func (r *CompletionRouter) StreamCompletion(ctx context.Context, input SearchInput, results []map[string]any, onChunk func(string)) (CompletionResult, error) {
+	prompt, err := r.prompts.RenderCompletion(input.PromptVersion, input, results)
+	if err != nil {
+		return CompletionResult{}, err
+	}
 	chain := r.resolveChain(input.PreferredModel)
-	var errs []string
+	var errs []error
 	for _, provider := range chain {
-		resp, err := provider.PromptCompletion(ctx, input.Question, results)
+		streamed := false
+		emit := func(chunk string) {
+			streamed = true
+			onChunk(chunk)
+		}
+
+		var resp CompletionResult
+		err := r.callProvider(provider, func() (err error) {
+			if sp, ok := provider.(StreamingLLMProvider); ok {
+				resp, err = sp.StreamCompletion(ctx, prompt, emit)
+				return err
+			}
+			resp, err = provider.PromptCompletion(ctx, prompt)
+			if err == nil {
+				emit(resp.Content)
+			}
+			return err
+		})
 		if err == nil {
+			resp.PromptVersion = PromptID(PromptKindCompletion, input.PromptVersion)
 			return resp, nil
 		}
-		errs = append(errs, fmt.Sprintf("%s: %v", provider.Name(), err))
+		errs = append(errs, err)
+		if streamed || !isRetryableLLMError(err) {
+			break
+		}
+	}
+	return CompletionResult{}, fmt.Errorf("all completion providers failed: %w", errors.Join(errs...))
+}
+
+// CompleteJSON follows the fallback chain over the providers that support schema constrained output.
+// It backs the auxiliary LLM calls such as reranking and query understanding.
+func (r *CompletionRouter) CompleteJSON(ctx context.Context, preferredModel, prompt, schemaName string, schema map[string]any) (CompletionResult, error) {
+	chain := r.resolveChain(preferredModel)
+	var errs []error
+	for _, provider := range chain {
+		sp, ok := provider.(StructuredLLMProvider)
+		if !ok {
+			continue
+		}
+		var resp CompletionResult
+		err := r.callProvider(provider, func() (err error) {
+			resp, err = sp.CompleteJSON(ctx, prompt, schemaName, schema)
+			return err
+		})
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, err)
 		if !isRetryableLLMError(err) {
 			break
 		}
 	}
-	return CompletionResult{}, fmt.Errorf("all completion providers failed: %s", strings.Join(errs, " | "))
+	if len(errs) == 0 {
+		return CompletionResult{}, fmt.Errorf("no configured provider supports JSON completions")
+	}
+	return CompletionResult{}, fmt.Errorf("all JSON completion providers failed: %w", errors.Join(errs...))
+}
+
+// callProvider runs one provider call through the provider's circuit breaker, skipping it while the breaker is
+// open, and records the typed outcome in its rolling stats
+func (r *CompletionRouter) callProvider(provider LLMProvider, call func() error) error {
+	breaker := r.breakers[provider.Name()]
+	if ok, wait := breaker.allow(time.Now()); !ok {
+		return &ProviderError{
+			Provider:   provider.Name(),
+			Kind:       ProviderErrCircuitOpen,
+			RetryAfter: wait,
+			Err:        errors.New("skipped after repeated failures"),
+		}
+	}
+	start := time.Now()
+	err := classifyProviderError(provider.Name(), call())
+	breaker.record(time.Now(), time.Since(start), err)
+	return err
+}
+
+// ProviderStats reports the circuit breaker and rolling stats of each configured provider in fallback order
+func (r *CompletionRouter) ProviderStats() []ProviderStats {
+	var stats []ProviderStats
+	for _, choice := range r.order {
+		if provider, ok := r.providers[choice]; ok {
+			stats = append(stats, r.breakers[provider.Name()].stats(provider.Name()))
+		}
+	}
+	return stats
 }
 
 func (r *CompletionRouter) resolveChain(model string) []LLMProvider {
@@ -126,35 +361,20 @@ func (r *CompletionRouter) resolveChain(model string) []LLMProvider {
 		}
 	}
 
-	var ordered []LLMProvider
-	switch LLMChoice(norm) {
-	case LLMChoiceGrok:
-		ordered = []LLMProvider{r.providers[LLMChoiceGrok], r.providers[LLMChoiceGemini], r.providers[LLMChoiceOpenAI]}
-	case LLMChoiceOpenAI:
-		ordered = []LLMProvider{r.providers[LLMChoiceOpenAI], r.providers[LLMChoiceGemini], r.providers[LLMChoiceGrok]}
-	default:
-		ordered = []LLMProvider{r.providers[LLMChoiceGemini], r.providers[LLMChoiceGrok], r.providers[LLMChoiceOpenAI]}
-	}
-
+	// The preferred provider goes first, an unknown or unconfigured one leaves the fallback order
+	preferred := LLMChoice(norm)
 	var chain []LLMProvider
-	for _, p := range ordered {
-		if p != nil {
+	if p, ok := r.providers[preferred]; ok {
+		chain = append(chain, p)
+	}
+	for _, choice := range r.order {
+		if p, ok := r.providers[choice]; ok && choice != preferred {
 			chain = append(chain, p)
 		}
 	}
 	return chain
 }
 
-func isRetryableLLMError(err error) bool {
-	if err == nil {
-		return false
-	}
-	msg := strings.ToLower(err.Error())
-	return strings.Contains(msg, "429") || strings.Contains(msg, "rate limit") ||
-		strings.Contains(msg, "timeout") || strings.Contains(msg, "temporarily") ||
-		strings.Contains(msg, "unavailable") || strings.Contains(msg, "resource exhausted")
-}
-
 func completionJSONSchema() map[string]any {
 	return map[string]any{
 		"type": "array",
@@ -175,25 +395,6 @@ func completionJSONSchema() map[string]any {
 	}
 }
 
-func buildCompletionPrompt(systemPrompt, question string, results []map[string]any) string {
-	var reviewContext strings.Builder
-	for i, r := range results {
-		reviewContext.WriteString(fmt.Sprintf(
-			"Review %d:\nHotel: %v\nCity: %v\nReview: %v\nRating: %v\nDistance: %.3f\nAddress: %v\nGoogleMapsURI: %v\nPhotoName: %v\n\n",
-			i+1, r["hotel_name"], r["city"], r["review_text"], r["rating"], r["distance"],
-			r["street_address"], r["google_maps_uri"], r["photo_name"],
-		))
-	}
-	return fmt.Sprintf(`%s
-
-User Question: %s
-
-Important: If a review has a google_maps_uri or photo_name, you MUST include them in the JSON output.
-
-Reviews:
-%s`, systemPrompt, question, reviewContext.String())
-}
-
 func firstNonEmpty(vals ...string) string {
 	for _, v := range vals {
 		if strings.TrimSpace(v) != "" {
@@ -202,3 +403,31 @@ func firstNonEmpty(vals ...string) string {
 	}
 	return ""
 }
+
+// ParseCompletionJSON parses the JSON array of hotel recommendations returned by a completion, tolerating markdown fences
+func ParseCompletionJSON(completionStr string) ([]map[string]any, error) {
+	trimmed := strings.TrimSpace(completionStr)
+	if trimmed == "" || trimmed == "null" {
+		return []map[string]any{}, nil
+	}
+
+	dirtyRegexp := regexp.MustCompile("(?s)^```(?:json)?\\s*|\\s*```$")
+	clean := dirtyRegexp.ReplaceAllString(trimmed, "")
+
+	var err error
+	var reviews []map[string]any
+	if err = json.Unmarshal([]byte(clean), &reviews); err == nil {
+		log.Println("Successfully parsed LLM returned JSON")
+		return reviews, nil
+	}
+
+	log.Printf("Failed to unmarshal LLM returned JSON. Trying backtick removal %v\n", err)
+	noBackTick := strings.ReplaceAll(trimmed, "```", "")
+	noFrontTick := strings.ReplaceAll(noBackTick, "json```", "")
+	if err = json.Unmarshal([]byte(noFrontTick), &reviews); err == nil {
+		log.Println("Successfully parsed LLM returned JSON after removing backticks")
+		return reviews, nil
+	}
+	log.Printf("Failed to parse LLM returned JSON after backtick removal: %v\n", err)
+	return nil, err
+}
@@ -11,7 +11,8 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 )
 
-func InitTracer(name string) {
+// InitTracer installs the global tracer provider and returns its Shutdown, which flushes the spans still batched
+func InitTracer(name string) func(context.Context) error {
 	ctx := context.Background()
 	res, err := resource.New(ctx, resource.WithAttributes(
 		semconv.ServiceName(name),
@@ -30,4 +31,5 @@ func InitTracer(name string) {
 		sdktrace.WithResource(res),
 	)
 	otel.SetTracerProvider(tp)
+	return tp.Shutdown
 }
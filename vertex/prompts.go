@@ -0,0 +1,239 @@
+package vertex
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Prompt kinds, each a subdirectory of prompt_dir holding one <version>.tmpl file per version
+const (
+	PromptKindCompletion = "completion"
+	PromptKindSafety     = "safety"
+)
+
+// DefaultPromptVersion is the built-in layout around config.Prompt and config.SecurityPrompt. It is used unless
+// prompt_weights assigns traffic to other versions, and a default.tmpl file replaces it.
+const DefaultPromptVersion = "default"
+
+const defaultCompletionTemplate = `{{.SystemPrompt}}
+
+User Question: {{.Question}}
+
+Important: If a review has a google_maps_uri or photo_name, you MUST include them in the JSON output.
+
+Reviews:
+{{range .Reviews}}Review {{.Number}}:
+Hotel: {{.Hotel}}
+City: {{.City}}
+Review: {{.Review}}
+Rating: {{.Rating}}
+Distance: {{printf "%.3f" .Distance}}
+Address: {{.Address}}
+GoogleMapsURI: {{.GoogleMapsURI}}
+PhotoName: {{.PhotoName}}
+{{with .ImportantNote}}ImportantNote: {{.}}
+{{end}}
+{{end}}`
+
+const defaultSafetyTemplate = `{{.SystemPrompt}}
+
+User query: {{.Question}}
+
+Answer with only the word YES or NO.`
+
+// PromptData is passed to every template. Question already includes the earlier turns of a session, History
+// holds them separately for templates that lay them out differently.
+type PromptData struct {
+	SystemPrompt string
+	Question     string
+	RawQuestion  string
+	History      []Turn
+	Reviews      []PromptReview
+}
+
+// PromptReview is one retrieved review as laid out in the completion prompt
+type PromptReview struct {
+	Number        int
+	Hotel         any
+	City          any
+	Review        any
+	Rating        any
+	Distance      float64
+	Address       any
+	GoogleMapsURI any
+	PhotoName     any
+	ImportantNote any
+	Fields        map[string]any
+}
+
+// PromptTemplate is one version of a prompt kind. ID is "<kind>/<version>", as recorded in responses and logs.
+type PromptTemplate struct {
+	Kind    string
+	Version string
+	Weight  int
+	tmpl    *template.Template
+}
+
+func (t *PromptTemplate) ID() string { return PromptID(t.Kind, t.Version) }
+
+// PromptID is the "<kind>/<version>" ID of a prompt version, an empty version is the default
+func PromptID(kind, version string) string {
+	return kind + "/" + firstNonEmpty(version, DefaultPromptVersion)
+}
+
+// PromptRegistry holds the prompt templates loaded from prompt_dir, with the built-in default version of each kind
+type PromptRegistry struct {
+	config    *Config
+	templates map[string]map[string]*PromptTemplate
+}
+
+// NewPromptRegistry loads <prompt_dir>/<kind>/<version>.tmpl files and applies prompt_weights, keyed by template ID.
+// A missing prompt_dir only leaves the defaults.
+func NewPromptRegistry(config *Config) (*PromptRegistry, error) {
+	r := &PromptRegistry{config: config, templates: make(map[string]map[string]*PromptTemplate)}
+	if err := r.add(PromptKindCompletion, DefaultPromptVersion, defaultCompletionTemplate); err != nil {
+		return nil, err
+	}
+	if err := r.add(PromptKindSafety, DefaultPromptVersion, defaultSafetyTemplate); err != nil {
+		return nil, err
+	}
+
+	dir := firstNonEmpty(config.PromptDir, "prompts")
+	for _, kind := range []string{PromptKindCompletion, PromptKindSafety} {
+		files, err := filepath.Glob(filepath.Join(dir, kind, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			text, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read prompt %s: %w", file, err)
+			}
+			// Versions are lowercase, config keys such as prompt_weights are case-insensitive
+			version := strings.ToLower(strings.TrimSuffix(filepath.Base(file), ".tmpl"))
+			if err := r.add(kind, version, string(text)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for id, weight := range config.PromptWeights {
+		kind, version, _ := strings.Cut(strings.ToLower(id), "/")
+		t, ok := r.templates[kind][version]
+		if !ok {
+			return nil, fmt.Errorf("prompt_weights references unknown prompt %q", id)
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("prompt_weights for %q must not be negative", id)
+		}
+		t.Weight = weight
+	}
+	return r, nil
+}
+
+func (r *PromptRegistry) add(kind, version, text string) error {
+	tmpl, err := template.New(kind + "/" + version).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse prompt %s/%s: %w", kind, version, err)
+	}
+	if r.templates[kind] == nil {
+		r.templates[kind] = make(map[string]*PromptTemplate)
+	}
+	r.templates[kind][version] = &PromptTemplate{Kind: kind, Version: version, tmpl: tmpl}
+	return nil
+}
+
+// Assign picks the version for a request: the requested one when set, otherwise a weighted random choice among the
+// versions with a prompt_weights entry, falling back to the default
+func (r *PromptRegistry) Assign(kind, requested string) (string, error) {
+	versions := r.templates[kind]
+	if requested = strings.TrimSpace(requested); requested != "" {
+		requested = strings.TrimPrefix(strings.ToLower(requested), kind+"/")
+		if _, ok := versions[requested]; !ok {
+			return "", fmt.Errorf("unknown %s prompt version %q, expected one of %s", kind, requested, strings.Join(r.Versions(kind), ", "))
+		}
+		return requested, nil
+	}
+
+	var total int
+	for _, t := range versions {
+		total += t.Weight
+	}
+	if total == 0 {
+		return DefaultPromptVersion, nil
+	}
+
+	n := rand.IntN(total)
+	for _, version := range r.Versions(kind) {
+		n -= versions[version].Weight
+		if n < 0 {
+			return version, nil
+		}
+	}
+	return DefaultPromptVersion, nil
+}
+
+// Versions lists the versions of a kind in name order
+func (r *PromptRegistry) Versions(kind string) []string {
+	versions := make([]string, 0, len(r.templates[kind]))
+	for version := range r.templates[kind] {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// RenderCompletion renders the completion prompt version for the question and retrieved reviews
+func (r *PromptRegistry) RenderCompletion(version string, input SearchInput, results []map[string]any) (string, error) {
+	data := PromptData{
+		SystemPrompt: r.config.Prompt,
+		Question:     conversationQuestion(input),
+		RawQuestion:  input.Question,
+		History:      input.History,
+		Reviews:      make([]PromptReview, len(results)),
+	}
+	for i, res := range results {
+		distance, _ := strconv.ParseFloat(fmt.Sprintf("%v", res["distance"]), 64)
+		data.Reviews[i] = PromptReview{
+			Number:        i + 1,
+			Hotel:         res["hotel_name"],
+			City:          res["city"],
+			Review:        res["review_text"],
+			Rating:        res["rating"],
+			Distance:      distance,
+			Address:       res["street_address"],
+			GoogleMapsURI: res["google_maps_uri"],
+			PhotoName:     res["photo_name"],
+			ImportantNote: res["important_note"],
+			Fields:        res,
+		}
+	}
+	return r.render(PromptKindCompletion, version, data)
+}
+
+// RenderSafety renders the safety check prompt version for the current question only
+func (r *PromptRegistry) RenderSafety(version string, input SearchInput) (string, error) {
+	return r.render(PromptKindSafety, version, PromptData{
+		SystemPrompt: r.config.SecurityPrompt,
+		Question:     input.Question,
+		RawQuestion:  input.Question,
+	})
+}
+
+func (r *PromptRegistry) render(kind, version string, data PromptData) (string, error) {
+	t, ok := r.templates[kind][firstNonEmpty(version, DefaultPromptVersion)]
+	if !ok {
+		return "", fmt.Errorf("unknown %s prompt version %q", kind, version)
+	}
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", t.ID(), err)
+	}
+	return b.String(), nil
+}
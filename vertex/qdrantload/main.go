@@ -0,0 +1,53 @@
+package main
+
+// Upserts review embeddings into Qdrant for the qdrant vector backend.
+// Reads the Vertex JSONL export at local_embeddings_path, or the SQLite review_embeddings table when unset.
+//
+// For local testing:
+//   docker run -p 6333:6333 qdrant/qdrant
+//   go run ./vertex/qdrantload
+
+import (
+	"context"
+	"log"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+)
+
+func main() {
+	config, err := vertex.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	datapoints, err := vertex.LoadDatapoints(ctx, config)
+	if err != nil {
+		log.Fatalf("Failed to load embeddings: %v", err)
+	}
+	if len(datapoints) == 0 {
+		log.Println("⚠️ No embeddings found, nothing to upload")
+		return
+	}
+
+	qdrant := vertex.NewQdrantSearcher(config)
+	if err := qdrant.EnsureCollection(ctx, len(datapoints[0].Embedding)); err != nil {
+		log.Fatalf("Failed to prepare collection: %v", err)
+	}
+
+	const batchSize = 256
+	for i := 0; i < len(datapoints); i += batchSize {
+		end := i + batchSize
+		if end > len(datapoints) {
+			end = len(datapoints)
+		}
+		if err := qdrant.Upsert(ctx, datapoints[i:end]); err != nil {
+			log.Fatalf("Failed to upsert batch at %d: %v", i, err)
+		}
+		if i/batchSize%20 == 0 {
+			log.Printf("📤 qdrant: %d/%d points", end, len(datapoints))
+		}
+	}
+
+	log.Printf("✅ Uploaded %d review embeddings to Qdrant", len(datapoints))
+}
@@ -0,0 +1,228 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Travel types the query understanding step may infer
+var travelTypes = []string{"business", "couple", "family", "friends", "solo"}
+
+// QueryFilters are the search filters inferred from the question. Empty fields were not mentioned.
+type QueryFilters struct {
+	City       string   `json:"city"`
+	Country    string   `json:"country"`
+	Continent  string   `json:"continent"`
+	MinRating  int      `json:"min_rating"`
+	TravelType string   `json:"travel_type"`
+	Aspects    []string `json:"aspects"`
+}
+
+// Location is one city known to the index, with the spelling used in the restrict namespaces
+type Location struct {
+	City      string
+	Country   string
+	Continent string
+}
+
+// LocationCatalog validates inferred locations against the cities that actually have reviews
+type LocationCatalog struct {
+	locations  []Location
+	continents map[string]string
+}
+
+func NewLocationCatalog(locations []Location) *LocationCatalog {
+	continents := make(map[string]string)
+	for _, l := range locations {
+		continents[strings.ToLower(l.Continent)] = l.Continent
+	}
+	return &LocationCatalog{locations: locations, continents: continents}
+}
+
+// Continents returns the known continent names, sorted
+func (c *LocationCatalog) Continents() []string {
+	names := make([]string, 0, len(c.continents))
+	for _, name := range c.continents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MatchCity finds the catalog entry for a city, using country to disambiguate. A city without a country only
+// matches when the name is unique across the catalog.
+func (c *LocationCatalog) MatchCity(city, country string) (Location, bool) {
+	var matches []Location
+	for _, l := range c.locations {
+		if !strings.EqualFold(l.City, city) {
+			continue
+		}
+		if country != "" && !strings.EqualFold(l.Country, country) {
+			continue
+		}
+		matches = append(matches, l)
+	}
+	if len(matches) != 1 {
+		return Location{}, false
+	}
+	return matches[0], true
+}
+
+// MatchCountry returns the catalog spelling of a country
+func (c *LocationCatalog) MatchCountry(country string) (string, bool) {
+	for _, l := range c.locations {
+		if strings.EqualFold(l.Country, country) {
+			return l.Country, true
+		}
+	}
+	return "", false
+}
+
+// MatchContinent returns the catalog spelling of a continent
+func (c *LocationCatalog) MatchContinent(continent string) (string, bool) {
+	name, ok := c.continents[strings.ToLower(strings.TrimSpace(continent))]
+	return name, ok
+}
+
+// LocationSource provides the catalog of known locations, which may need a remote query
+type LocationSource interface {
+	Catalog(ctx context.Context) *LocationCatalog
+}
+
+// UnderstandQuery extracts filters from the question with a schema constrained completion and merges them into
+// input. Explicit form filters always win over inferred ones, and inferred locations missing from the catalog of
+// locations are dropped. The catalog is only fetched when understanding is enabled; without one no location is
+// inferred.
+func (s *VertexSearchService) UnderstandQuery(ctx context.Context, input SearchInput, locations LocationSource) (SearchInput, QueryFilters, error) {
+	if !s.queryUnderstanding || strings.TrimSpace(input.Question) == "" {
+		return input, QueryFilters{}, nil
+	}
+
+	var catalog *LocationCatalog
+	if locations != nil {
+		catalog = locations.Catalog(ctx)
+	}
+	var continents []string
+	if catalog != nil {
+		continents = catalog.Continents()
+	}
+
+	resp, err := s.completionRouter.CompleteJSON(ctx, input.PreferredModel,
+		buildQueryUnderstandingPrompt(input.Question, continents), "search_filters", queryFiltersJSONSchema())
+	if err != nil {
+		return input, QueryFilters{}, err
+	}
+
+	var filters QueryFilters
+	content := strings.TrimSpace(resp.Content)
+	content = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(content, "```json"), "```"), "```")
+	if err := json.Unmarshal([]byte(content), &filters); err != nil {
+		return input, QueryFilters{}, fmt.Errorf("%s returned invalid filters: %w", resp.Model, err)
+	}
+
+	filters = validateQueryFilters(filters, catalog)
+	return MergeQueryFilters(input, filters), filters, nil
+}
+
+// validateQueryFilters clears every inferred value that does not match the catalog or the allowed ranges
+func validateQueryFilters(f QueryFilters, catalog *LocationCatalog) QueryFilters {
+	valid := QueryFilters{}
+
+	if catalog != nil {
+		if f.City != "" {
+			if loc, ok := catalog.MatchCity(f.City, f.Country); ok {
+				valid.City, valid.Country, valid.Continent = loc.City, loc.Country, loc.Continent
+			}
+		}
+		if valid.Country == "" && f.Country != "" && f.City == "" {
+			valid.Country, _ = catalog.MatchCountry(f.Country)
+		}
+		if valid.Continent == "" && f.Continent != "" {
+			valid.Continent, _ = catalog.MatchContinent(f.Continent)
+		}
+	}
+
+	if f.MinRating >= 1 && f.MinRating <= 5 {
+		valid.MinRating = f.MinRating
+	}
+
+	for _, t := range travelTypes {
+		if strings.EqualFold(f.TravelType, t) {
+			valid.TravelType = t
+		}
+	}
+
+	for _, a := range f.Aspects {
+		if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+			valid.Aspects = append(valid.Aspects, a)
+		}
+	}
+	return valid
+}
+
+// MergeQueryFilters applies inferred filters to the fields the form left empty
+func MergeQueryFilters(input SearchInput, f QueryFilters) SearchInput {
+	explicitLocation := input.FilterCityCountry || input.Continent != ""
+
+	if !explicitLocation {
+		switch {
+		case f.City != "":
+			input.City, input.Country = f.City, f.Country
+			input.FilterCityCountry = true
+		case f.Country != "":
+			input.Country = f.Country
+		}
+	}
+	if input.Continent == "" && !input.FilterCityCountry && f.Continent != "" {
+		input.Continent = f.Continent
+	}
+
+	if !input.FilterRating && f.MinRating > 0 {
+		input.Rating = f.MinRating
+		input.FilterRating = true
+	}
+
+	if input.TravelType == "" {
+		input.TravelType = f.TravelType
+	}
+	if len(input.Aspects) == 0 {
+		input.Aspects = f.Aspects
+	}
+	return input
+}
+
+func queryFiltersJSONSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city":        map[string]any{"type": "string"},
+			"country":     map[string]any{"type": "string"},
+			"continent":   map[string]any{"type": "string"},
+			"min_rating":  map[string]any{"type": "integer"},
+			"travel_type": map[string]any{"type": "string"},
+			"aspects": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+		},
+		"required":             []string{"city", "country", "continent", "min_rating", "travel_type", "aspects"},
+		"additionalProperties": false,
+	}
+}
+
+func buildQueryUnderstandingPrompt(question string, continents []string) string {
+	return fmt.Sprintf(`Extract hotel search filters from the traveller's question.
+Only fill a field when the question states it, otherwise use "" or 0 or [].
+
+city: the city name in English, e.g. "Madrid".
+country: the country name in English, e.g. "Spain".
+continent: one of %s.
+min_rating: the minimum review rating from 1 to 5, e.g. 4 for "rated at least 4" or "4 stars and up".
+travel_type: one of %s.
+aspects: short lowercase hotel features the traveller cares about, e.g. "quiet", "breakfast", "wifi", "pool".
+
+Question: %s`, strings.Join(continents, ", "), strings.Join(travelTypes, ", "), question)
+}
@@ -0,0 +1,237 @@
+package vertex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// rerankReviewChars caps each review in the reranking prompt, scoring needs the gist rather than the full text
+const rerankReviewChars = 600
+
+// Reranker reorders metadata results by relevance to the question and trims them to topN.
+// Each returned result carries its "rerank_score".
+type Reranker interface {
+	Name() string
+	Rerank(ctx context.Context, input SearchInput, results []map[string]any, topN int) ([]map[string]any, error)
+}
+
+// NewReranker selects the reranker from config.Reranker: "llm", "cross_encoder", or empty/"none" to disable reranking
+func NewReranker(config *Config, router *CompletionRouter) (Reranker, error) {
+	switch strings.ToLower(strings.TrimSpace(config.Reranker)) {
+	case "", "none":
+		return nil, nil
+	case "llm":
+		return &LLMReranker{router: router, model: config.RerankerModel}, nil
+	case "cross_encoder":
+		if config.RerankerURL == "" {
+			return nil, fmt.Errorf("reranker_url is required for the cross_encoder reranker")
+		}
+		return &CrossEncoderReranker{
+			url:    config.RerankerURL,
+			apiKey: config.RerankerAPIKey,
+			model:  config.RerankerModel,
+			client: &http.Client{Timeout: 10 * time.Second},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown reranker: %s", config.Reranker)
+	}
+}
+
+// LLMReranker asks the completion providers for a pointwise 0-10 relevance score per review in a single call
+type LLMReranker struct {
+	router *CompletionRouter
+	// model overrides the request's preferred model for scoring, e.g. a cheaper provider than the one answering
+	model string
+}
+
+func (r *LLMReranker) Name() string { return "llm" }
+
+func (r *LLMReranker) Rerank(ctx context.Context, input SearchInput, results []map[string]any, topN int) ([]map[string]any, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+	model := firstNonEmpty(r.model, input.PreferredModel)
+	resp, err := r.router.CompleteJSON(ctx, model, buildRerankPrompt(input.Question, results), "review_relevance_scores", rerankJSONSchema())
+	if err != nil {
+		return nil, err
+	}
+	scores, err := parseRerankScores(resp.Content, len(results))
+	if err != nil {
+		return nil, fmt.Errorf("%s returned invalid rerank scores: %w", resp.Model, err)
+	}
+	return applyRerankScores(results, scores, topN), nil
+}
+
+// CrossEncoderReranker calls a Cohere-compatible /rerank endpoint (Cohere, Jina, Voyage, Infinity or a
+// self-hosted cross-encoder behind the same API)
+type CrossEncoderReranker struct {
+	url    string
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+func (r *CrossEncoderReranker) Name() string { return "cross_encoder" }
+
+func (r *CrossEncoderReranker) Rerank(ctx context.Context, input SearchInput, results []map[string]any, topN int) ([]map[string]any, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	documents := make([]string, len(results))
+	for i, res := range results {
+		documents[i] = fmt.Sprintf("%v, %v: %v", res["hotel_name"], res["city"], res["review_text"])
+	}
+	body := map[string]any{
+		"query":     input.Question,
+		"documents": documents,
+	}
+	if r.model != "" {
+		body["model"] = r.model
+	}
+
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("rerank request failed: %s: %s", resp.Status, string(respBytes))
+	}
+
+	var parsed struct {
+		Results []struct {
+			Index          int     `json:"index"`
+			RelevanceScore float64 `json:"relevance_score"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(respBytes, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse rerank response: %w", err)
+	}
+
+	scores := make([]float64, len(results))
+	for i := range scores {
+		scores[i] = -1
+	}
+	for _, res := range parsed.Results {
+		if res.Index >= 0 && res.Index < len(scores) {
+			scores[res.Index] = res.RelevanceScore
+		}
+	}
+	return applyRerankScores(results, scores, topN), nil
+}
+
+// applyRerankScores sorts results by descending score, keeping the retrieval order for ties, and trims to topN
+func applyRerankScores(results []map[string]any, scores []float64, topN int) []map[string]any {
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+
+	if topN <= 0 || topN > len(order) {
+		topN = len(order)
+	}
+	reranked := make([]map[string]any, 0, topN)
+	for _, i := range order[:topN] {
+		results[i]["rerank_score"] = scores[i]
+		reranked = append(reranked, results[i])
+	}
+	return reranked
+}
+
+func rerankJSONSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"scores": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"index": map[string]any{"type": "integer"},
+						"score": map[string]any{"type": "number"},
+					},
+					"required":             []string{"index", "score"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"scores"},
+		"additionalProperties": false,
+	}
+}
+
+func buildRerankPrompt(question string, results []map[string]any) string {
+	var reviews strings.Builder
+	for i, r := range results {
+		text := []rune(fmt.Sprintf("%v", r["review_text"]))
+		if len(text) > rerankReviewChars {
+			text = append(text[:rerankReviewChars], '…')
+		}
+		reviews.WriteString(fmt.Sprintf("[%d] Hotel: %v, %v. Rating: %v\n%s\n\n", i, r["hotel_name"], r["city"], r["rating"], string(text)))
+	}
+	return fmt.Sprintf(`Score how well each hotel review helps answer the traveller's question.
+Judge every review on its own, from 0 (irrelevant) to 10 (directly answers the question).
+Return one score per review index.
+
+Question: %s
+
+Reviews:
+%s`, question, reviews.String())
+}
+
+func parseRerankScores(content string, n int) ([]float64, error) {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimSuffix(strings.TrimPrefix(content, "```"), "```")
+
+	var parsed struct {
+		Scores []struct {
+			Index int     `json:"index"`
+			Score float64 `json:"score"`
+		} `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(content), &parsed); err != nil {
+		return nil, err
+	}
+
+	// Reviews the model skipped sort after every scored one
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = -1
+	}
+	for _, s := range parsed.Scores {
+		if s.Index >= 0 && s.Index < n {
+			scores[s.Index] = s.Score
+		}
+	}
+	return scores, nil
+}
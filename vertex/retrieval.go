@@ -0,0 +1,165 @@
+package vertex
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
+
+// RetrievalBackends are the stores Retrieve reads from besides the vector backend of the VertexSearchService
+type RetrievalBackends struct {
+	Metadata  MetadataProvider
+	Keyword   KeywordSearcher
+	Hotels    HotelLocator
+	Locations LocationSource
+}
+
+// RetrievalTimings are the durations of the retrieval stages, zero for the stages that did not run
+type RetrievalTimings struct {
+	Understand   time.Duration
+	Geo          time.Duration
+	Embedding    time.Duration
+	VectorSearch time.Duration
+	Keyword      time.Duration
+	Metadata     time.Duration
+}
+
+// Retrieval is the outcome of Retrieve
+type Retrieval struct {
+	// Input is the search input with the inferred filters and the geo prefilter applied
+	Input   SearchInput
+	Filters QueryFilters
+	// Results are the fused rankings, Rows their metadata in the same order with distance_km for geo searches
+	Results []VectorResult
+	Rows    []map[string]any
+	Timings RetrievalTimings
+}
+
+// Retrieve runs the retrieval stages every search shares: query understanding and the geo prefilter alongside the
+// embedding, the vector and/or keyword search selected by input.Mode, fusion with the session's prior reviews and
+// the metadata lookup. config.Limit is widened by CandidateLimit for a reranker to trim.
+//
+// A failed stage is passed to onError, which may be called concurrently, and retrieval goes on with what remains:
+// in hybrid mode either retriever may fail and the other still provides results.
+func (s *VertexSearchService) Retrieve(ctx context.Context, config *Config, backends RetrievalBackends, input SearchInput, priorReviewIDs []string, onError func(stage string, err error)) Retrieval {
+	tracer := otel.Tracer("vertex-search")
+
+	retrievalConfig := *config
+	retrievalConfig.Limit = s.CandidateLimit(config.Limit)
+
+	r := Retrieval{Input: input}
+	var timings RetrievalTimings
+
+	// Filters inferred from the question only restrict retrieval, so they run alongside the embedding
+	understood := make(chan struct{})
+	var nearby []HotelCoordinate
+	go func() {
+		defer close(understood)
+		start := time.Now()
+		_, understandSpan := tracer.Start(ctx, "query-understanding")
+		merged, inferred, err := s.UnderstandQuery(ctx, input, backends.Locations)
+		understandSpan.End()
+		timings.Understand = time.Since(start)
+
+		if err != nil {
+			onError(StageUnderstand, err)
+		} else {
+			r.Input, r.Filters = merged, inferred
+		}
+
+		if r.Input.Geo == nil {
+			return
+		}
+		start = time.Now()
+		_, geoSpan := tracer.Start(ctx, "geo-prefilter")
+		defer geoSpan.End()
+
+		// On failure HotelNames stays empty and retrieval returns nothing rather than ignoring the radius
+		r.Input, nearby, err = ApplyGeoFilter(ctx, backends.Hotels, r.Input)
+		timings.Geo = time.Since(start)
+		if err != nil {
+			onError(StageGeo, err)
+		}
+	}()
+
+	keywordChan := make(chan []VectorResult, 1)
+	go func() {
+		if input.Mode == SearchModeVector {
+			keywordChan <- nil
+			return
+		}
+
+		<-understood
+		start := time.Now()
+		_, keywordSpan := tracer.Start(ctx, "keyword-search")
+		defer keywordSpan.End()
+
+		results, err := KeywordSearch(ctx, backends.Keyword, retrievalConfig, r.Input)
+		timings.Keyword = time.Since(start)
+		if err != nil {
+			onError(StageKeyword, err)
+		}
+		keywordChan <- results
+	}()
+
+	var results []VectorResult
+	var rows []map[string]any
+	if input.Mode != SearchModeKeyword {
+		start := time.Now()
+		_, embedSpan := tracer.Start(ctx, "embedding")
+		embedding, err := s.GenerateEmbedding(ctx, input.Question)
+		embedSpan.End()
+		timings.Embedding = time.Since(start)
+
+		if err != nil {
+			onError(StageEmbedding, err)
+		} else {
+			<-understood
+			start = time.Now()
+			_, searchSpan := tracer.Start(ctx, "vector-search")
+			results, rows, err = s.VectorSearchWithMetadata(ctx, retrievalConfig, embedding, r.Input)
+			searchSpan.End()
+			timings.VectorSearch = time.Since(start)
+
+			if err != nil {
+				onError(StageVectorSearch, err)
+				results, rows = nil, nil
+			}
+		}
+	}
+
+	keywordResults := <-keywordChan
+	<-understood
+	switch input.Mode {
+	case SearchModeKeyword:
+		results = keywordResults
+	case SearchModeHybrid:
+		results = FuseRRF(retrievalConfig.Limit, results, keywordResults)
+		rows = MetadataForResults(rows, results)
+	}
+	if len(priorReviewIDs) > 0 {
+		results = FuseSessionResults(retrievalConfig.Limit, results, priorReviewIDs)
+		rows = MetadataForResults(rows, results)
+	}
+
+	// Backends like pgvector already joined the metadata in the vector query
+	if rows == nil && len(results) > 0 {
+		start := time.Now()
+		_, metaSpan := tracer.Start(ctx, "metadata-lookup")
+		var err error
+		rows, err = backends.Metadata.GetMetadataByIDs(ctx, results, config)
+		metaSpan.End()
+		timings.Metadata = time.Since(start)
+
+		if err != nil {
+			onError(StageMetadata, err)
+			rows = nil
+		}
+	}
+
+	r.Results = results
+	r.Rows = AttachGeoDistance(rows, r.Input.Geo, nearby)
+	r.Timings = timings
+	return r
+}
@@ -0,0 +1,104 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// fakeKeyword answers FindKeyword with ranking or err
+type fakeKeyword struct {
+	ranking []VectorResult
+	err     error
+}
+
+func (k fakeKeyword) Name() string { return "fake" }
+
+func (k fakeKeyword) FindKeyword(ctx context.Context, terms []string, restricts []Restrict, numericRestricts []NumericRestrict, limit int) ([]VectorResult, error) {
+	return k.ranking, k.err
+}
+
+// fakeMetadata returns one row per result, or err
+type fakeMetadata struct {
+	err error
+}
+
+func (m fakeMetadata) GetMetadataByIDs(ctx context.Context, vectorResults []VectorResult, config *Config) ([]map[string]any, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	rows := make([]map[string]any, len(vectorResults))
+	for i, r := range vectorResults {
+		rows[i] = map[string]any{"id": r.ID}
+	}
+	return rows, nil
+}
+
+func TestRetrieveKeywordMode(t *testing.T) {
+	down := errors.New("down")
+	tests := []struct {
+		name        string
+		backends    RetrievalBackends
+		prior       []string
+		wantResults []string
+		wantRows    []string
+		wantStages  []string
+	}{
+		{
+			name:        "keyword results with metadata",
+			backends:    RetrievalBackends{Keyword: fakeKeyword{ranking: rankingOf("r1", "r2")}, Metadata: fakeMetadata{}},
+			wantResults: []string{"r1", "r2"},
+			wantRows:    []string{"r1", "r2"},
+		},
+		{
+			name:        "previous turn's reviews are fused",
+			backends:    RetrievalBackends{Keyword: fakeKeyword{ranking: rankingOf("r1", "r2")}, Metadata: fakeMetadata{}},
+			prior:       []string{"r2", "r3"},
+			wantResults: []string{"r2", "r1", "r3"},
+			wantRows:    []string{"r2", "r1", "r3"},
+		},
+		{
+			name:       "keyword search fails",
+			backends:   RetrievalBackends{Keyword: fakeKeyword{err: down}, Metadata: fakeMetadata{}},
+			wantStages: []string{StageKeyword},
+		},
+		{
+			name:        "metadata lookup fails",
+			backends:    RetrievalBackends{Keyword: fakeKeyword{ranking: rankingOf("r1")}, Metadata: fakeMetadata{err: down}},
+			wantResults: []string{"r1"},
+			wantStages:  []string{StageMetadata},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var stages []string
+			s := &VertexSearchService{}
+			input := SearchInput{Question: "quiet hotel", Mode: SearchModeKeyword}
+			r := s.Retrieve(context.Background(), &Config{Limit: 5}, tt.backends, input, tt.prior, func(stage string, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				stages = append(stages, stage)
+			})
+
+			var results, rows []string
+			for _, res := range r.Results {
+				results = append(results, res.ID)
+			}
+			for _, row := range r.Rows {
+				rows = append(rows, row["id"].(string))
+			}
+			if !slices.Equal(results, tt.wantResults) {
+				t.Errorf("results = %v, want %v", results, tt.wantResults)
+			}
+			if !slices.Equal(rows, tt.wantRows) {
+				t.Errorf("rows = %v, want %v", rows, tt.wantRows)
+			}
+			if !slices.Equal(stages, tt.wantStages) {
+				t.Errorf("failed stages = %v, want %v", stages, tt.wantStages)
+			}
+		})
+	}
+}
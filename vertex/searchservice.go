@@ -4,28 +4,30 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
-	aiplatform "cloud.google.com/go/aiplatform/apiv1"
-	"google.golang.org/api/option"
+	"github.com/chukiagosoftware/alpaca/internal/orm"
 	"google.golang.org/genai"
 )
 
 type VertexSearchService struct {
-	matchClient      *aiplatform.MatchClient
+	searcher         VectorSearcher
 	genaiClient      genai.Client
 	completionRouter *CompletionRouter
-	projectID        string
-	location         string
-	datasetID        string
+	reranker         Reranker
+	rerankCandidates int
+	// queryUnderstanding enables filter extraction from the question in UnderstandQuery
+	queryUnderstanding bool
+	projectID          string
+	location           string
+	datasetID          string
 }
 
-func NewVertexSearchService(ctx context.Context, config *Config) (*VertexSearchService, error) {
-	clientOptions := []option.ClientOption{
-		option.WithEndpoint(fmt.Sprintf("%s:443", config.EndpointPublicDomainName)),
-	}
-	matchClient, err := aiplatform.NewMatchClient(ctx, clientOptions...)
+// NewVertexSearchService builds the search service. db is only used by the pgvector backend and may be nil otherwise.
+func NewVertexSearchService(ctx context.Context, config *Config, db *orm.DB) (*VertexSearchService, error) {
+	searcher, err := NewVectorSearcher(ctx, config, db)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create MatchClient: %w", err)
+		return nil, err
 	}
 
 	clientConfig := genai.ClientConfig{
@@ -39,27 +41,56 @@ func NewVertexSearchService(ctx context.Context, config *Config) (*VertexSearchS
 		return nil, fmt.Errorf("failed to create GenAI client: %w", err)
 	}
 
-	router, err := NewCompletionRouter(config, *client)
+	router, err := NewCompletionRouter(ctx, config, *client)
+	if err != nil {
+		return nil, err
+	}
+
+	reranker, err := NewReranker(config, router)
 	if err != nil {
 		return nil, err
 	}
 
 	return &VertexSearchService{
-		matchClient:      matchClient,
-		genaiClient:      *client,
-		completionRouter: router,
-		projectID:        config.ProjectID,
-		location:         config.Location,
-		datasetID:        config.DatasetID,
+		searcher:           searcher,
+		genaiClient:        *client,
+		completionRouter:   router,
+		reranker:           reranker,
+		rerankCandidates:   config.RerankCandidates,
+		queryUnderstanding: config.QueryUnderstanding,
+		projectID:          config.ProjectID,
+		location:           config.Location,
+		datasetID:          config.DatasetID,
 	}, nil
 }
 
+// NewVectorSearcher selects the vector backend from config.VectorBackend, defaulting to the Vertex index endpoint
+func NewVectorSearcher(ctx context.Context, config *Config, db *orm.DB) (VectorSearcher, error) {
+	switch strings.ToLower(strings.TrimSpace(config.VectorBackend)) {
+	case "", "vertex":
+		return NewVertexMatchSearcher(ctx, config)
+	case "local":
+		return NewLocalSearcherFromConfig(ctx, config)
+	case "qdrant":
+		return NewQdrantSearcher(config), nil
+	case "pgvector":
+		return NewSQLStore(config, db)
+	default:
+		return nil, fmt.Errorf("unknown vector backend: %s", config.VectorBackend)
+	}
+}
+
 func (s *VertexSearchService) Close() {
-	if err := s.matchClient.Close(); err != nil {
-		log.Printf("Failed to close MatchClient: %v", err)
+	if err := s.searcher.Close(); err != nil {
+		log.Printf("Failed to close %s vector searcher: %v", s.searcher.Name(), err)
 	}
 }
 
+// AssignPrompts fixes the completion and safety prompt versions used for the request
+func (s *VertexSearchService) AssignPrompts(input SearchInput) (SearchInput, error) {
+	return s.completionRouter.AssignPrompts(input)
+}
+
 func (s *VertexSearchService) CheckQuerySafety(ctx context.Context, input SearchInput) (bool, error) {
 	return s.completionRouter.CheckQuerySafety(ctx, input)
 }
@@ -67,3 +98,38 @@ func (s *VertexSearchService) CheckQuerySafety(ctx context.Context, input Search
 func (s *VertexSearchService) PromptCompletion(ctx context.Context, input SearchInput, results []map[string]any) (CompletionResult, error) {
 	return s.completionRouter.PromptCompletion(ctx, input, results)
 }
+
+func (s *VertexSearchService) StreamCompletion(ctx context.Context, input SearchInput, results []map[string]any, onChunk func(string)) (CompletionResult, error) {
+	return s.completionRouter.StreamCompletion(ctx, input, results, onChunk)
+}
+
+// RerankerName is empty when reranking is disabled
+func (s *VertexSearchService) RerankerName() string {
+	if s.reranker == nil {
+		return ""
+	}
+	return s.reranker.Name()
+}
+
+// CandidateLimit is the number of results to retrieve for a final limit, widened when a reranker will trim them.
+// rerank_candidates defaults to three times the limit.
+func (s *VertexSearchService) CandidateLimit(limit int) int {
+	if s.reranker == nil {
+		return limit
+	}
+	if s.rerankCandidates <= 0 {
+		return limit * 3
+	}
+	return max(s.rerankCandidates, limit)
+}
+
+// Rerank reorders results and trims them to limit. Without a reranker it only trims.
+func (s *VertexSearchService) Rerank(ctx context.Context, input SearchInput, results []map[string]any, limit int) ([]map[string]any, error) {
+	if s.reranker == nil {
+		if limit > 0 && len(results) > limit {
+			results = results[:limit]
+		}
+		return results, nil
+	}
+	return s.reranker.Rerank(ctx, input, results, limit)
+}
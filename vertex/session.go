@@ -0,0 +1,215 @@
+package vertex
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chukiagosoftware/alpaca/internal/orm"
+)
+
+const (
+	defaultSessionTTL      = 30 * time.Minute
+	defaultSessionMaxTurns = 5
+	sessionSweepInterval   = time.Minute
+)
+
+var ErrSessionNotFound = errors.New("session not found or expired")
+
+// Turn is one answered question of a conversational search session
+type Turn struct {
+	Question  string    `json:"question"`
+	Answer    string    `json:"answer"`
+	ReviewIDs []string  `json:"review_ids"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Session holds the turns of a conversation, oldest first
+type Session struct {
+	ID        string    `json:"id"`
+	Turns     []Turn    `json:"turns"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SessionStore persists conversational sessions. Sessions expire after a TTL of inactivity, AppendTurn creates
+// the session on its first turn and extends the expiry.
+type SessionStore interface {
+	Get(ctx context.Context, id string) (*Session, error)
+	AppendTurn(ctx context.Context, id string, turn Turn) error
+	Delete(ctx context.Context, id string) error
+	Close() error
+}
+
+// NewSessionStore selects the session store from config.SessionStore: "memory" (default) or "sql", which keeps the
+// sessions in db
+func NewSessionStore(config *Config, db *orm.DB) (SessionStore, error) {
+	ttl := defaultSessionTTL
+	if config.SessionTTLMinutes > 0 {
+		ttl = time.Duration(config.SessionTTLMinutes) * time.Minute
+	}
+	maxTurns := defaultSessionMaxTurns
+	if config.SessionMaxTurns > 0 {
+		maxTurns = config.SessionMaxTurns
+	}
+
+	switch strings.ToLower(strings.TrimSpace(config.SessionStore)) {
+	case "", "memory":
+		return NewMemorySessionStore(ttl, maxTurns), nil
+	case "sql", "sqlite":
+		if db == nil {
+			return nil, errors.New("the sql session store needs a database connection")
+		}
+		return NewSQLSessionStore(db, ttl, maxTurns), nil
+	default:
+		return nil, fmt.Errorf("unknown session store: %s", config.SessionStore)
+	}
+}
+
+func NewSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal("Failed to generate session ID:", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// MemorySessionStore keeps sessions in process memory, they are lost on restart
+type MemorySessionStore struct {
+	ttl      time.Duration
+	maxTurns int
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+	stop     chan struct{}
+}
+
+func NewMemorySessionStore(ttl time.Duration, maxTurns int) *MemorySessionStore {
+	s := &MemorySessionStore{
+		ttl:      ttl,
+		maxTurns: maxTurns,
+		sessions: make(map[string]*Session),
+		stop:     make(chan struct{}),
+	}
+	go s.sweep()
+	return s
+}
+
+func (s *MemorySessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok || time.Now().After(session.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	copied := *session
+	copied.Turns = append([]Turn(nil), session.Turns...)
+	return &copied, nil
+}
+
+func (s *MemorySessionStore) AppendTurn(ctx context.Context, id string, turn Turn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok || time.Now().After(session.ExpiresAt) {
+		session = &Session{ID: id}
+		s.sessions[id] = session
+	}
+	if turn.CreatedAt.IsZero() {
+		turn.CreatedAt = time.Now()
+	}
+	session.Turns = append(session.Turns, turn)
+	if len(session.Turns) > s.maxTurns {
+		session.Turns = session.Turns[len(session.Turns)-s.maxTurns:]
+	}
+	session.ExpiresAt = time.Now().Add(s.ttl)
+	return nil
+}
+
+func (s *MemorySessionStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[id]; !ok {
+		return ErrSessionNotFound
+	}
+	delete(s.sessions, id)
+	return nil
+}
+
+func (s *MemorySessionStore) Close() error {
+	close(s.stop)
+	return nil
+}
+
+func (s *MemorySessionStore) sweep() {
+	ticker := time.NewTicker(sessionSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for id, session := range s.sessions {
+				if now.After(session.ExpiresAt) {
+					delete(s.sessions, id)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// SummarizeAnswer condenses parsed completion items to the hotels recommended, which is what follow-up questions
+// refer back to
+func SummarizeAnswer(items []map[string]any) string {
+	var lines []string
+	for _, item := range items {
+		lines = append(lines, fmt.Sprintf("- %v (%v), rated %v", item["Hotel"], item["City"], item["Rating"]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PriorReviewIDs returns the reviews retrieved in the most recent turn
+func (s *Session) PriorReviewIDs() []string {
+	if s == nil || len(s.Turns) == 0 {
+		return nil
+	}
+	return s.Turns[len(s.Turns)-1].ReviewIDs
+}
+
+// conversationQuestion prefixes the question with the earlier turns of the session, so providers can resolve
+// follow-ups like "which of those has parking?"
+func conversationQuestion(input SearchInput) string {
+	if len(input.History) == 0 {
+		return input.Question
+	}
+	var b strings.Builder
+	b.WriteString("Earlier in this conversation:\n")
+	for i, turn := range input.History {
+		fmt.Fprintf(&b, "Question %d: %s\n", i+1, turn.Question)
+		if turn.Answer != "" {
+			fmt.Fprintf(&b, "Recommended:\n%s\n", turn.Answer)
+		}
+	}
+	fmt.Fprintf(&b, "\nFollow-up question, refine the earlier recommendations where it refers to them: %s", input.Question)
+	return b.String()
+}
+
+// FuseSessionResults fuses the reviews retrieved in the previous turn into the current ranking, so follow-ups such
+// as "only the ones with a pool" can still select among the earlier results
+func FuseSessionResults(limit int, results []VectorResult, priorReviewIDs []string) []VectorResult {
+	if len(priorReviewIDs) == 0 {
+		return results
+	}
+	prior := make([]VectorResult, len(priorReviewIDs))
+	for i, id := range priorReviewIDs {
+		prior[i] = VectorResult{ID: id}
+	}
+	return FuseRRF(limit, results, prior)
+}
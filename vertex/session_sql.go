@@ -0,0 +1,141 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/chukiagosoftware/alpaca/internal/orm"
+	"github.com/chukiagosoftware/alpaca/models"
+	"gorm.io/gorm"
+)
+
+// SQLSessionStore persists sessions in the search_sessions and session_turns tables, so they survive restarts
+// and can be shared by several API instances on Postgres
+type SQLSessionStore struct {
+	db       *orm.DB
+	ttl      time.Duration
+	maxTurns int
+	stop     chan struct{}
+}
+
+func NewSQLSessionStore(db *orm.DB, ttl time.Duration, maxTurns int) *SQLSessionStore {
+	s := &SQLSessionStore{db: db, ttl: ttl, maxTurns: maxTurns, stop: make(chan struct{})}
+	go s.sweep()
+	return s
+}
+
+func (s *SQLSessionStore) Get(ctx context.Context, id string) (*Session, error) {
+	// Find rather than First, a missing session is expected and should not be logged as a GORM error
+	var session models.SearchSession
+	result := s.db.WithContext(ctx).Where("id = ? AND expires_at > ?", id, time.Now()).Limit(1).Find(&session)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to load session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrSessionNotFound
+	}
+
+	var rows []models.SessionTurn
+	if err := s.db.WithContext(ctx).Where("session_id = ?", id).Order("id DESC").Limit(s.maxTurns).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load session turns: %w", err)
+	}
+
+	turns := make([]Turn, len(rows))
+	for i, row := range rows {
+		turn := Turn{Question: row.Question, Answer: row.Answer, CreatedAt: row.CreatedAt}
+		if row.ReviewIDs != "" {
+			if err := json.Unmarshal([]byte(row.ReviewIDs), &turn.ReviewIDs); err != nil {
+				log.Printf("Ignoring malformed review IDs of session %s turn %d: %v", id, row.ID, err)
+			}
+		}
+		// rows are newest first
+		turns[len(rows)-1-i] = turn
+	}
+	return &Session{ID: session.ID, Turns: turns, ExpiresAt: session.ExpiresAt}, nil
+}
+
+func (s *SQLSessionStore) AppendTurn(ctx context.Context, id string, turn Turn) error {
+	reviewIDs, err := json.Marshal(turn.ReviewIDs)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var session models.SearchSession
+		result := tx.Where("id = ?", id).Limit(1).Find(&session)
+		switch {
+		case result.Error != nil:
+			return fmt.Errorf("failed to load session: %w", result.Error)
+		case result.RowsAffected == 0:
+			session = models.SearchSession{ID: id}
+		case time.Now().After(session.ExpiresAt):
+			// An expired session that was not swept yet starts over
+			if err := tx.Where("session_id = ?", id).Delete(&models.SessionTurn{}).Error; err != nil {
+				return fmt.Errorf("failed to reset expired session: %w", err)
+			}
+		}
+
+		session.ExpiresAt = time.Now().Add(s.ttl)
+		if err := tx.Save(&session).Error; err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+		row := models.SessionTurn{
+			SessionID: id,
+			Question:  turn.Question,
+			Answer:    turn.Answer,
+			ReviewIDs: string(reviewIDs),
+		}
+		if err := tx.Create(&row).Error; err != nil {
+			return fmt.Errorf("failed to save session turn: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *SQLSessionStore) Delete(ctx context.Context, id string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&models.SearchSession{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete session: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrSessionNotFound
+		}
+		return tx.Where("session_id = ?", id).Delete(&models.SessionTurn{}).Error
+	})
+}
+
+// Close stops the expiry sweep, the connection belongs to the caller of NewSQLSessionStore
+func (s *SQLSessionStore) Close() error {
+	close(s.stop)
+	return nil
+}
+
+func (s *SQLSessionStore) sweep() {
+	ticker := time.NewTicker(sessionSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.deleteExpired(); err != nil {
+				log.Printf("Failed to delete expired sessions: %v", err)
+			}
+		}
+	}
+}
+
+func (s *SQLSessionStore) deleteExpired() error {
+	now := time.Now()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		expired := tx.Model(&models.SearchSession{}).Select("id").Where("expires_at <= ?", now)
+		if err := tx.Where("session_id IN (?)", expired).Delete(&models.SessionTurn{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("expires_at <= ?", now).Delete(&models.SearchSession{}).Error
+	})
+}
@@ -0,0 +1,235 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/chukiagosoftware/alpaca/internal/orm"
+)
+
+// testDatabase opens a migrated SQLite database in a temporary directory, closed when the test ends
+func testDatabase(t *testing.T) *orm.DB {
+	t.Helper()
+	db, err := OpenDatabase(&Config{DatabaseDriver: "sqlite", DatabaseURL: filepath.Join(t.TempDir(), "alpaca.db")})
+	if err != nil {
+		t.Fatalf("OpenDatabase: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// sessionStores builds each SessionStore implementation with the given TTL and turn limit
+func sessionStores(t *testing.T, ttl time.Duration, maxTurns int) map[string]SessionStore {
+	t.Helper()
+	db := testDatabase(t)
+	stores := map[string]SessionStore{
+		"memory": NewMemorySessionStore(ttl, maxTurns),
+		"sql":    NewSQLSessionStore(db, ttl, maxTurns),
+	}
+	t.Cleanup(func() {
+		for _, store := range stores {
+			store.Close()
+		}
+	})
+	return stores
+}
+
+func questions(session *Session) []string {
+	var qs []string
+	for _, turn := range session.Turns {
+		qs = append(qs, turn.Question)
+	}
+	return qs
+}
+
+func TestSessionStores(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxTurns int
+		run      func(t *testing.T, ctx context.Context, store SessionStore)
+	}{
+		{
+			name:     "missing session",
+			maxTurns: 5,
+			run: func(t *testing.T, ctx context.Context, store SessionStore) {
+				if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrSessionNotFound) {
+					t.Errorf("Get error = %v, want ErrSessionNotFound", err)
+				}
+				if err := store.Delete(ctx, "missing"); !errors.Is(err, ErrSessionNotFound) {
+					t.Errorf("Delete error = %v, want ErrSessionNotFound", err)
+				}
+			},
+		},
+		{
+			name:     "turns oldest first with review IDs",
+			maxTurns: 5,
+			run: func(t *testing.T, ctx context.Context, store SessionStore) {
+				mustAppend(t, ctx, store, "s1", Turn{Question: "q1", Answer: "a1", ReviewIDs: []string{"1", "2"}})
+				mustAppend(t, ctx, store, "s1", Turn{Question: "q2", ReviewIDs: []string{"3"}})
+				session, err := store.Get(ctx, "s1")
+				if err != nil {
+					t.Fatalf("Get: %v", err)
+				}
+				if got := questions(session); !slices.Equal(got, []string{"q1", "q2"}) {
+					t.Errorf("questions = %v, want [q1 q2]", got)
+				}
+				if session.Turns[0].Answer != "a1" {
+					t.Errorf("answer = %q, want a1", session.Turns[0].Answer)
+				}
+				if got := session.PriorReviewIDs(); !slices.Equal(got, []string{"3"}) {
+					t.Errorf("PriorReviewIDs = %v, want [3]", got)
+				}
+				if !session.ExpiresAt.After(time.Now()) {
+					t.Errorf("ExpiresAt %v is not in the future", session.ExpiresAt)
+				}
+			},
+		},
+		{
+			name:     "keeps the last max turns",
+			maxTurns: 2,
+			run: func(t *testing.T, ctx context.Context, store SessionStore) {
+				for _, q := range []string{"q1", "q2", "q3"} {
+					mustAppend(t, ctx, store, "s1", Turn{Question: q})
+				}
+				session, err := store.Get(ctx, "s1")
+				if err != nil {
+					t.Fatalf("Get: %v", err)
+				}
+				if got := questions(session); !slices.Equal(got, []string{"q2", "q3"}) {
+					t.Errorf("questions = %v, want [q2 q3]", got)
+				}
+			},
+		},
+		{
+			name:     "sessions are isolated",
+			maxTurns: 5,
+			run: func(t *testing.T, ctx context.Context, store SessionStore) {
+				mustAppend(t, ctx, store, "s1", Turn{Question: "q1"})
+				mustAppend(t, ctx, store, "s2", Turn{Question: "other"})
+				session, err := store.Get(ctx, "s1")
+				if err != nil {
+					t.Fatalf("Get: %v", err)
+				}
+				if got := questions(session); !slices.Equal(got, []string{"q1"}) {
+					t.Errorf("questions = %v, want [q1]", got)
+				}
+			},
+		},
+		{
+			name:     "delete",
+			maxTurns: 5,
+			run: func(t *testing.T, ctx context.Context, store SessionStore) {
+				mustAppend(t, ctx, store, "s1", Turn{Question: "q1"})
+				if err := store.Delete(ctx, "s1"); err != nil {
+					t.Fatalf("Delete: %v", err)
+				}
+				if _, err := store.Get(ctx, "s1"); !errors.Is(err, ErrSessionNotFound) {
+					t.Errorf("Get after Delete error = %v, want ErrSessionNotFound", err)
+				}
+				// A new turn after a delete starts an empty session
+				mustAppend(t, ctx, store, "s1", Turn{Question: "q2"})
+				session, err := store.Get(ctx, "s1")
+				if err != nil {
+					t.Fatalf("Get: %v", err)
+				}
+				if got := questions(session); !slices.Equal(got, []string{"q2"}) {
+					t.Errorf("questions = %v, want [q2]", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		for name, store := range sessionStores(t, time.Hour, tt.maxTurns) {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				tt.run(t, context.Background(), store)
+			})
+		}
+	}
+}
+
+func TestSessionStoresExpiry(t *testing.T) {
+	ctx := context.Background()
+	const ttl = 50 * time.Millisecond
+	for name, store := range sessionStores(t, ttl, 5) {
+		t.Run(name, func(t *testing.T) {
+			mustAppend(t, ctx, store, "s1", Turn{Question: "q1"})
+			time.Sleep(2 * ttl)
+			if _, err := store.Get(ctx, "s1"); !errors.Is(err, ErrSessionNotFound) {
+				t.Fatalf("Get of expired session error = %v, want ErrSessionNotFound", err)
+			}
+
+			// An expired session starts over on its next turn
+			mustAppend(t, ctx, store, "s1", Turn{Question: "q2"})
+			session, err := store.Get(ctx, "s1")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if got := questions(session); !slices.Equal(got, []string{"q2"}) {
+				t.Errorf("questions = %v, want [q2]", got)
+			}
+		})
+	}
+}
+
+func mustAppend(t *testing.T, ctx context.Context, store SessionStore, id string, turn Turn) {
+	t.Helper()
+	if err := store.AppendTurn(ctx, id, turn); err != nil {
+		t.Fatalf("AppendTurn: %v", err)
+	}
+}
+
+func TestNewSessionStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		store   string
+		noDB    bool
+		want    string
+		wantErr bool
+	}{
+		{name: "default", store: "", want: "*vertex.MemorySessionStore"},
+		{name: "memory", store: "Memory", want: "*vertex.MemorySessionStore"},
+		{name: "memory without a database", store: "memory", noDB: true, want: "*vertex.MemorySessionStore"},
+		{name: "sql", store: "sql", want: "*vertex.SQLSessionStore"},
+		{name: "sql without a database", store: "sql", noDB: true, wantErr: true},
+		{name: "unknown", store: "redis", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var db *orm.DB
+			if !tt.noDB {
+				db = testDatabase(t)
+			}
+			store, err := NewSessionStore(&Config{SessionStore: tt.store}, db)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSessionStore error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer store.Close()
+			if got := fmt.Sprintf("%T", store); got != tt.want {
+				t.Errorf("NewSessionStore = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuseSessionResults(t *testing.T) {
+	results := rankingOf("a", "b")
+	if got := FuseSessionResults(10, results, nil); !slices.Equal(got, results) {
+		t.Errorf("without prior reviews = %v, want the results unchanged", got)
+	}
+	fused := FuseSessionResults(10, results, []string{"b", "c"})
+	var ids []string
+	for _, r := range fused {
+		ids = append(ids, r.ID)
+	}
+	if !slices.Equal(ids, []string{"b", "a", "c"}) {
+		t.Errorf("fused IDs = %v, want [b a c]", ids)
+	}
+}
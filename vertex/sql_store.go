@@ -0,0 +1,437 @@
+package vertex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/chukiagosoftware/alpaca/internal/orm"
+	"github.com/chukiagosoftware/alpaca/models"
+	"gorm.io/gorm"
+)
+
+// MetadataProvider hydrates vector results with the review and hotel fields used in the completion prompt.
+// Results keep the vector search order and carry the vector distance.
+type MetadataProvider interface {
+	GetMetadataByIDs(ctx context.Context, vectorResults []VectorResult, config *Config) ([]map[string]any, error)
+}
+
+// MetadataSearcher is implemented by vector backends that can return metadata alongside neighbors in one query
+type MetadataSearcher interface {
+	FindNeighborsWithMetadata(ctx context.Context, queryEmbedding []float32, restricts []Restrict, numericRestricts []NumericRestrict, limit int) ([]VectorResult, []map[string]any, error)
+}
+
+// sqlRestrictColumns whitelists the restrict namespaces that map onto review_embeddings columns
+var sqlRestrictColumns = map[string]string{
+	"city":       "e.city",
+	"country":    "e.country",
+	"continent":  "e.continent",
+	"hotel_name": "e.hotel_name",
+	"rating":     "e.rating",
+}
+
+var sqlNumericOps = map[NumericOp]string{
+	NumericOpLess:         "<",
+	NumericOpLessEqual:    "<=",
+	NumericOpEqual:        "=",
+	NumericOpGreaterEqual: ">=",
+	NumericOpGreater:      ">",
+	NumericOpNotEqual:     "<>",
+}
+
+const sqlMetadataColumns = `
+		e.id,
+		e.review_text,
+		e.rating,
+		e.reviewer_name,
+		e.google_maps_uri,
+		e.photo_name,
+		e.city,
+		e.country,
+		e.continent,
+		e.hotel_name,
+		h.hotel_id,
+		h.street_address,
+		h.important_note`
+
+// sqlVisibleHotel excludes reviews of hotels flagged by an admin, reviews without a hotels row stay visible
+const sqlVisibleHotel = "NOT COALESCE(h.admin_flag, FALSE)"
+
+// sqlKeywordDocument is the text indexed for keyword search on Postgres, the GIN index and the query must use the same expression
+func sqlKeywordDocument(prefix string) string {
+	return fmt.Sprintf("to_tsvector('simple', coalesce(%[1]sreview_text, '') || ' ' || coalesce(%[1]shotel_name, ''))", prefix)
+}
+
+// SQLStore serves review metadata from the review_embeddings and hotels tables. On Postgres with pgvector it is
+// also a VectorSearcher, returning neighbors and metadata from a single query. It is a KeywordSearcher on both
+// drivers, using FTS5 on SQLite (build with -tags sqlite_fts5) and a tsvector GIN index on Postgres.
+type SQLStore struct {
+	db         *orm.DB
+	dimensions int
+	keywordErr error
+}
+
+const (
+	defaultEmbeddingDimensions = 3072
+	// pgvectorMaxIndexDimensions is the largest vector HNSW can index, larger ones are indexed as halfvec
+	pgvectorMaxIndexDimensions = 2000
+)
+
+// OpenDatabase opens and migrates the database from database_driver/database_url, or from the DB_DRIVER environment
+// when unset. The SQL stores share the returned connection, and the caller closes it after them.
+func OpenDatabase(config *Config) (*orm.DB, error) {
+	if config.DatabaseDriver != "" {
+		return orm.OpenDatabase(config.DatabaseDriver, config.DatabaseURL)
+	}
+	return orm.NewDatabase()
+}
+
+func NewSQLStore(config *Config, db *orm.DB) (*SQLStore, error) {
+	if db == nil {
+		return nil, errors.New("the SQL store needs a database connection")
+	}
+
+	store := &SQLStore{db: db, dimensions: defaultEmbeddingDimensions}
+	if config.EmbeddingDimensions > 0 {
+		store.dimensions = config.EmbeddingDimensions
+	}
+	if db.Dialector.Name() == orm.DriverPostgres {
+		// Searches still work without the index, as sequential scans
+		if err := store.ensureVectorIndex(); err != nil {
+			log.Printf("Vector index unavailable: %v", err)
+		}
+	}
+	if err := store.ensureKeywordIndex(); err != nil {
+		log.Printf("Keyword search disabled: %v", err)
+		store.keywordErr = err
+	}
+	return store, nil
+}
+
+// Name is "pgvector" on Postgres and "sqlite" otherwise, where SQLStore only serves metadata and keyword search
+func (s *SQLStore) Name() string {
+	if s.db.Dialector.Name() == orm.DriverPostgres {
+		return "pgvector"
+	}
+	return "sqlite"
+}
+
+// DB exposes the underlying connection for the hotel and review lookups served from the same database
+func (s *SQLStore) DB() *orm.DB {
+	return s.db
+}
+
+// Close is a no-op, the connection belongs to the caller of NewSQLStore
+func (s *SQLStore) Close() error {
+	return nil
+}
+
+func (s *SQLStore) FindNeighbors(ctx context.Context, queryEmbedding []float32, restricts []Restrict, numericRestricts []NumericRestrict, limit int) ([]VectorResult, error) {
+	results, _, err := s.FindNeighborsWithMetadata(ctx, queryEmbedding, restricts, numericRestricts, limit)
+	return results, err
+}
+
+// FindNeighborsWithMetadata ranks by pgvector cosine distance (<=>) so the HNSW index serves the query. Distance is
+// reported as the cosine similarity, which matches the DOT_PRODUCT_DISTANCE scores Vertex returns for its
+// UNIT_L2_NORM embeddings.
+func (s *SQLStore) FindNeighborsWithMetadata(ctx context.Context, queryEmbedding []float32, restricts []Restrict, numericRestricts []NumericRestrict, limit int) ([]VectorResult, []map[string]any, error) {
+	if s.db.Dialector.Name() != orm.DriverPostgres {
+		return nil, nil, fmt.Errorf("pgvector search requires the postgres driver, got %s", s.db.Dialector.Name())
+	}
+
+	query, err := models.Vector(queryEmbedding).Value()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	where, args, err := sqlRestrictClause(restricts, numericRestricts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if where == "" {
+		where = "WHERE " + sqlVisibleHotel
+	} else {
+		where += " AND " + sqlVisibleHotel
+	}
+
+	column, cast := s.vectorExpression("e.")
+	sql := fmt.Sprintf(`
+		SELECT %[1]s,
+		       1 - (%[2]s <=> ?::%[3]s) AS distance
+		FROM review_embeddings e
+		LEFT JOIN hotels h ON h.name = e.hotel_name
+		%[4]s
+		ORDER BY %[2]s <=> ?::%[3]s
+		LIMIT ?`, sqlMetadataColumns, column, cast, where)
+
+	params := append([]any{query}, args...)
+	params = append(params, query, limit)
+
+	var rows []map[string]any
+	if err := s.db.WithContext(ctx).Raw(sql, params...).Scan(&rows).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to find neighbors: %w", err)
+	}
+
+	results := make([]VectorResult, 0, len(rows))
+	for _, row := range rows {
+		distance, _ := toFloat64(row["distance"])
+		results = append(results, VectorResult{
+			ID:       fmt.Sprintf("%v", row["id"]),
+			Distance: distance,
+		})
+		row["distance"] = distance
+	}
+	return results, rows, nil
+}
+
+// GetMetadataByIDs works on both SQLite and Postgres, so it can back any vector backend during offline development
+func (s *SQLStore) GetMetadataByIDs(ctx context.Context, vectorResults []VectorResult, config *Config) ([]map[string]any, error) {
+	if len(vectorResults) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, 0, len(vectorResults))
+	for _, vr := range vectorResults {
+		if i, err := strconv.ParseInt(vr.ID, 10, 64); err == nil {
+			ids = append(ids, i)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	sql := fmt.Sprintf(`
+		SELECT %s
+		FROM review_embeddings e
+		LEFT JOIN hotels h ON h.name = e.hotel_name
+		WHERE e.id IN ? AND %s`, sqlMetadataColumns, sqlVisibleHotel)
+
+	var rows []map[string]any
+	if err := s.db.WithContext(ctx).Raw(sql, ids).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("metadata query failed: %w", err)
+	}
+
+	metadataMap := make(map[string]map[string]any, len(rows))
+	for _, row := range rows {
+		metadataMap[fmt.Sprintf("%v", row["id"])] = row
+	}
+
+	// Rebuild results in original vector search order, attaching distance
+	var finalResults []map[string]any
+	for _, vr := range vectorResults {
+		if meta, ok := metadataMap[vr.ID]; ok {
+			meta["distance"] = vr.Distance
+			finalResults = append(finalResults, meta)
+		}
+	}
+	return finalResults, nil
+}
+
+// HotelsInBox makes SQLStore a HotelLocator for geo-radius search
+func (s *SQLStore) HotelsInBox(ctx context.Context, minLat, maxLat, minLng, maxLng float64) ([]HotelCoordinate, error) {
+	hotels, err := s.db.GetHotelsInBox(ctx, minLat, maxLat, minLng, maxLng)
+	if err != nil {
+		return nil, fmt.Errorf("hotel coordinate query failed: %w", err)
+	}
+	coords := make([]HotelCoordinate, 0, len(hotels))
+	for _, h := range hotels {
+		coords = append(coords, HotelCoordinate{HotelID: h.HotelID, Name: h.Name, City: h.City, Lat: h.Latitude, Lng: h.Longitude})
+	}
+	return coords, nil
+}
+
+// vectorExpression is the embedding column as indexed by ensureVectorIndex and the type to cast the query to.
+// Queries must use the same expression for Postgres to pick the index.
+func (s *SQLStore) vectorExpression(prefix string) (string, string) {
+	if s.dimensions > pgvectorMaxIndexDimensions {
+		cast := fmt.Sprintf("halfvec(%d)", s.dimensions)
+		return fmt.Sprintf("(%sembedding::%s)", prefix, cast), cast
+	}
+	cast := fmt.Sprintf("vector(%d)", s.dimensions)
+	return prefix + "embedding", cast
+}
+
+// ensureVectorIndex sizes the embedding column to the configured dimensions and creates the HNSW cosine index
+func (s *SQLStore) ensureVectorIndex() error {
+	var current int
+	err := s.db.Raw(`SELECT atttypmod FROM pg_attribute
+		WHERE attrelid = 'review_embeddings'::regclass AND attname = 'embedding'`).Scan(&current).Error
+	if err != nil {
+		return fmt.Errorf("failed to read embedding column type: %w", err)
+	}
+	if current != s.dimensions {
+		alter := fmt.Sprintf("ALTER TABLE review_embeddings ALTER COLUMN embedding TYPE vector(%d)", s.dimensions)
+		if err := s.db.Exec(alter).Error; err != nil {
+			return fmt.Errorf("failed to size embedding column to %d dimensions: %w", s.dimensions, err)
+		}
+	}
+
+	column, _ := s.vectorExpression("")
+	opclass := "vector_cosine_ops"
+	if s.dimensions > pgvectorMaxIndexDimensions {
+		opclass = "halfvec_cosine_ops"
+	}
+	index := fmt.Sprintf("CREATE INDEX IF NOT EXISTS review_embeddings_embedding_hnsw_idx ON review_embeddings USING hnsw (%s %s)", column, opclass)
+	if err := s.db.Exec(index).Error; err != nil {
+		return fmt.Errorf("failed to create embedding index: %w", err)
+	}
+	return nil
+}
+
+// ensureKeywordIndex creates the full text index over review_embeddings, kept in sync by triggers on SQLite
+func (s *SQLStore) ensureKeywordIndex() error {
+	if s.db.Dialector.Name() == orm.DriverPostgres {
+		return s.db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS review_embeddings_fts_idx ON review_embeddings USING GIN (%s)", sqlKeywordDocument(""))).Error
+	}
+
+	var exists int64
+	if err := s.db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'review_embeddings_fts'").Scan(&exists).Error; err != nil {
+		return err
+	}
+	if exists > 0 {
+		return nil
+	}
+
+	statements := []string{
+		`CREATE VIRTUAL TABLE review_embeddings_fts USING fts5(
+			review_text, hotel_name, content='review_embeddings', content_rowid='id')`,
+		`CREATE TRIGGER review_embeddings_fts_ai AFTER INSERT ON review_embeddings BEGIN
+			INSERT INTO review_embeddings_fts(rowid, review_text, hotel_name) VALUES (new.id, new.review_text, new.hotel_name);
+		END`,
+		`CREATE TRIGGER review_embeddings_fts_ad AFTER DELETE ON review_embeddings BEGIN
+			INSERT INTO review_embeddings_fts(review_embeddings_fts, rowid, review_text, hotel_name) VALUES ('delete', old.id, old.review_text, old.hotel_name);
+		END`,
+		`CREATE TRIGGER review_embeddings_fts_au AFTER UPDATE ON review_embeddings BEGIN
+			INSERT INTO review_embeddings_fts(review_embeddings_fts, rowid, review_text, hotel_name) VALUES ('delete', old.id, old.review_text, old.hotel_name);
+			INSERT INTO review_embeddings_fts(rowid, review_text, hotel_name) VALUES (new.id, new.review_text, new.hotel_name);
+		END`,
+		`INSERT INTO review_embeddings_fts(review_embeddings_fts) VALUES ('rebuild')`,
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range statements {
+			if err := tx.Exec(stmt).Error; err != nil {
+				if strings.Contains(err.Error(), "no such module: fts5") {
+					return fmt.Errorf("SQLite was built without FTS5, rebuild with -tags sqlite_fts5: %w", err)
+				}
+				return fmt.Errorf("failed to create FTS5 index: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindKeyword matches any of the terms, ranked by bm25 on SQLite and ts_rank on Postgres
+func (s *SQLStore) FindKeyword(ctx context.Context, terms []string, restricts []Restrict, numericRestricts []NumericRestrict, limit int) ([]VectorResult, error) {
+	if s.keywordErr != nil {
+		return nil, fmt.Errorf("keyword index unavailable: %w", s.keywordErr)
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	where, args, err := sqlRestrictClause(restricts, numericRestricts)
+	if err != nil {
+		return nil, err
+	}
+	and := func(condition string) string {
+		if where == "" {
+			return "WHERE " + condition
+		}
+		return where + " AND " + condition
+	}
+
+	var sql string
+	var params []any
+	if s.db.Dialector.Name() == orm.DriverPostgres {
+		query := strings.Join(terms, " | ")
+		document := sqlKeywordDocument("e.")
+		sql = fmt.Sprintf(`
+			SELECT e.id, ts_rank(%[1]s, to_tsquery('simple', ?)) AS score
+			FROM review_embeddings e
+			%[2]s
+			ORDER BY score DESC
+			LIMIT ?`, document, and(document+" @@ to_tsquery('simple', ?)"))
+		params = append([]any{query}, args...)
+		params = append(params, query, limit)
+	} else {
+		quoted := make([]string, len(terms))
+		for i, t := range terms {
+			quoted[i] = `"` + t + `"`
+		}
+		sql = fmt.Sprintf(`
+			SELECT e.id, -bm25(review_embeddings_fts) AS score
+			FROM review_embeddings_fts
+			JOIN review_embeddings e ON e.id = review_embeddings_fts.rowid
+			%s
+			ORDER BY bm25(review_embeddings_fts)
+			LIMIT ?`, and("review_embeddings_fts MATCH ?"))
+		params = append(args, strings.Join(quoted, " OR "), limit)
+	}
+
+	var rows []struct {
+		ID    int64
+		Score float64
+	}
+	if err := s.db.WithContext(ctx).Raw(sql, params...).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("keyword search failed: %w", err)
+	}
+
+	results := make([]VectorResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, VectorResult{ID: strconv.FormatInt(row.ID, 10), Distance: row.Score})
+	}
+	return results, nil
+}
+
+func sqlRestrictClause(restricts []Restrict, numericRestricts []NumericRestrict) (string, []any, error) {
+	var conditions []string
+	var args []any
+	for _, r := range restricts {
+		column, ok := sqlRestrictColumns[r.Namespace]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported restrict namespace: %s", r.Namespace)
+		}
+		conditions = append(conditions, column+" IN ?")
+		args = append(args, r.AllowList)
+	}
+	for _, r := range numericRestricts {
+		column, ok := sqlRestrictColumns[r.Namespace]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported numeric restrict namespace: %s", r.Namespace)
+		}
+		op, ok := sqlNumericOps[r.Op]
+		if !ok {
+			op = "="
+		}
+		conditions = append(conditions, fmt.Sprintf("%s %s ?", column, op))
+		args = append(args, r.ValueInt)
+	}
+	if len(conditions) == 0 {
+		return "", nil, nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	case []byte:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
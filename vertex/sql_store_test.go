@@ -0,0 +1,22 @@
+package vertex
+
+import "testing"
+
+func TestSQLStoreVectorExpression(t *testing.T) {
+	tests := []struct {
+		dimensions int
+		wantColumn string
+		wantCast   string
+	}{
+		{dimensions: 768, wantColumn: "e.embedding", wantCast: "vector(768)"},
+		{dimensions: 2000, wantColumn: "e.embedding", wantCast: "vector(2000)"},
+		{dimensions: 3072, wantColumn: "(e.embedding::halfvec(3072))", wantCast: "halfvec(3072)"},
+	}
+	for _, tt := range tests {
+		s := &SQLStore{dimensions: tt.dimensions}
+		column, cast := s.vectorExpression("e.")
+		if column != tt.wantColumn || cast != tt.wantCast {
+			t.Errorf("vectorExpression(%d) = %q, %q, want %q, %q", tt.dimensions, column, cast, tt.wantColumn, tt.wantCast)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package stores
 
 import (
 	"log"
@@ -30,9 +30,12 @@ type PlacesResponse struct {
 }
 
 type BQ struct {
-	BQClient  *bigquery.Client
-	ProjectID string
-	DatasetID string
+	BQClient        *bigquery.Client
+	ProjectID       string
+	DatasetID       string
+	HotelsTable     string
+	ReviewsTable    string
+	EmbeddingsTable string
 }
 
 func (bq *BQ) ExecuteQuery(ctx context.Context, query string, params []bigquery.QueryParameter) (*bigquery.RowIterator, error) {
@@ -57,9 +60,12 @@ func NewBigQueryService(ctx context.Context, config vertex.Config) (*BQ, error)
 	}
 	log.Printf("DEBUG: BigQuery client created successfully")
 	return &BQ{
-		BQClient:  bqClient,
-		ProjectID: config.ProjectID,
-		DatasetID: config.DatasetID,
+		BQClient:        bqClient,
+		ProjectID:       config.ProjectID,
+		DatasetID:       config.DatasetID,
+		HotelsTable:     config.BigHotels,
+		ReviewsTable:    config.BigReviews,
+		EmbeddingsTable: config.BigReviewEmbeddings,
 	}, nil
 }
 
@@ -104,10 +110,13 @@ func (bq *BQ) GetMetadataByIDs(ctx context.Context, vectorResults []vertex.Vecto
 			e.country,
 			e.continent,
 			e.hotel_name,
-			h.street_address
+			h.hotel_id,
+			h.street_address,
+			h.important_note
 		FROM %s e
 		JOIN %s h ON h.name = e.hotel_name
 		WHERE e.id IN UNNEST(@ids)
+		  AND NOT IFNULL(h.admin_flag, FALSE)
 	`, tableEmbed, tableHotels)
 
 	params := []bigquery.QueryParameter{
@@ -318,3 +327,19 @@ func (bq *BQ) RefreshPhotoNames(ctx context.Context, config *vertex.Config) erro
 
 	return nil
 }
+
+// Ping dry-runs a query on the hotels table, which checks credentials, the dataset and the table without billing
+func (bq *BQ) Ping(ctx context.Context) error {
+	tableHotels := fmt.Sprintf("%s.%s.%s", bq.ProjectID, bq.DatasetID, bq.HotelsTable)
+	q := bq.BQClient.Query(fmt.Sprintf("SELECT hotel_id FROM `%s` LIMIT 1", tableHotels))
+	q.DryRun = true
+
+	job, err := q.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("dry run failed: %w", err)
+	}
+	if err := job.LastStatus().Err(); err != nil {
+		return fmt.Errorf("dry run failed: %w", err)
+	}
+	return nil
+}
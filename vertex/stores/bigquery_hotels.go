@@ -0,0 +1,164 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+
+	"github.com/chukiagosoftware/alpaca/internal/orm"
+	"github.com/chukiagosoftware/alpaca/models"
+	"github.com/chukiagosoftware/alpaca/vertex"
+)
+
+// ErrHotelNotFound is returned by BQ.GetHotel for an unknown hotel ID
+var ErrHotelNotFound = errors.New("hotel not found")
+
+func (bq *BQ) GetHotel(ctx context.Context, hotelID string) (*models.Hotel, error) {
+	tableHotels := fmt.Sprintf("%s.%s.%s", bq.ProjectID, bq.DatasetID, bq.HotelsTable)
+	sql := fmt.Sprintf("SELECT * FROM %s WHERE hotel_id = @id LIMIT 1", tableHotels)
+	params := []bigquery.QueryParameter{
+		{Name: "id", Value: hotelID},
+	}
+
+	it, err := bq.ExecuteQuery(ctx, sql, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var hotel models.Hotel
+	err = it.Next(&hotel)
+	if err == iterator.Done {
+		return nil, ErrHotelNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hotel: %w", err)
+	}
+	return &hotel, nil
+}
+
+func (bq *BQ) GetReviewsPage(ctx context.Context, hotelID string, q orm.ReviewQuery) ([]*models.HotelReview, int64, error) {
+	tableReviews := fmt.Sprintf("%s.%s.%s", bq.ProjectID, bq.DatasetID, bq.ReviewsTable)
+
+	where := "WHERE hotel_id = @id"
+	params := []bigquery.QueryParameter{
+		{Name: "id", Value: hotelID},
+	}
+	if q.Source != "" {
+		where += " AND source = @source"
+		params = append(params, bigquery.QueryParameter{Name: "source", Value: q.Source})
+	}
+
+	countIt, err := bq.ExecuteQuery(ctx, fmt.Sprintf("SELECT COUNT(*) AS total FROM %s %s", tableReviews, where), params)
+	if err != nil {
+		return nil, 0, err
+	}
+	var count struct {
+		Total int64 `bigquery:"total"`
+	}
+	if err := countIt.Next(&count); err != nil {
+		return nil, 0, fmt.Errorf("failed to count reviews: %w", err)
+	}
+
+	sql := fmt.Sprintf("SELECT * FROM %s %s ORDER BY %s LIMIT @limit OFFSET @offset", tableReviews, where, q.OrderClause())
+	params = append(params,
+		bigquery.QueryParameter{Name: "limit", Value: q.Limit},
+		bigquery.QueryParameter{Name: "offset", Value: q.Offset},
+	)
+	it, err := bq.ExecuteQuery(ctx, sql, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var reviews []*models.HotelReview
+	for {
+		var r models.HotelReview
+		err := it.Next(&r)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read review: %w", err)
+		}
+		reviews = append(reviews, &r)
+	}
+	return reviews, count.Total, nil
+}
+
+// HotelsInBox makes BQ a vertex.HotelLocator for geo-radius search
+func (bq *BQ) HotelsInBox(ctx context.Context, minLat, maxLat, minLng, maxLng float64) ([]vertex.HotelCoordinate, error) {
+	tableHotels := fmt.Sprintf("%s.%s.%s", bq.ProjectID, bq.DatasetID, bq.HotelsTable)
+	sql := fmt.Sprintf(`
+		SELECT hotel_id, name, city, latitude, longitude
+		FROM %s
+		WHERE latitude BETWEEN @min_lat AND @max_lat
+		  AND longitude BETWEEN @min_lng AND @max_lng
+		  AND NOT (latitude = 0 AND longitude = 0)`, tableHotels)
+	params := []bigquery.QueryParameter{
+		{Name: "min_lat", Value: minLat},
+		{Name: "max_lat", Value: maxLat},
+		{Name: "min_lng", Value: minLng},
+		{Name: "max_lng", Value: maxLng},
+	}
+
+	it, err := bq.ExecuteQuery(ctx, sql, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var coords []vertex.HotelCoordinate
+	for {
+		var row struct {
+			HotelID   string  `bigquery:"hotel_id"`
+			Name      string  `bigquery:"name"`
+			City      string  `bigquery:"city"`
+			Latitude  float64 `bigquery:"latitude"`
+			Longitude float64 `bigquery:"longitude"`
+		}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hotel coordinates: %w", err)
+		}
+		coords = append(coords, vertex.HotelCoordinate{HotelID: row.HotelID, Name: row.Name, City: row.City, Lat: row.Latitude, Lng: row.Longitude})
+	}
+	return coords, nil
+}
+
+// UpdateAdminFlag makes BQ a HotelModerator
+func (bq *BQ) UpdateAdminFlag(ctx context.Context, hotelID string, disabled bool) error {
+	return bq.updateHotel(ctx, hotelID, "admin_flag = @admin_flag",
+		bigquery.QueryParameter{Name: "admin_flag", Value: disabled})
+}
+
+func (bq *BQ) UpdateRecommendationFields(ctx context.Context, hotelID string, recommended, quality, quiet bool, importantNote string) error {
+	return bq.updateHotel(ctx, hotelID, "recommended = @recommended, quality = @quality, quiet = @quiet, important_note = @important_note",
+		bigquery.QueryParameter{Name: "recommended", Value: recommended},
+		bigquery.QueryParameter{Name: "quality", Value: quality},
+		bigquery.QueryParameter{Name: "quiet", Value: quiet},
+		bigquery.QueryParameter{Name: "important_note", Value: importantNote})
+}
+
+// updateHotel runs an UPDATE on the hotels table and waits for it, so the change is visible to the next search
+func (bq *BQ) updateHotel(ctx context.Context, hotelID, set string, params ...bigquery.QueryParameter) error {
+	tableHotels := fmt.Sprintf("%s.%s.%s", bq.ProjectID, bq.DatasetID, bq.HotelsTable)
+	q := bq.BQClient.Query(fmt.Sprintf("UPDATE %s SET %s WHERE hotel_id = @id", tableHotels, set))
+	q.Parameters = append(params, bigquery.QueryParameter{Name: "id", Value: hotelID})
+
+	job, err := q.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update hotel %s: %w", hotelID, err)
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update hotel %s: %w", hotelID, err)
+	}
+	if err := status.Err(); err != nil {
+		return fmt.Errorf("failed to update hotel %s: %w", hotelID, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+)
+
+var bqRestrictColumns = map[string]string{
+	"city":       "e.city",
+	"country":    "e.country",
+	"continent":  "e.continent",
+	"hotel_name": "e.hotel_name",
+	"rating":     "e.rating",
+}
+
+var bqNumericOps = map[vertex.NumericOp]string{
+	vertex.NumericOpLess:         "<",
+	vertex.NumericOpLessEqual:    "<=",
+	vertex.NumericOpEqual:        "=",
+	vertex.NumericOpGreaterEqual: ">=",
+	vertex.NumericOpGreater:      ">",
+	vertex.NumericOpNotEqual:     "!=",
+}
+
+func (bq *BQ) Name() string { return "bigquery" }
+
+// FindKeyword uses BigQuery SEARCH on the review embeddings table, which benefits from a search index on review_text.
+// SEARCH only filters, so results are ranked by how many of the terms each review contains.
+func (bq *BQ) FindKeyword(ctx context.Context, terms []string, restricts []vertex.Restrict, numericRestricts []vertex.NumericRestrict, limit int) ([]vertex.VectorResult, error) {
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	conditions := []string{"SEARCH(e.review_text, @query)"}
+	params := []bigquery.QueryParameter{
+		{Name: "query", Value: strings.Join(terms, " OR ")},
+		{Name: "terms", Value: terms},
+		{Name: "limit", Value: limit},
+	}
+	for i, r := range restricts {
+		column, ok := bqRestrictColumns[r.Namespace]
+		if !ok {
+			return nil, fmt.Errorf("unsupported restrict namespace: %s", r.Namespace)
+		}
+		name := fmt.Sprintf("r%d", i)
+		conditions = append(conditions, fmt.Sprintf("%s IN UNNEST(@%s)", column, name))
+		params = append(params, bigquery.QueryParameter{Name: name, Value: r.AllowList})
+	}
+	for i, r := range numericRestricts {
+		column, ok := bqRestrictColumns[r.Namespace]
+		if !ok {
+			return nil, fmt.Errorf("unsupported numeric restrict namespace: %s", r.Namespace)
+		}
+		op, ok := bqNumericOps[r.Op]
+		if !ok {
+			op = "="
+		}
+		name := fmt.Sprintf("n%d", i)
+		conditions = append(conditions, fmt.Sprintf("%s %s @%s", column, op, name))
+		params = append(params, bigquery.QueryParameter{Name: name, Value: r.ValueInt})
+	}
+
+	tableEmbed := fmt.Sprintf("%s.%s.%s", bq.ProjectID, bq.DatasetID, bq.EmbeddingsTable)
+	sql := fmt.Sprintf(`
+		SELECT
+			e.id,
+			(SELECT COUNT(*) FROM UNNEST(@terms) AS t WHERE CONTAINS_SUBSTR(e.review_text, t)) AS score
+		FROM %s e
+		WHERE %s
+		ORDER BY score DESC, e.rating DESC
+		LIMIT @limit
+	`, tableEmbed, strings.Join(conditions, " AND "))
+
+	it, err := bq.ExecuteQuery(ctx, sql, params)
+	if err != nil {
+		return nil, fmt.Errorf("keyword query failed: %w", err)
+	}
+
+	var results []vertex.VectorResult
+	for {
+		var row struct {
+			ID    int64 `bigquery:"id"`
+			Score int64 `bigquery:"score"`
+		}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+		results = append(results, vertex.VectorResult{ID: fmt.Sprintf("%d", row.ID), Distance: float64(row.Score)})
+	}
+	return results, nil
+}
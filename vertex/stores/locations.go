@@ -0,0 +1,109 @@
+package stores
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chukiagosoftware/alpaca/vertex"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	locationCacheTTL = time.Hour
+	// locationRetryBackoff is how long a failed load is remembered, so a failing BigQuery is not queried per search
+	locationRetryBackoff = 30 * time.Second
+	locationLoadTimeout  = 30 * time.Second
+)
+
+// locationLister is the BigQuery query the catalog is loaded from
+type locationLister interface {
+	GetDistinctLocations(ctx context.Context) ([]LocationGroup, error)
+}
+
+// LocationCache keeps the known locations used to validate filters inferred from the question,
+// so query understanding does not run a BigQuery aggregation per search
+type LocationCache struct {
+	source locationLister
+	// loads lets concurrent searches share one query, which runs without holding mu
+	loads singleflight.Group
+
+	mu       sync.Mutex
+	catalog  *vertex.LocationCatalog
+	loaded   time.Time
+	failedAt time.Time
+}
+
+func NewLocationCache(bq *BQ) *LocationCache {
+	if bq == nil {
+		return &LocationCache{}
+	}
+	return &LocationCache{source: bq}
+}
+
+// Catalog returns the cached catalog, reloading it once the TTL has passed. A failed reload keeps serving the
+// previous catalog and is not retried before locationRetryBackoff; nil means locations were never loaded and no
+// location will be inferred.
+func (l *LocationCache) Catalog(ctx context.Context) *vertex.LocationCatalog {
+	if l == nil || l.source == nil {
+		return nil
+	}
+	l.mu.Lock()
+	catalog := l.catalog
+	fresh := catalog != nil && time.Since(l.loaded) < locationCacheTTL
+	backingOff := !l.failedAt.IsZero() && time.Since(l.failedAt) < locationRetryBackoff
+	l.mu.Unlock()
+	if fresh || backingOff {
+		return catalog
+	}
+
+	loaded := l.loads.DoChan("catalog", func() (any, error) {
+		// The load is shared, so it must not be cancelled along with the search that started it
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), locationLoadTimeout)
+		defer cancel()
+		return l.load(ctx), nil
+	})
+	select {
+	case res := <-loaded:
+		return res.Val.(*vertex.LocationCatalog)
+	case <-ctx.Done():
+		return catalog
+	}
+}
+
+func (l *LocationCache) load(ctx context.Context) *vertex.LocationCatalog {
+	groups, err := l.source.GetDistinctLocations(ctx)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		log.Printf("Failed to load locations for query understanding: %v", err)
+		l.failedAt = time.Now()
+		return l.catalog
+	}
+	l.catalog = vertex.NewLocationCatalog(locationsFromGroups(groups))
+	l.loaded = time.Now()
+	l.failedAt = time.Time{}
+	return l.catalog
+}
+
+// locationsFromGroups splits the "City, Country" labels returned by GetDistinctLocations
+func locationsFromGroups(groups []LocationGroup) []vertex.Location {
+	var locations []vertex.Location
+	for _, g := range groups {
+		for _, cc := range g.CityCountries {
+			i := strings.LastIndex(cc, ", ")
+			if i < 0 {
+				continue
+			}
+			locations = append(locations, vertex.Location{
+				City:      cc[:i],
+				Country:   cc[i+2:],
+				Continent: g.Continent,
+			})
+		}
+	}
+	return locations
+}
@@ -0,0 +1,112 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLocations answers GetDistinctLocations with groups or err once release is closed, counting the queries
+type fakeLocations struct {
+	groups  []LocationGroup
+	err     error
+	release chan struct{}
+	queries atomic.Int32
+}
+
+func (f *fakeLocations) GetDistinctLocations(ctx context.Context) ([]LocationGroup, error) {
+	f.queries.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	return f.groups, f.err
+}
+
+var parisGroups = []LocationGroup{{Continent: "Europe", CityCountries: []string{"Paris, France"}}}
+
+func TestLocationCacheCatalog(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      *fakeLocations
+		age         time.Duration
+		calls       int
+		wantQueries int32
+		wantCatalog bool
+	}{
+		{name: "loaded once", source: &fakeLocations{groups: parisGroups}, calls: 3, wantQueries: 1, wantCatalog: true},
+		{name: "failure is backed off", source: &fakeLocations{err: errors.New("bigquery down")}, calls: 3, wantQueries: 1},
+		{name: "expired catalog is reloaded", source: &fakeLocations{groups: parisGroups}, age: locationCacheTTL, calls: 2, wantQueries: 2, wantCatalog: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &LocationCache{source: tt.source}
+			ctx := context.Background()
+			for range tt.calls {
+				catalog := cache.Catalog(ctx)
+				if (catalog != nil) != tt.wantCatalog {
+					t.Fatalf("Catalog = %v, want a catalog %v", catalog, tt.wantCatalog)
+				}
+				cache.mu.Lock()
+				cache.loaded = cache.loaded.Add(-tt.age)
+				cache.mu.Unlock()
+			}
+			if got := tt.source.queries.Load(); got != tt.wantQueries {
+				t.Errorf("queries = %d, want %d", got, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestLocationCacheFailedReloadKeepsCatalog(t *testing.T) {
+	source := &fakeLocations{groups: parisGroups}
+	cache := &LocationCache{source: source}
+	first := cache.Catalog(context.Background())
+
+	source.err = errors.New("bigquery down")
+	cache.loaded = time.Now().Add(-locationCacheTTL)
+	if got := cache.Catalog(context.Background()); got != first {
+		t.Errorf("Catalog after a failed reload = %p, want the previous catalog %p", got, first)
+	}
+	if _, ok := first.MatchCity("paris", ""); !ok {
+		t.Error("catalog does not know Paris")
+	}
+}
+
+func TestLocationCacheSharesConcurrentLoads(t *testing.T) {
+	source := &fakeLocations{groups: parisGroups, release: make(chan struct{})}
+	cache := &LocationCache{source: source}
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if cache.Catalog(context.Background()) == nil {
+				t.Error("Catalog = nil, want the loaded catalog")
+			}
+		}()
+	}
+	// The cache lock is not held during the query, so a cancelled search gives up without waiting for it
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := cache.Catalog(ctx); got != nil {
+		t.Errorf("Catalog of a cancelled search = %v, want nil before the first load", got)
+	}
+	close(source.release)
+	wg.Wait()
+	if got := source.queries.Load(); got != 1 {
+		t.Errorf("queries = %d, want 1", got)
+	}
+}
+
+func TestLocationCacheWithoutBigQuery(t *testing.T) {
+	var nilCache *LocationCache
+	for _, cache := range []*LocationCache{nilCache, NewLocationCache(nil)} {
+		if got := cache.Catalog(context.Background()); got != nil {
+			t.Errorf("Catalog = %v, want nil", got)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/chukiagosoftware/alpaca/internal/orm"
+	"github.com/chukiagosoftware/alpaca/vertex"
+)
+
+// Stores are what a search reads from besides the vector backend, selected from config.MetadataBackend. BigQuery
+// serves metadata, keyword search and hotel coordinates unless metadata_backend is sql; then the SQL store does and
+// BigQuery, when available, only backs the location catalog and photo name refreshes.
+type Stores struct {
+	Retrieval vertex.RetrievalBackends
+	// BigQuery is nil when metadata_backend is sql and no BigQuery client could be created
+	BigQuery *BQ
+	// SQL is the metadata store of metadata_backend sql, nil otherwise
+	SQL *vertex.SQLStore
+}
+
+// Open connects the stores selected by config. db is the shared database, required for metadata_backend sql.
+func Open(ctx context.Context, config *vertex.Config, db *orm.DB) (*Stores, error) {
+	s := &Stores{}
+	sqlMetadata := strings.EqualFold(config.MetadataBackend, "sql")
+
+	bq, err := NewBigQueryService(ctx, *config)
+	if err != nil {
+		if !sqlMetadata {
+			return nil, fmt.Errorf("failed to create BigQuery service: %w", err)
+		}
+		log.Printf("Warning: BigQuery disabled: %v", err)
+	} else {
+		s.BigQuery = bq
+		s.Retrieval = vertex.RetrievalBackends{Metadata: bq, Keyword: bq, Hotels: bq}
+	}
+	s.Retrieval.Locations = NewLocationCache(s.BigQuery)
+
+	if sqlMetadata {
+		store, err := vertex.NewSQLStore(config, db)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to open SQL metadata store: %w", err)
+		}
+		s.SQL = store
+		s.Retrieval.Metadata = store
+		s.Retrieval.Keyword = store
+		s.Retrieval.Hotels = store
+	}
+	return s, nil
+}
+
+// Close closes the BigQuery client. The SQL store shares the database, which is closed by whoever opened it.
+func (s *Stores) Close() {
+	if s.BigQuery == nil {
+		return
+	}
+	if err := s.BigQuery.Close(); err != nil {
+		log.Printf("Failed to close BigQuery client: %v", err)
+	}
+}
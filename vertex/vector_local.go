@@ -0,0 +1,258 @@
+package vertex
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chukiagosoftware/alpaca/internal/orm"
+	"github.com/chukiagosoftware/alpaca/models"
+)
+
+// LocalDatapoint is one review embedding held in memory by LocalSearcher
+type LocalDatapoint struct {
+	ID               string
+	Embedding        []float32
+	Restricts        map[string][]string
+	NumericRestricts map[string]float64
+}
+
+// localJSONDatapoint is the Vertex AI Vector Search JSONL import format, so the same export feeds both backends
+type localJSONDatapoint struct {
+	ID               json.RawMessage        `json:"id"`
+	Embedding        []float32              `json:"embedding"`
+	Restricts        []Restrict             `json:"restricts"`
+	NumericRestricts []localNumericRestrict `json:"numeric_restricts"`
+}
+
+// localNumericRestrict holds one of the typed values of a Vertex numeric restrict. The rating column of
+// importBigIndex.json is a FLOAT, so exports carry value_float.
+type localNumericRestrict struct {
+	Namespace   string   `json:"namespace"`
+	ValueInt    *int64   `json:"value_int"`
+	ValueFloat  *float32 `json:"value_float"`
+	ValueDouble *float64 `json:"value_double"`
+}
+
+// value is the restrict value as a float64, false when no value is set
+func (r localNumericRestrict) value() (float64, bool) {
+	switch {
+	case r.ValueInt != nil:
+		return float64(*r.ValueInt), true
+	case r.ValueFloat != nil:
+		return float64(*r.ValueFloat), true
+	case r.ValueDouble != nil:
+		return *r.ValueDouble, true
+	}
+	return 0, false
+}
+
+// LocalSearcher is an in-process brute-force backend for offline development. Embeddings are L2 normalized on load
+// and scored by dot product, matching the UNIT_L2_NORM + DOT_PRODUCT_DISTANCE configuration of the Vertex index.
+type LocalSearcher struct {
+	datapoints []LocalDatapoint
+}
+
+func NewLocalSearcher(datapoints []LocalDatapoint) *LocalSearcher {
+	for i := range datapoints {
+		normalize(datapoints[i].Embedding)
+	}
+	return &LocalSearcher{datapoints: datapoints}
+}
+
+// NewLocalSearcherFromConfig loads the datapoints selected by LoadDatapoints into memory
+func NewLocalSearcherFromConfig(ctx context.Context, config *Config) (*LocalSearcher, error) {
+	datapoints, err := LoadDatapoints(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Local vector backend loaded %d datapoints", len(datapoints))
+	return NewLocalSearcher(datapoints), nil
+}
+
+// LoadDatapoints reads embeddings from config.LocalEmbeddingsPath (JSONL), or from the SQLite
+// review_embeddings table when no path is configured
+func LoadDatapoints(ctx context.Context, config *Config) ([]LocalDatapoint, error) {
+	if config.LocalEmbeddingsPath != "" {
+		return LoadDatapointsJSONL(config.LocalEmbeddingsPath)
+	}
+	return LoadDatapointsSQLite(ctx)
+}
+
+// LoadDatapointsJSONL reads one Vertex-format datapoint per line
+func LoadDatapointsJSONL(path string) ([]LocalDatapoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embeddings file: %w", err)
+	}
+	defer f.Close()
+
+	var datapoints []LocalDatapoint
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var raw localJSONDatapoint
+		if err := json.Unmarshal([]byte(text), &raw); err != nil {
+			return nil, fmt.Errorf("invalid datapoint on line %d: %w", line, err)
+		}
+
+		dp := LocalDatapoint{
+			ID:               strings.Trim(string(raw.ID), `"`),
+			Embedding:        raw.Embedding,
+			Restricts:        make(map[string][]string, len(raw.Restricts)),
+			NumericRestricts: make(map[string]float64, len(raw.NumericRestricts)),
+		}
+		for _, r := range raw.Restricts {
+			dp.Restricts[r.Namespace] = append(dp.Restricts[r.Namespace], r.AllowList...)
+		}
+		for _, r := range raw.NumericRestricts {
+			if value, ok := r.value(); ok {
+				dp.NumericRestricts[r.Namespace] = value
+			}
+		}
+		datapoints = append(datapoints, dp)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read embeddings file: %w", err)
+	}
+	return datapoints, nil
+}
+
+// LoadDatapointsSQLite reads the review_embeddings table of the local database selected by orm.NewDatabase
+func LoadDatapointsSQLite(ctx context.Context) ([]LocalDatapoint, error) {
+	db, err := orm.NewDatabase()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var datapoints []LocalDatapoint
+	err = db.ForEachReviewEmbeddingBatch(ctx, 1000, func(batch []*models.ReviewEmbedding) error {
+		for _, e := range batch {
+			datapoints = append(datapoints, datapointFromReviewEmbedding(e))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load review embeddings: %w", err)
+	}
+	return datapoints, nil
+}
+
+func datapointFromReviewEmbedding(e *models.ReviewEmbedding) LocalDatapoint {
+	return LocalDatapoint{
+		ID:        strconv.FormatInt(e.ID, 10),
+		Embedding: e.Embedding,
+		Restricts: map[string][]string{
+			"hotel_name": {e.HotelName},
+			"city":       {e.City},
+			"country":    {e.Country},
+			"continent":  {e.Continent},
+		},
+		NumericRestricts: map[string]float64{
+			"rating": float64(e.Rating),
+		},
+	}
+}
+
+func (l *LocalSearcher) Name() string { return "local" }
+
+func (l *LocalSearcher) Close() error { return nil }
+
+func (l *LocalSearcher) FindNeighbors(ctx context.Context, queryEmbedding []float32, restricts []Restrict, numericRestricts []NumericRestrict, limit int) ([]VectorResult, error) {
+	query := slices.Clone(queryEmbedding)
+	normalize(query)
+
+	var results []VectorResult
+	for i, dp := range l.datapoints {
+		if i%10000 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if len(dp.Embedding) != len(query) {
+			continue
+		}
+		if !dp.matches(restricts, numericRestricts) {
+			continue
+		}
+		results = append(results, VectorResult{
+			ID:       dp.ID,
+			Distance: dot(query, dp.Embedding),
+		})
+	}
+
+	slices.SortFunc(results, func(a, b VectorResult) int {
+		switch {
+		case a.Distance > b.Distance:
+			return -1
+		case a.Distance < b.Distance:
+			return 1
+		default:
+			return strings.Compare(a.ID, b.ID)
+		}
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (dp LocalDatapoint) matches(restricts []Restrict, numericRestricts []NumericRestrict) bool {
+	for _, r := range restricts {
+		tokens, ok := dp.Restricts[r.Namespace]
+		if !ok {
+			return false
+		}
+		allowed := false
+		for _, t := range tokens {
+			if slices.Contains(r.AllowList, t) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	for _, r := range numericRestricts {
+		value, ok := dp.NumericRestricts[r.Namespace]
+		if !ok || !r.Matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalize(v []float32) {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
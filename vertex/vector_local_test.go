@@ -0,0 +1,156 @@
+package vertex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadDatapointsJSONLNumericRestricts(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want map[string]float64
+	}{
+		{
+			name: "value_int",
+			line: `{"id":"1","embedding":[1,0],"numeric_restricts":[{"namespace":"rating","value_int":4}]}`,
+			want: map[string]float64{"rating": 4},
+		},
+		{
+			name: "value_float",
+			line: `{"id":"1","embedding":[1,0],"numeric_restricts":[{"namespace":"rating","value_float":4.5}]}`,
+			want: map[string]float64{"rating": 4.5},
+		},
+		{
+			name: "value_double",
+			line: `{"id":"1","embedding":[1,0],"numeric_restricts":[{"namespace":"rating","value_double":3.25}]}`,
+			want: map[string]float64{"rating": 3.25},
+		},
+		{
+			name: "no value",
+			line: `{"id":"1","embedding":[1,0],"numeric_restricts":[{"namespace":"rating"}]}`,
+			want: map[string]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "embeddings.json")
+			if err := os.WriteFile(path, []byte(tt.line+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			datapoints, err := LoadDatapointsJSONL(path)
+			if err != nil {
+				t.Fatalf("LoadDatapointsJSONL: %v", err)
+			}
+			if len(datapoints) != 1 {
+				t.Fatalf("got %d datapoints, want 1", len(datapoints))
+			}
+			got := datapoints[0].NumericRestricts
+			if len(got) != len(tt.want) {
+				t.Fatalf("NumericRestricts = %v, want %v", got, tt.want)
+			}
+			for namespace, value := range tt.want {
+				if got[namespace] != value {
+					t.Errorf("NumericRestricts[%q] = %v, want %v", namespace, got[namespace], value)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalSearcherFindNeighborsFilters(t *testing.T) {
+	searcher := NewLocalSearcher([]LocalDatapoint{
+		{
+			ID:               "1",
+			Embedding:        []float32{1, 0},
+			Restricts:        map[string][]string{"city": {"Paris"}, "country": {"France"}},
+			NumericRestricts: map[string]float64{"rating": 4.5},
+		},
+		{
+			ID:               "2",
+			Embedding:        []float32{0.8, 0.6},
+			Restricts:        map[string][]string{"city": {"Lyon"}, "country": {"France"}},
+			NumericRestricts: map[string]float64{"rating": 3},
+		},
+		{
+			ID:               "3",
+			Embedding:        []float32{0, 1},
+			Restricts:        map[string][]string{"city": {"Rome"}, "country": {"Italy"}},
+			NumericRestricts: map[string]float64{"rating": 5},
+		},
+	})
+
+	tests := []struct {
+		name             string
+		restricts        []Restrict
+		numericRestricts []NumericRestrict
+		limit            int
+		want             []string
+	}{
+		{
+			name: "no filters ranks by similarity",
+			want: []string{"1", "2", "3"},
+		},
+		{
+			name:  "limit",
+			limit: 2,
+			want:  []string{"1", "2"},
+		},
+		{
+			name:      "allow list is ORed",
+			restricts: []Restrict{{Namespace: "city", AllowList: []string{"Lyon", "Rome"}}},
+			want:      []string{"2", "3"},
+		},
+		{
+			name: "namespaces are ANDed",
+			restricts: []Restrict{
+				{Namespace: "country", AllowList: []string{"France"}},
+				{Namespace: "city", AllowList: []string{"Rome"}},
+			},
+			want: nil,
+		},
+		{
+			name:      "unknown namespace matches nothing",
+			restricts: []Restrict{{Namespace: "continent", AllowList: []string{"Europe"}}},
+			want:      nil,
+		},
+		{
+			name:             "float rating against integer bound",
+			numericRestricts: []NumericRestrict{{Namespace: "rating", ValueInt: 4, Op: NumericOpGreaterEqual}},
+			want:             []string{"1", "3"},
+		},
+		{
+			name:             "less",
+			numericRestricts: []NumericRestrict{{Namespace: "rating", ValueInt: 4, Op: NumericOpLess}},
+			want:             []string{"2"},
+		},
+		{
+			name:             "equal is the default op",
+			numericRestricts: []NumericRestrict{{Namespace: "rating", ValueInt: 5}},
+			want:             []string{"3"},
+		},
+		{
+			name:             "not equal",
+			numericRestricts: []NumericRestrict{{Namespace: "rating", ValueInt: 5, Op: NumericOpNotEqual}},
+			want:             []string{"1", "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := searcher.FindNeighbors(context.Background(), []float32{1, 0}, tt.restricts, tt.numericRestricts, tt.limit)
+			if err != nil {
+				t.Fatalf("FindNeighbors: %v", err)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FindNeighbors IDs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
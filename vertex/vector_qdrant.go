@@ -0,0 +1,219 @@
+package vertex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// QdrantSearcher queries a Qdrant collection over its REST API. Restrict namespaces are stored as payload keys,
+// so city/country/continent/rating filters map directly onto Qdrant payload filters.
+type QdrantSearcher struct {
+	baseURL    string
+	apiKey     string
+	collection string
+	client     *http.Client
+}
+
+// QdrantError is a non-2xx response of the Qdrant REST API
+type QdrantError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *QdrantError) Error() string {
+	return fmt.Sprintf("qdrant request failed: %s: %s", e.Status, e.Body)
+}
+
+func NewQdrantSearcher(config *Config) *QdrantSearcher {
+	return &QdrantSearcher{
+		baseURL:    firstNonEmpty(config.QdrantURL, "http://localhost:6333"),
+		apiKey:     config.QdrantAPIKey,
+		collection: firstNonEmpty(config.QdrantCollection, "review_embeddings"),
+		client:     &http.Client{},
+	}
+}
+
+func (q *QdrantSearcher) Name() string { return "qdrant" }
+
+func (q *QdrantSearcher) Close() error { return nil }
+
+func (q *QdrantSearcher) FindNeighbors(ctx context.Context, queryEmbedding []float32, restricts []Restrict, numericRestricts []NumericRestrict, limit int) ([]VectorResult, error) {
+	body := map[string]any{
+		"vector":       queryEmbedding,
+		"limit":        limit,
+		"with_payload": false,
+	}
+	if filter := qdrantFilter(restricts, numericRestricts); filter != nil {
+		body["filter"] = filter
+	}
+
+	var parsed struct {
+		Result []struct {
+			ID    json.RawMessage `json:"id"`
+			Score float64         `json:"score"`
+		} `json:"result"`
+	}
+	if err := q.do(ctx, http.MethodPost, "/collections/"+q.collection+"/points/search", body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to find neighbors: %w", err)
+	}
+
+	results := make([]VectorResult, 0, len(parsed.Result))
+	for _, point := range parsed.Result {
+		results = append(results, VectorResult{
+			ID:       strings.Trim(string(point.ID), `"`),
+			Distance: point.Score,
+		})
+	}
+	return results, nil
+}
+
+// EnsureCollection creates the collection with cosine distance if it does not exist yet
+func (q *QdrantSearcher) EnsureCollection(ctx context.Context, dimensions int) error {
+	err := q.do(ctx, http.MethodGet, "/collections/"+q.collection, nil, nil)
+	if err == nil {
+		return nil
+	}
+	var qdrantErr *QdrantError
+	if !errors.As(err, &qdrantErr) || qdrantErr.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	body := map[string]any{
+		"vectors": map[string]any{
+			"size":     dimensions,
+			"distance": "Cosine",
+		},
+	}
+	if err := q.do(ctx, http.MethodPut, "/collections/"+q.collection, body, nil); err != nil {
+		return fmt.Errorf("failed to create collection %s: %w", q.collection, err)
+	}
+
+	// Keyword and float indexes keep filtered searches fast
+	for field, schema := range map[string]string{
+		"city": "keyword", "country": "keyword", "continent": "keyword", "hotel_name": "keyword", "rating": "float",
+	} {
+		index := map[string]any{"field_name": field, "field_schema": schema}
+		if err := q.do(ctx, http.MethodPut, "/collections/"+q.collection+"/index?wait=true", index, nil); err != nil {
+			return fmt.Errorf("failed to create payload index %s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// Upsert writes datapoints as Qdrant points, with their restricts flattened into the payload
+func (q *QdrantSearcher) Upsert(ctx context.Context, datapoints []LocalDatapoint) error {
+	points := make([]map[string]any, 0, len(datapoints))
+	for _, dp := range datapoints {
+		id, err := strconv.ParseUint(dp.ID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("qdrant point IDs must be unsigned integers, got %q", dp.ID)
+		}
+		payload := make(map[string]any, len(dp.Restricts)+len(dp.NumericRestricts))
+		for namespace, tokens := range dp.Restricts {
+			if len(tokens) == 1 {
+				payload[namespace] = tokens[0]
+			} else {
+				payload[namespace] = tokens
+			}
+		}
+		for namespace, value := range dp.NumericRestricts {
+			payload[namespace] = value
+		}
+		points = append(points, map[string]any{
+			"id":      id,
+			"vector":  dp.Embedding,
+			"payload": payload,
+		})
+	}
+
+	body := map[string]any{"points": points}
+	if err := q.do(ctx, http.MethodPut, "/collections/"+q.collection+"/points?wait=true", body, nil); err != nil {
+		return fmt.Errorf("failed to upsert %d points: %w", len(points), err)
+	}
+	return nil
+}
+
+func qdrantFilter(restricts []Restrict, numericRestricts []NumericRestrict) map[string]any {
+	var must, mustNot []map[string]any
+	for _, r := range restricts {
+		must = append(must, map[string]any{
+			"key":   r.Namespace,
+			"match": map[string]any{"any": r.AllowList},
+		})
+	}
+	for _, r := range numericRestricts {
+		// Numeric payloads are floats, which Qdrant only matches by range, so equality is a closed range
+		exact := map[string]any{"key": r.Namespace, "range": map[string]any{"gte": r.ValueInt, "lte": r.ValueInt}}
+		switch r.Op {
+		case NumericOpEqual, "":
+			must = append(must, exact)
+		case NumericOpNotEqual:
+			mustNot = append(mustNot, exact)
+		default:
+			ops := map[NumericOp]string{
+				NumericOpLess:         "lt",
+				NumericOpLessEqual:    "lte",
+				NumericOpGreaterEqual: "gte",
+				NumericOpGreater:      "gt",
+			}
+			must = append(must, map[string]any{"key": r.Namespace, "range": map[string]any{ops[r.Op]: r.ValueInt}})
+		}
+	}
+	if len(must) == 0 && len(mustNot) == 0 {
+		return nil
+	}
+	filter := map[string]any{}
+	if len(must) > 0 {
+		filter["must"] = must
+	}
+	if len(mustNot) > 0 {
+		filter["must_not"] = mustNot
+	}
+	return filter
+}
+
+func (q *QdrantSearcher) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(bodyBytes)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(q.baseURL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if q.apiKey != "" {
+		req.Header.Set("api-key", q.apiKey)
+	}
+
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &QdrantError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(respBytes)}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBytes, out)
+}
@@ -0,0 +1,124 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestQdrantFilter(t *testing.T) {
+	tests := []struct {
+		name             string
+		restricts        []Restrict
+		numericRestricts []NumericRestrict
+		want             string
+	}{
+		{
+			name: "no filters",
+			want: `null`,
+		},
+		{
+			name:      "allow list",
+			restricts: []Restrict{{Namespace: "city", AllowList: []string{"Paris", "Lyon"}}},
+			want:      `{"must":[{"key":"city","match":{"any":["Paris","Lyon"]}}]}`,
+		},
+		{
+			name:             "equal is a closed range",
+			numericRestricts: []NumericRestrict{{Namespace: "rating", ValueInt: 4, Op: NumericOpEqual}},
+			want:             `{"must":[{"key":"rating","range":{"gte":4,"lte":4}}]}`,
+		},
+		{
+			name:             "default op is equal",
+			numericRestricts: []NumericRestrict{{Namespace: "rating", ValueInt: 4}},
+			want:             `{"must":[{"key":"rating","range":{"gte":4,"lte":4}}]}`,
+		},
+		{
+			name:             "not equal",
+			numericRestricts: []NumericRestrict{{Namespace: "rating", ValueInt: 4, Op: NumericOpNotEqual}},
+			want:             `{"must_not":[{"key":"rating","range":{"gte":4,"lte":4}}]}`,
+		},
+		{
+			name:             "greater equal",
+			numericRestricts: []NumericRestrict{{Namespace: "rating", ValueInt: 4, Op: NumericOpGreaterEqual}},
+			want:             `{"must":[{"key":"rating","range":{"gte":4}}]}`,
+		},
+		{
+			name:             "less",
+			numericRestricts: []NumericRestrict{{Namespace: "rating", ValueInt: 2, Op: NumericOpLess}},
+			want:             `{"must":[{"key":"rating","range":{"lt":2}}]}`,
+		},
+		{
+			name:             "restricts and numeric restricts combined",
+			restricts:        []Restrict{{Namespace: "country", AllowList: []string{"France"}}},
+			numericRestricts: []NumericRestrict{{Namespace: "rating", ValueInt: 3, Op: NumericOpGreater}},
+			want:             `{"must":[{"key":"country","match":{"any":["France"]}},{"key":"rating","range":{"gt":3}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(qdrantFilter(tt.restricts, tt.numericRestricts))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("qdrantFilter = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQdrantEnsureCollection(t *testing.T) {
+	tests := []struct {
+		name        string
+		getStatus   int
+		getBody     string
+		wantCreated bool
+		wantErr     bool
+	}{
+		{name: "exists", getStatus: http.StatusOK, getBody: `{"result":{}}`},
+		{name: "missing is created", getStatus: http.StatusNotFound, getBody: `{"status":{"error":"Not found"}}`, wantCreated: true},
+		{name: "404 in the body of another error", getStatus: http.StatusInternalServerError, getBody: `{"status":{"error":"shard 404 failed"}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var puts []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.WriteHeader(tt.getStatus)
+					w.Write([]byte(tt.getBody))
+					return
+				}
+				mu.Lock()
+				puts = append(puts, r.URL.Path)
+				mu.Unlock()
+				w.Write([]byte(`{"result":true}`))
+			}))
+			defer srv.Close()
+
+			q := NewQdrantSearcher(&Config{QdrantURL: srv.URL, QdrantCollection: "reviews"})
+			err := q.EnsureCollection(context.Background(), 768)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EnsureCollection error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var qdrantErr *QdrantError
+				if !errors.As(err, &qdrantErr) || qdrantErr.StatusCode != tt.getStatus {
+					t.Errorf("error = %v, want a QdrantError with status %d", err, tt.getStatus)
+				}
+			}
+			created := len(puts) > 0 && puts[0] == "/collections/reviews"
+			if created != tt.wantCreated {
+				t.Errorf("created = %v (requests %v), want %v", created, puts, tt.wantCreated)
+			}
+			if created && !strings.HasSuffix(puts[len(puts)-1], "/index") {
+				t.Errorf("payload indexes not created, requests %v", puts)
+			}
+		})
+	}
+}
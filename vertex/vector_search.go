@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	aiplatformpb "cloud.google.com/go/aiplatform/apiv1/aiplatformpb"
 	"google.golang.org/genai"
 )
 
@@ -14,6 +13,12 @@ type SearchForm struct {
 	CityCountry string `form:"citycountry"`
 	Rating      string `form:"rating"`
 	LLMChoice   string `form:"llm"`
+	Mode        string `form:"mode"`
+	Near        string `form:"near"`
+	RadiusKm    string `form:"radius_km"`
+	SessionID   string `form:"session_id"`
+	// PromptVersion pins the completion prompt, e.g. "v2" or "completion/v2", instead of the weighted assignment
+	PromptVersion string `form:"prompt_version"`
 }
 
 type SearchInput struct {
@@ -25,6 +30,22 @@ type SearchInput struct {
 	FilterRating      bool
 	FilterCityCountry bool
 	PreferredModel    string
+	Mode              string
+	TravelType        string
+	Aspects           []string
+	Geo               *GeoFilter
+	// HotelNames restricts retrieval to these hotels, set by ApplyGeoFilter
+	HotelNames []string
+	// History holds the earlier turns of a conversational session, oldest first
+	History []Turn
+	// PromptVersion and SafetyPromptVersion are the prompt template versions, set by AssignPrompts
+	PromptVersion       string
+	SafetyPromptVersion string
+}
+
+// GeoExcludesAll reports a geo filter with no hotel in range, in which case retrieval returns nothing
+func (in SearchInput) GeoExcludesAll() bool {
+	return in.Geo != nil && len(in.HotelNames) == 0
 }
 
 type VectorResult struct {
@@ -32,13 +53,68 @@ type VectorResult struct {
 	Distance float64 `json:"distance"`
 }
 
+// Restrict is a token allow list on a string namespace such as city, country or continent
+type Restrict struct {
+	Namespace string   `json:"namespace"`
+	AllowList []string `json:"allow"`
+}
+
+type NumericOp string
+
+const (
+	NumericOpLess         NumericOp = "LESS"
+	NumericOpLessEqual    NumericOp = "LESS_EQUAL"
+	NumericOpEqual        NumericOp = "EQUAL"
+	NumericOpGreaterEqual NumericOp = "GREATER_EQUAL"
+	NumericOpGreater      NumericOp = "GREATER"
+	NumericOpNotEqual     NumericOp = "NOT_EQUAL"
+)
+
+// NumericRestrict compares an integer namespace such as rating against ValueInt
+type NumericRestrict struct {
+	Namespace string    `json:"namespace"`
+	ValueInt  int64     `json:"value_int"`
+	Op        NumericOp `json:"op,omitempty"`
+}
+
+// Matches reports whether value satisfies the restriction. Values are compared as float64 since the index
+// stores rating as a FLOAT.
+func (r NumericRestrict) Matches(value float64) bool {
+	bound := float64(r.ValueInt)
+	switch r.Op {
+	case NumericOpLess:
+		return value < bound
+	case NumericOpLessEqual:
+		return value <= bound
+	case NumericOpGreaterEqual:
+		return value >= bound
+	case NumericOpGreater:
+		return value > bound
+	case NumericOpNotEqual:
+		return value != bound
+	default:
+		return value == bound
+	}
+}
+
+// VectorSearcher finds the nearest review embeddings for a query vector. Restricts on different namespaces are
+// ANDed, values within one AllowList are ORed, mirroring Vertex AI Vector Search filtering.
+type VectorSearcher interface {
+	Name() string
+	FindNeighbors(ctx context.Context, queryEmbedding []float32, restricts []Restrict, numericRestricts []NumericRestrict, limit int) ([]VectorResult, error)
+	Close() error
+}
+
 func float32Ptr(v float32) *float32 {
 	return &v
 }
 
+// EmbeddingModel embeds questions, it must match the model the review embeddings were generated with
+const EmbeddingModel = "gemini-embedding-001"
+
 func (s *VertexSearchService) GenerateEmbedding(ctx context.Context, question string) ([]float32, error) {
 	content := genai.NewContentFromText(question, "")
-	result, err := s.genaiClient.Models.EmbedContent(ctx, "gemini-embedding-001", []*genai.Content{content}, nil)
+	result, err := s.genaiClient.Models.EmbedContent(ctx, EmbeddingModel, []*genai.Content{content}, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate embedding: %w", err)
 	}
@@ -54,73 +130,68 @@ func (s *VertexSearchService) GenerateEmbedding(ctx context.Context, question st
 	return embedding, nil
 }
 
-func (s *VertexSearchService) VertexSearchEndpoint(ctx context.Context, config Config, queryEmbedding []float32, params SearchInput) ([]VectorResult, error) {
-	endpointPath := fmt.Sprintf("projects/%s/locations/%s/indexEndpoints/%s", s.projectID, s.location, config.EndpointID)
+// VectorSearch runs the configured VectorSearcher with the restricts derived from the search input
+func (s *VertexSearchService) VectorSearch(ctx context.Context, config Config, queryEmbedding []float32, params SearchInput) ([]VectorResult, error) {
+	if params.GeoExcludesAll() {
+		return nil, nil
+	}
+	restricts, numericRestricts := BuildRestricts(params)
+	return s.searcher.FindNeighbors(ctx, queryEmbedding, restricts, numericRestricts, config.Limit)
+}
 
-	city := params.City
-	country := params.Country
-	continent := params.Continent
-	rating := int64(params.Rating)
+// VectorSearchWithMetadata also returns review metadata when the backend is a MetadataSearcher, otherwise metadata is nil
+func (s *VertexSearchService) VectorSearchWithMetadata(ctx context.Context, config Config, queryEmbedding []float32, params SearchInput) ([]VectorResult, []map[string]any, error) {
+	ms, ok := s.searcher.(MetadataSearcher)
+	if !ok || params.GeoExcludesAll() {
+		results, err := s.VectorSearch(ctx, config, queryEmbedding, params)
+		return results, nil, err
+	}
+	restricts, numericRestricts := BuildRestricts(params)
+	return ms.FindNeighborsWithMetadata(ctx, queryEmbedding, restricts, numericRestricts, config.Limit)
+}
 
-	var restrictsParams []*aiplatformpb.IndexDatapoint_Restriction
-	var numericRestrictsParams []*aiplatformpb.IndexDatapoint_NumericRestriction
+// BuildRestricts maps the search filters to the city/country/continent/rating namespaces of the index
+func BuildRestricts(params SearchInput) ([]Restrict, []NumericRestrict) {
+	var restricts []Restrict
+	var numericRestricts []NumericRestrict
 
 	if params.FilterRating {
-		numericRestrictsParams = append(numericRestrictsParams, &aiplatformpb.IndexDatapoint_NumericRestriction{
+		numericRestricts = append(numericRestricts, NumericRestrict{
 			Namespace: "rating",
-			Value:     &aiplatformpb.IndexDatapoint_NumericRestriction_ValueInt{ValueInt: rating},
-			Op:        aiplatformpb.IndexDatapoint_NumericRestriction_GREATER_EQUAL,
+			ValueInt:  int64(params.Rating),
+			Op:        NumericOpGreaterEqual,
 		})
 	}
 
-	if continent != "" {
-		restrictsParams = append(restrictsParams, &aiplatformpb.IndexDatapoint_Restriction{
+	if params.Continent != "" {
+		restricts = append(restricts, Restrict{
 			Namespace: "continent",
-			AllowList: []string{continent},
+			AllowList: []string{params.Continent},
 		})
 	}
 
 	if params.FilterCityCountry {
-		restrictsParams = append(restrictsParams, &aiplatformpb.IndexDatapoint_Restriction{
+		restricts = append(restricts, Restrict{
 			Namespace: "city",
-			AllowList: []string{city},
+			AllowList: []string{params.City},
 		})
-		restrictsParams = append(restrictsParams, &aiplatformpb.IndexDatapoint_Restriction{
+		restricts = append(restricts, Restrict{
 			Namespace: "country",
-			AllowList: []string{country},
+			AllowList: []string{params.Country},
+		})
+	} else if params.Country != "" {
+		restricts = append(restricts, Restrict{
+			Namespace: "country",
+			AllowList: []string{params.Country},
 		})
 	}
 
-	req := &aiplatformpb.FindNeighborsRequest{
-		IndexEndpoint:   endpointPath,
-		DeployedIndexId: config.DeployedIndexID,
-		Queries: []*aiplatformpb.FindNeighborsRequest_Query{
-			{
-				Datapoint: &aiplatformpb.IndexDatapoint{
-					FeatureVector:    queryEmbedding,
-					Restricts:        restrictsParams,
-					NumericRestricts: numericRestrictsParams,
-				},
-				NeighborCount: int32(config.Limit),
-			},
-		},
-		ReturnFullDatapoint: false,
-	}
-
-	resp, err := s.matchClient.FindNeighbors(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find neighbors: %w", err)
-	}
-
-	var results []VectorResult
-	for _, nearestNeighbors := range resp.GetNearestNeighbors() {
-		for _, neighbor := range nearestNeighbors.GetNeighbors() {
-			results = append(results, VectorResult{
-				ID:       neighbor.GetDatapoint().GetDatapointId(),
-				Distance: neighbor.GetDistance(),
-			})
-		}
+	if len(params.HotelNames) > 0 {
+		restricts = append(restricts, Restrict{
+			Namespace: "hotel_name",
+			AllowList: params.HotelNames,
+		})
 	}
 
-	return results, nil
+	return restricts, numericRestricts
 }
@@ -0,0 +1,109 @@
+package vertex
+
+import (
+	"context"
+	"fmt"
+
+	aiplatform "cloud.google.com/go/aiplatform/apiv1"
+	aiplatformpb "cloud.google.com/go/aiplatform/apiv1/aiplatformpb"
+	"google.golang.org/api/option"
+)
+
+// VertexMatchSearcher queries a deployed Vertex AI Vector Search index endpoint
+type VertexMatchSearcher struct {
+	matchClient     *aiplatform.MatchClient
+	endpointPath    string
+	deployedIndexID string
+}
+
+func NewVertexMatchSearcher(ctx context.Context, config *Config) (*VertexMatchSearcher, error) {
+	clientOptions := []option.ClientOption{
+		option.WithEndpoint(fmt.Sprintf("%s:443", config.EndpointPublicDomainName)),
+	}
+	matchClient, err := aiplatform.NewMatchClient(ctx, clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MatchClient: %w", err)
+	}
+
+	return &VertexMatchSearcher{
+		matchClient:     matchClient,
+		endpointPath:    fmt.Sprintf("projects/%s/locations/%s/indexEndpoints/%s", config.ProjectID, config.Location, config.EndpointID),
+		deployedIndexID: config.DeployedIndexID,
+	}, nil
+}
+
+func (v *VertexMatchSearcher) Name() string { return "vertex" }
+
+func (v *VertexMatchSearcher) Close() error {
+	return v.matchClient.Close()
+}
+
+func (v *VertexMatchSearcher) FindNeighbors(ctx context.Context, queryEmbedding []float32, restricts []Restrict, numericRestricts []NumericRestrict, limit int) ([]VectorResult, error) {
+	var restrictsParams []*aiplatformpb.IndexDatapoint_Restriction
+	var numericRestrictsParams []*aiplatformpb.IndexDatapoint_NumericRestriction
+
+	for _, r := range restricts {
+		restrictsParams = append(restrictsParams, &aiplatformpb.IndexDatapoint_Restriction{
+			Namespace: r.Namespace,
+			AllowList: r.AllowList,
+		})
+	}
+
+	for _, r := range numericRestricts {
+		numericRestrictsParams = append(numericRestrictsParams, &aiplatformpb.IndexDatapoint_NumericRestriction{
+			Namespace: r.Namespace,
+			Value:     &aiplatformpb.IndexDatapoint_NumericRestriction_ValueInt{ValueInt: r.ValueInt},
+			Op:        vertexNumericOp(r.Op),
+		})
+	}
+
+	req := &aiplatformpb.FindNeighborsRequest{
+		IndexEndpoint:   v.endpointPath,
+		DeployedIndexId: v.deployedIndexID,
+		Queries: []*aiplatformpb.FindNeighborsRequest_Query{
+			{
+				Datapoint: &aiplatformpb.IndexDatapoint{
+					FeatureVector:    queryEmbedding,
+					Restricts:        restrictsParams,
+					NumericRestricts: numericRestrictsParams,
+				},
+				NeighborCount: int32(limit),
+			},
+		},
+		ReturnFullDatapoint: false,
+	}
+
+	resp, err := v.matchClient.FindNeighbors(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find neighbors: %w", err)
+	}
+
+	var results []VectorResult
+	for _, nearestNeighbors := range resp.GetNearestNeighbors() {
+		for _, neighbor := range nearestNeighbors.GetNeighbors() {
+			results = append(results, VectorResult{
+				ID:       neighbor.GetDatapoint().GetDatapointId(),
+				Distance: neighbor.GetDistance(),
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func vertexNumericOp(op NumericOp) aiplatformpb.IndexDatapoint_NumericRestriction_Operator {
+	switch op {
+	case NumericOpLess:
+		return aiplatformpb.IndexDatapoint_NumericRestriction_LESS
+	case NumericOpLessEqual:
+		return aiplatformpb.IndexDatapoint_NumericRestriction_LESS_EQUAL
+	case NumericOpGreaterEqual:
+		return aiplatformpb.IndexDatapoint_NumericRestriction_GREATER_EQUAL
+	case NumericOpGreater:
+		return aiplatformpb.IndexDatapoint_NumericRestriction_GREATER
+	case NumericOpNotEqual:
+		return aiplatformpb.IndexDatapoint_NumericRestriction_NOT_EQUAL
+	default:
+		return aiplatformpb.IndexDatapoint_NumericRestriction_EQUAL
+	}
+}